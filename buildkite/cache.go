@@ -0,0 +1,264 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	genqlient "github.com/Khan/genqlient/graphql"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+// responseCache holds successful read responses for the lifetime of the provider process, which
+// Terraform starts afresh for every plan and apply. Only data sources are served from it; resources
+// always read through to the API so that drift is never hidden, but their writes still invalidate it.
+//
+// Entries are invalidated by node rather than by operation: each remembers the IDs and UUIDs its
+// response and variables mention, and a write drops every entry sharing one with the write's own
+// variables, payload or response. That is what lets a GraphQL mutation invalidate a REST read of the
+// same object and the other way round. A write that creates something cannot be matched this way,
+// since no cached response mentions the new node yet, so it clears the cache instead: any list
+// query might now be missing it.
+type responseCache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	hits    int
+	misses  int
+}
+
+type cacheEntry struct {
+	data  []byte
+	nodes map[string]struct{}
+	// path is the REST path the entry was read from, or empty for GraphQL entries.
+	path string
+}
+
+func newResponseCache() *responseCache {
+	return &responseCache{entries: map[string]cacheEntry{}}
+}
+
+func (c *responseCache) get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	tflog.Debug(ctx, "Data source response cache lookup", map[string]interface{}{
+		"key":    key,
+		"hit":    ok,
+		"hits":   c.hits,
+		"misses": c.misses,
+	})
+
+	return entry.data, ok
+}
+
+func (c *responseCache) put(key, path string, data []byte, nodes map[string]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = cacheEntry{data: data, nodes: nodes, path: path}
+}
+
+// invalidate drops every entry that mentions one of the given nodes or, when path is set, was read
+// from the same REST collection.
+func (c *responseCache) invalidate(ctx context.Context, reason string, path string, nodes map[string]struct{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	collection := path
+	if i := strings.LastIndex(path, "/"); i > 0 {
+		collection = path[:i]
+	}
+
+	dropped := 0
+	for key, entry := range c.entries {
+		if path != "" && entry.path != "" && strings.HasPrefix(entry.path, collection) {
+			delete(c.entries, key)
+			dropped++
+			continue
+		}
+		for node := range nodes {
+			if _, ok := entry.nodes[node]; ok {
+				delete(c.entries, key)
+				dropped++
+				break
+			}
+		}
+	}
+	if dropped > 0 {
+		tflog.Debug(ctx, "Invalidated data source response cache entries", map[string]interface{}{
+			"reason":  reason,
+			"dropped": dropped,
+		})
+	}
+}
+
+func (c *responseCache) clear(ctx context.Context, reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) > 0 {
+		tflog.Debug(ctx, "Cleared data source response cache", map[string]interface{}{
+			"reason":  reason,
+			"dropped": len(c.entries),
+		})
+	}
+	c.entries = map[string]cacheEntry{}
+}
+
+// cacheNodes collects the identifiers a value mentions. Every string in a set of variables or a
+// request payload counts, since their names vary ("id", "clusterId", "organizationID"); in a
+// response only the identifying fields do, so that names and descriptions do not tie unrelated
+// entries together.
+func cacheNodes(nodes map[string]struct{}, value interface{}, everyString bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if s, ok := child.(string); ok && !everyString {
+				if key == "id" || key == "uuid" || key == "graphql_id" {
+					nodes[s] = struct{}{}
+				}
+				continue
+			}
+			cacheNodes(nodes, child, everyString)
+		}
+	case []interface{}:
+		for _, child := range v {
+			cacheNodes(nodes, child, everyString)
+		}
+	case string:
+		if everyString && v != "" {
+			nodes[v] = struct{}{}
+		}
+	}
+}
+
+// nodesOf decodes JSON, or marshals and decodes any other value, before collecting its nodes.
+func nodesOf(nodes map[string]struct{}, value interface{}, everyString bool) {
+	data, ok := value.([]byte)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return
+		}
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err == nil {
+		cacheNodes(nodes, decoded, everyString)
+	}
+}
+
+// cachingGraphQLClient wraps the genqlient client. Mutations always pass through and invalidate;
+// queries are served from the cache only when serveReads is set, which it is for the client handed
+// to data sources.
+type cachingGraphQLClient struct {
+	next       genqlient.Client
+	cache      *responseCache
+	serveReads bool
+}
+
+func (c *cachingGraphQLClient) MakeRequest(ctx context.Context, req *genqlient.Request, resp *genqlient.Response) error {
+	if strings.HasPrefix(strings.TrimSpace(req.Query), "mutation") {
+		err := c.next.MakeRequest(ctx, req, resp)
+
+		// Invalidate even when the mutation failed: it may have been applied before the error.
+		if strings.Contains(strings.ToLower(req.OpName), "create") {
+			c.cache.clear(ctx, req.OpName)
+		} else {
+			nodes := map[string]struct{}{}
+			nodesOf(nodes, req.Variables, true)
+			nodesOf(nodes, resp.Data, false)
+			c.cache.invalidate(ctx, req.OpName, "", nodes)
+		}
+
+		return err
+	}
+
+	if !c.serveReads {
+		return c.next.MakeRequest(ctx, req, resp)
+	}
+
+	variables, err := json.Marshal(req.Variables)
+	if err != nil {
+		return c.next.MakeRequest(ctx, req, resp)
+	}
+	key := fmt.Sprintf("graphql %s %s", req.OpName, variables)
+
+	if data, ok := c.cache.get(ctx, key); ok {
+		return json.Unmarshal(data, resp.Data)
+	}
+
+	if err := c.next.MakeRequest(ctx, req, resp); err != nil {
+		return err
+	}
+	if data, err := json.Marshal(resp.Data); err == nil && len(resp.Errors) == 0 {
+		nodes := map[string]struct{}{}
+		nodesOf(nodes, req.Variables, true)
+		nodesOf(nodes, data, false)
+		c.cache.put(key, "", data, nodes)
+	}
+
+	return nil
+}
+
+// cachedRESTRead serves a data source's GET from the cache, reporting whether it did.
+func (client *Client) cachedRESTRead(ctx context.Context, method, path string, responseObject interface{}) (bool, error) {
+	if !client.cacheReads || method != http.MethodGet {
+		return false, nil
+	}
+
+	data, ok := client.cache.get(ctx, "rest "+path)
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, responseObject)
+}
+
+// storeRESTResponse records a data source's GET, or invalidates whatever a write may have changed.
+func (client *Client) storeRESTResponse(ctx context.Context, method, path string, postData interface{}, responseBody []byte) {
+	if client.cache == nil {
+		return
+	}
+
+	switch {
+	case method == http.MethodGet && client.cacheReads:
+		nodes := map[string]struct{}{}
+		nodesOf(nodes, responseBody, false)
+		client.cache.put("rest "+path, path, responseBody, nodes)
+
+	case method == http.MethodPost:
+		client.cache.clear(ctx, method+" "+path)
+
+	case method != http.MethodGet:
+		nodes := map[string]struct{}{}
+		nodesOf(nodes, postData, true)
+		if responseBody != nil {
+			nodesOf(nodes, responseBody, false)
+		}
+		client.cache.invalidate(ctx, method+" "+path, path, nodes)
+	}
+}
+
+// dataSourceClient returns the client data sources should use: one that serves reads from the
+// cache when caching is enabled, and the client itself otherwise.
+func (client *Client) dataSourceClient() *Client {
+	if client.cache == nil {
+		return client
+	}
+
+	dataSourceClient := *client
+	dataSourceClient.genqlient = &cachingGraphQLClient{next: client.genqlient.(*cachingGraphQLClient).next, cache: client.cache, serveReads: true}
+	dataSourceClient.cacheReads = true
+
+	return &dataSourceClient
+}
@@ -0,0 +1,213 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newCacheTestServer answers every GraphQL operation with the same organization and every REST GET
+// with a fixed portal, counting the requests that reach it.
+func newCacheTestServer(t *testing.T) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasPrefix(r.URL.Path, "/v2/") {
+			_, _ = w.Write([]byte(`{"uuid":"portal-uuid","slug":"viewer"}`))
+			return
+		}
+
+		var body struct {
+			OperationName string `json:"operationName"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch body.OperationName {
+		case "setApiIpAddresses":
+			_, _ = w.Write([]byte(`{"data":{"organizationApiIpAllowlistUpdate":{"organization":{"id":"org-abc","uuid":"org-uuid","allowedApiIpAddresses":"10.0.0.0/8"}}}}`))
+		case "createCluster":
+			_, _ = w.Write([]byte(`{"data":{"clusterCreate":{"cluster":{"id":"cluster-new"}}}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data":{"organization":{"id":"org-abc","uuid":"org-uuid","allowedApiIpAddresses":"","membersRequireTwoFactorAuthentication":false}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &requests
+}
+
+func newCacheTestClient(serverURL string, cacheReads bool) *Client {
+	return NewClient(&clientConfig{
+		apiToken:   "test",
+		graphqlURL: serverURL,
+		restURL:    serverURL,
+		org:        "test-org",
+		userAgent:  "test",
+		cacheReads: cacheReads,
+	})
+}
+
+func TestDataSourceClientServesRepeatedQueriesFromCache(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newCacheTestServer(t)
+	client := newCacheTestClient(server.URL, true)
+	dataSources := client.dataSourceClient()
+	ctx := context.Background()
+
+	for range 3 {
+		response, err := getOrganization(ctx, dataSources.genqlient, "test-org")
+		if err != nil {
+			t.Fatalf("getOrganization() error = %v", err)
+		}
+		if response.Organization.Id != "org-abc" {
+			t.Fatalf("cached response lost its data: %+v", response.Organization)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+
+	// A different variable is a different key.
+	if _, err := getOrganization(ctx, dataSources.genqlient, "other-org"); err != nil {
+		t.Fatalf("getOrganization() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+
+	// Resources always read through, so drift is never hidden from them.
+	if _, err := getOrganization(ctx, client.genqlient, "test-org"); err != nil {
+		t.Fatalf("getOrganization() error = %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3", got)
+	}
+}
+
+func TestMutationInvalidatesEntriesForTheSameNode(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newCacheTestServer(t)
+	client := newCacheTestClient(server.URL, true)
+	dataSources := client.dataSourceClient()
+	ctx := context.Background()
+
+	if _, err := getOrganization(ctx, dataSources.genqlient, "test-org"); err != nil {
+		t.Fatal(err)
+	}
+	// The resource client's mutation names the organization the cached query returned.
+	if _, err := setApiIpAddresses(ctx, client.genqlient, "org-abc", "10.0.0.0/8"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getOrganization(ctx, dataSources.genqlient, "test-org"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3: the mutation should have invalidated the cached query", got)
+	}
+}
+
+func TestCreateMutationClearsTheCache(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newCacheTestServer(t)
+	client := newCacheTestClient(server.URL, true)
+	dataSources := client.dataSourceClient()
+	ctx := context.Background()
+
+	if _, err := getOrganization(ctx, dataSources.genqlient, "test-org"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createCluster(ctx, client.genqlient, "unrelated-org", "cluster", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getOrganization(ctx, dataSources.genqlient, "test-org"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3: a create should clear the cache", got)
+	}
+}
+
+func TestRESTReadsAreCachedAndInvalidatedByWrites(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newCacheTestServer(t)
+	client := newCacheTestClient(server.URL, true)
+	dataSources := client.dataSourceClient()
+	ctx := context.Background()
+	path := "/v2/organizations/test-org/portals/viewer"
+
+	for range 2 {
+		var result portalAPIResponse
+		if err := dataSources.makeRequest(ctx, http.MethodGet, path, nil, &result); err != nil {
+			t.Fatal(err)
+		}
+		if result.UUID != "portal-uuid" {
+			t.Fatalf("cached response lost its data: %+v", result)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("server saw %d requests, want 1", got)
+	}
+
+	var result portalAPIResponse
+	if err := client.makeRequest(ctx, http.MethodPatch, path, map[string]string{"name": "renamed"}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if err := dataSources.makeRequest(ctx, http.MethodGet, path, nil, &result); err != nil {
+		t.Fatal(err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("server saw %d requests, want 3: the PATCH should have invalidated the cached GET", got)
+	}
+}
+
+func TestDataSourceClientWithoutCaching(t *testing.T) {
+	t.Parallel()
+
+	server, requests := newCacheTestServer(t)
+	client := newCacheTestClient(server.URL, false)
+
+	if client.dataSourceClient() != client {
+		t.Fatal("dataSourceClient() should return the client itself when caching is disabled")
+	}
+	for range 2 {
+		if _, err := getOrganization(context.Background(), client.genqlient, "test-org"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+}
+
+func TestDataSourceClientKeepsClientSettings(t *testing.T) {
+	t.Parallel()
+
+	client := newCacheTestClient("http://example.com", true)
+	dataSources := client.dataSourceClient()
+
+	if dataSources.genqlient == client.genqlient || !dataSources.cacheReads {
+		t.Fatal("the data source client should serve reads from the cache")
+	}
+	if dataSources.http != client.http || dataSources.portalHTTP != client.portalHTTP || dataSources.graphql != client.graphql {
+		t.Error("the data source client should share the client's HTTP clients")
+	}
+	if dataSources.organization != client.organization || dataSources.restURL != client.restURL || dataSources.portalURL != client.portalURL {
+		t.Error("the data source client should keep the client's organization and endpoints")
+	}
+	if dataSources.lookups != client.lookups || dataSources.cache != client.cache {
+		t.Error("the data source client should share the client's lookups and cache")
+	}
+}
@@ -22,14 +22,24 @@ import (
 
 // Client can be used to interact with the Buildkite API
 type Client struct {
-	graphql          *graphql.Client
-	genqlient        genqlient.Client
-	http             *http.Client
-	organization     string
-	organizationId   *string
-	organizationIdMu sync.Mutex
-	restURL          string
-	timeouts         timeouts.Value
+	graphql      *graphql.Client
+	genqlient    genqlient.Client
+	http         *http.Client
+	organization string
+	restURL      string
+	timeouts     timeouts.Value
+
+	// portalHTTP invokes portals, which authenticate with their own token rather than the API token.
+	portalHTTP *http.Client
+	portalURL  string
+
+	// lookups is shared with the data source client, so what one fetches the other reuses.
+	lookups *clientLookups
+
+	// cache is shared by the resource and data source clients when read caching is enabled, and nil
+	// otherwise. Only the data source client, which has cacheReads set, is served from it.
+	cache      *responseCache
+	cacheReads bool
 
 	// Retained so tests can assert the retry configuration and shorten the waits.
 	restRetry    *retryablehttp.Client
@@ -41,30 +51,49 @@ type clientConfig struct {
 	apiToken   string
 	graphqlURL string
 	restURL    string
+	portalURL  string
 	userAgent  string
 	timeouts   timeouts.Value
 	maxRetries int
+	// cacheReads enables the data source response cache described on responseCache.
+	cacheReads bool
+	// transport replaces the pooled transport retryablehttp creates for each client. It is shared
+	// by both, so REST and GraphQL calls see the same TLS and proxy settings.
+	transport http.RoundTripper
 }
 
+// wrapTransport, when set, wraps the transport beneath the auth headers of every client NewClient
+// builds. Acceptance tests set it to record and replay API traffic; the provider never does.
+var wrapTransport func(http.RoundTripper) http.RoundTripper
+
 type headerRoundTripper struct {
 	next   http.RoundTripper
 	Header http.Header
 }
 
+// clientLookups holds the values a client fetches once and then reuses.
+type clientLookups struct {
+	organizationId   *string
+	organizationIdMu sync.Mutex
+
+	hostedAgentCatalogue   *hostedAgentCatalogue
+	hostedAgentCatalogueMu sync.Mutex
+}
+
 func (client *Client) GetOrganizationID() (*string, error) {
-	client.organizationIdMu.Lock()
-	defer client.organizationIdMu.Unlock()
-	if client.organizationId != nil {
-		return client.organizationId, nil
+	client.lookups.organizationIdMu.Lock()
+	defer client.lookups.organizationIdMu.Unlock()
+	if client.lookups.organizationId != nil {
+		return client.lookups.organizationId, nil
 	}
 	orgId, err := GetOrganizationID(client.organization, client.graphql)
 	if err != nil {
 		return nil, err
 	}
 	// Cache only on success; a cached empty ID would be served on later retries.
-	client.organizationId = &orgId
+	client.lookups.organizationId = &orgId
 
-	return client.organizationId, nil
+	return client.lookups.organizationId, nil
 }
 
 // NewClient creates a client for interacting with the Buildkite API.
@@ -184,8 +213,8 @@ func NewClient(config *clientConfig) *Client {
 		}
 
 		// The count is carried rather than formatted in. makeRequest renders its own from the capture,
-		// alongside the request itself, and strips this one; the registry resources call client.http.Do
-		// directly and keep no capture, so for them this is the only place the count survives.
+		// alongside the request itself, and strips this one; a caller of client.http.Do that bypasses
+		// makeRequest keeps no capture, so for it this is the only place the count survives.
 		return nil, &attemptsError{attempts: numTries, err: unwrapURLError(err)}
 	}
 
@@ -202,10 +231,37 @@ func NewClient(config *clientConfig) *Client {
 	if !diags.HasError() && readTimeout > 0 {
 		restRetryClient.HTTPClient.Timeout = readTimeout
 	}
+	if config.transport != nil {
+		restRetryClient.HTTPClient.Transport = config.transport
+	}
+	if wrapTransport != nil {
+		restRetryClient.HTTPClient.Transport = wrapTransport(restRetryClient.HTTPClient.Transport)
+	}
 	// Add auth headers to the underlying transport of the REST retry client
 	restRetryClient.HTTPClient.Transport = newHeaderRoundTripper(restRetryClient.HTTPClient.Transport, commonHeaders)
 	restHttpClient := restRetryClient.StandardClient()
 
+	// Portal Client Setup. Same retry policy and transport as REST, but without the API token: a
+	// portal is invoked with its own token, which the caller sets on each request.
+	portalRetryClient := retryablehttp.NewClient()
+	portalRetryClient.RetryMax = config.maxRetries
+	portalRetryClient.RetryWaitMin = DefaultRetryWaitMinSeconds * time.Second
+	portalRetryClient.RetryWaitMax = DefaultRetryWaitMaxSeconds * time.Second
+	portalRetryClient.Logger = nil // Using tflog directly
+	portalRetryClient.Backoff = sharedBackoff
+	portalRetryClient.CheckRetry = sharedCheckRetry
+	portalRetryClient.ErrorHandler = restErrorHandler
+	if !diags.HasError() && readTimeout > 0 {
+		portalRetryClient.HTTPClient.Timeout = readTimeout
+	}
+	if config.transport != nil {
+		portalRetryClient.HTTPClient.Transport = config.transport
+	}
+	if wrapTransport != nil {
+		portalRetryClient.HTTPClient.Transport = wrapTransport(portalRetryClient.HTTPClient.Transport)
+	}
+	portalRetryClient.HTTPClient.Transport = newHeaderRoundTripper(portalRetryClient.HTTPClient.Transport, http.Header{"User-Agent": commonHeaders.Values("User-Agent")})
+
 	// GraphQL Client Setup. Note it gets no ErrorHandler: see restErrorHandler above before adding one.
 	graphqlRetryClient := retryablehttp.NewClient()
 	graphqlRetryClient.RetryMax = config.maxRetries // Same retry policy as REST
@@ -217,23 +273,37 @@ func NewClient(config *clientConfig) *Client {
 	if !diags.HasError() && readTimeout > 0 {
 		graphqlRetryClient.HTTPClient.Timeout = readTimeout
 	}
+	if config.transport != nil {
+		graphqlRetryClient.HTTPClient.Transport = config.transport
+	}
+	if wrapTransport != nil {
+		graphqlRetryClient.HTTPClient.Transport = wrapTransport(graphqlRetryClient.HTTPClient.Transport)
+	}
 	// Add auth headers to the underlying transport of the GraphQL retry client
 	graphqlRetryClient.HTTPClient.Transport = newHeaderRoundTripper(graphqlRetryClient.HTTPClient.Transport, commonHeaders)
 	graphqlHttpClient := graphqlRetryClient.StandardClient()
 
 	graphqlClient := graphql.NewClient(config.graphqlURL, graphqlHttpClient)
 
-	return &Client{
-		graphql:        graphqlClient,
-		genqlient:      genqlient.NewClient(config.graphqlURL, graphqlHttpClient),
-		http:           restHttpClient,
-		organization:   config.org,
-		organizationId: nil,
-		restURL:        config.restURL,
-		timeouts:       config.timeouts,
-		restRetry:      restRetryClient,
-		graphqlRetry:   graphqlRetryClient,
+	client := &Client{
+		graphql:      graphqlClient,
+		genqlient:    genqlient.NewClient(config.graphqlURL, graphqlHttpClient),
+		http:         restHttpClient,
+		organization: config.org,
+		lookups:      &clientLookups{},
+		restURL:      config.restURL,
+		timeouts:     config.timeouts,
+		portalHTTP:   portalRetryClient.StandardClient(),
+		portalURL:    config.portalURL,
+		restRetry:    restRetryClient,
+		graphqlRetry: graphqlRetryClient,
 	}
+	if config.cacheReads {
+		client.cache = newResponseCache()
+		client.genqlient = &cachingGraphQLClient{next: client.genqlient, cache: client.cache}
+	}
+
+	return client
 }
 
 func newHeaderRoundTripper(next http.RoundTripper, header http.Header) *headerRoundTripper {
@@ -283,8 +353,8 @@ type reopenedBody struct {
 }
 
 // captureFrom returns the capture carried by a request context, or nil for requests that do not
-// install one. Only makeRequest installs one, so GraphQL calls and the registry resources that use
-// client.http directly get nil, and the methods below are no-ops for them.
+// install one. Only makeRequest installs one, so GraphQL calls and anything using client.http
+// directly get nil, and the methods below are no-ops for them.
 func captureFrom(ctx context.Context) *lastResponseCapture {
 	capture, _ := ctx.Value(lastResponseKey{}).(*lastResponseCapture)
 	return capture
@@ -429,6 +499,10 @@ func (client *Client) makeRequest(ctx context.Context, method string, path strin
 		}
 	}
 
+	if served, err := client.cachedRESTRead(ctx, method, path, responseObject); served {
+		return err
+	}
+
 	lastResponse := &lastResponseCapture{}
 	ctx = context.WithValue(ctx, lastResponseKey{}, lastResponse)
 
@@ -454,6 +528,10 @@ func (client *Client) makeRequest(ctx context.Context, method string, path strin
 	}
 
 	resp, err := client.http.Do(req)
+	if method != http.MethodGet {
+		// Whatever the outcome, the write may have been applied.
+		client.storeRESTResponse(ctx, method, path, postData, nil)
+	}
 	if err != nil {
 		// A retryable status that never clears usually ends up here rather than in the status check
 		// below: if the deadline lands during a backoff wait, retryablehttp returns the context error
@@ -539,6 +617,9 @@ func (client *Client) makeRequest(ctx context.Context, method string, path strin
 	if err := json.Unmarshal(responseBody, responseObject); err != nil {
 		return fmt.Errorf("failed to unmarshal response: %w", err)
 	}
+	if method == http.MethodGet {
+		client.storeRESTResponse(ctx, method, path, nil, responseBody)
+	}
 
 	return nil
 }
@@ -1,6 +1,7 @@
 package buildkite
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
 	"github.com/shurcooL/graphql"
 )
 
@@ -37,13 +39,14 @@ func TestClientGetOrganizationIDNotCachedOnError(t *testing.T) {
 	client := &Client{
 		graphql:      graphql.NewClient(server.URL, server.Client()),
 		organization: "test-org",
+		lookups:      &clientLookups{},
 	}
 
 	if _, err := client.GetOrganizationID(); err == nil {
 		t.Fatal("expected error from first lookup, got nil")
 	}
-	if client.organizationId != nil {
-		t.Fatalf("organizationId was cached after a failed lookup: %q", *client.organizationId)
+	if client.lookups.organizationId != nil {
+		t.Fatalf("organizationId was cached after a failed lookup: %q", *client.lookups.organizationId)
 	}
 
 	id, err := client.GetOrganizationID()
@@ -77,6 +80,7 @@ func TestClientGetOrganizationIDConcurrent(t *testing.T) {
 	client := &Client{
 		graphql:      graphql.NewClient(server.URL, server.Client()),
 		organization: "test-org",
+		lookups:      &clientLookups{},
 	}
 
 	const goroutines = 32
@@ -101,3 +105,76 @@ func TestClientGetOrganizationIDConcurrent(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// The fake API has to answer in shapes the generated client can decode, or tests run against it
+// would pass for the wrong reasons.
+func TestClientAgainstFakeBuildkite(t *testing.T) {
+	t.Parallel()
+
+	server := fakebuildkite.New(t, "test-org")
+	client := NewClient(&clientConfig{
+		apiToken:   "test",
+		graphqlURL: server.GraphQLURL(),
+		restURL:    server.URL(),
+		org:        "test-org",
+		userAgent:  "test",
+	})
+	ctx := context.Background()
+
+	orgID, err := client.GetOrganizationID()
+	if err != nil {
+		t.Fatalf("GetOrganizationID() error = %v", err)
+	}
+	_, wantOrgID, _ := server.Organization()
+	if *orgID != wantOrgID {
+		t.Errorf("GetOrganizationID() = %s, want %s", *orgID, wantOrgID)
+	}
+
+	description := "builds"
+	created, err := createCluster(ctx, client.genqlient, *orgID, "cluster", &description, nil, nil)
+	if err != nil {
+		t.Fatalf("createCluster() error = %v", err)
+	}
+	clusterID := created.ClusterCreate.Cluster.Id
+	queue, err := createClusterQueue(ctx, client.genqlient, *orgID, clusterID, "default", nil, nil)
+	if err != nil {
+		t.Fatalf("createClusterQueue() error = %v", err)
+	}
+	if _, err := setClusterDefaultQueue(ctx, client.genqlient, *orgID, clusterID, queue.ClusterQueueCreate.ClusterQueue.Id); err != nil {
+		t.Fatalf("setClusterDefaultQueue() error = %v", err)
+	}
+
+	node, err := getNode(ctx, client.genqlient, clusterID)
+	if err != nil {
+		t.Fatalf("getNode() error = %v", err)
+	}
+	cluster, ok := node.GetNode().(*getNodeNodeCluster)
+	if !ok {
+		t.Fatalf("getNode() returned %T, want a cluster", node.GetNode())
+	}
+	if cluster.Name != "cluster" || *cluster.Description != "builds" || cluster.DefaultQueue.Key != "default" {
+		t.Errorf("cluster = %+v", cluster)
+	}
+
+	var settings clusterQueueRestResponse
+	path := fmt.Sprintf("/v2/organizations/test-org/clusters/%s/queues/%s", cluster.Uuid, queue.ClusterQueueCreate.ClusterQueue.Uuid)
+	if err := client.makeRequest(ctx, http.MethodGet, path, nil, &settings); err != nil {
+		t.Fatalf("makeRequest() error = %v", err)
+	}
+	if settings.RetryAgentAffinity != RetryAgentAffinityPreferWarmest {
+		t.Errorf("retry_agent_affinity = %q, want %q", settings.RetryAgentAffinity, RetryAgentAffinityPreferWarmest)
+	}
+
+	if _, err := teamCreate(ctx, client.genqlient, *orgID, "Platform Team", "", "VISIBLE", false, "MEMBER", false, false, false, false, false); err != nil {
+		t.Fatalf("teamCreate() error = %v", err)
+	}
+	teamID, err := GetTeamID("platform-team", client)
+	if err != nil || teamID == "" {
+		t.Errorf("GetTeamID() = %q, %v, want the created team", teamID, err)
+	}
+
+	err = client.makeRequest(ctx, http.MethodGet, "/v2/organizations/test-org/portals/missing", nil, nil)
+	if !isAPIStatus(err, http.StatusNotFound) {
+		t.Errorf("reading a missing portal returned %v, want a 404", err)
+	}
+}
@@ -0,0 +1,364 @@
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"gopkg.in/yaml.v3"
+)
+
+// clusterSecretPolicyClaim is a claim an access policy rule can match on, and the access_policy
+// attribute that sets it.
+type clusterSecretPolicyClaim struct {
+	name      string
+	attribute string
+	validate  func(value string) error
+}
+
+// clusterSecretPolicyClaims are the claims Buildkite accepts in a secret's access policy, in the
+// order they are rendered.
+var clusterSecretPolicyClaims = []clusterSecretPolicyClaim{
+	{name: "pipeline_slug", attribute: "pipeline_slugs", validate: validatePolicyPipelineSlug},
+	{name: "build_branch", attribute: "build_branches", validate: validatePolicyPattern("branch")},
+	{name: "build_creator", attribute: "build_creators", validate: validatePolicyUUID("user")},
+	{name: "build_creator_team", attribute: "build_creator_teams", validate: validatePolicyUUID("team")},
+	{name: "build_source", attribute: "build_sources", validate: validatePolicyBuildSource},
+	{name: "cluster_queue_key", attribute: "cluster_queue_keys", validate: validatePolicyPattern("queue key")},
+}
+
+var clusterSecretPolicyBuildSources = []string{"ui", "api", "webhook", "trigger_job", "schedule"}
+
+var clusterSecretPolicyPipelineSlug = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// clusterSecretPolicyRule maps each claim a rule matches on to its sorted values. A job can access
+// the secret when it matches every claim of at least one rule, and a claim matches any of its
+// values.
+type clusterSecretPolicyRule map[string][]string
+
+var clusterSecretPolicyRuleAttrTypes = func() map[string]attr.Type {
+	attrTypes := make(map[string]attr.Type, len(clusterSecretPolicyClaims))
+	for _, claim := range clusterSecretPolicyClaims {
+		attrTypes[claim.attribute] = types.SetType{ElemType: types.StringType}
+	}
+	return attrTypes
+}()
+
+func clusterSecretPolicyClaimNames() []string {
+	names := make([]string, len(clusterSecretPolicyClaims))
+	for i, claim := range clusterSecretPolicyClaims {
+		names[i] = claim.name
+	}
+	return names
+}
+
+func validatePolicyPipelineSlug(value string) error {
+	if !clusterSecretPolicyPipelineSlug.MatchString(value) {
+		return fmt.Errorf("%q is not a pipeline slug; use the slug from the pipeline's URL, such as my-pipeline", value)
+	}
+	return nil
+}
+
+func validatePolicyPattern(name string) func(string) error {
+	return func(value string) error {
+		if value == "" || strings.ContainsAny(value, " \t\n") {
+			return fmt.Errorf("%q is not a valid %s: it must be non-empty and must not contain whitespace", value, name)
+		}
+		return nil
+	}
+}
+
+func validatePolicyUUID(name string) func(string) error {
+	return func(value string) error {
+		if !isUUID(value) {
+			return fmt.Errorf("%q is not a %s UUID", value, name)
+		}
+		return nil
+	}
+}
+
+func validatePolicyBuildSource(value string) error {
+	if !slices.Contains(clusterSecretPolicyBuildSources, value) {
+		return fmt.Errorf("%q is not a build source, expected one of %s", value, strings.Join(clusterSecretPolicyBuildSources, ", "))
+	}
+	return nil
+}
+
+// validate checks that the rule matches on at least one claim and that every value is well formed.
+func (rule clusterSecretPolicyRule) validate() error {
+	if len(rule) == 0 {
+		return fmt.Errorf("the rule matches no claims, so it would not grant access to any job")
+	}
+	for _, claim := range clusterSecretPolicyClaims {
+		for _, value := range rule[claim.name] {
+			if err := claim.validate(value); err != nil {
+				return fmt.Errorf("%s: %w", claim.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseClusterSecretPolicy parses a YAML access policy, rejecting unknown claims and malformed
+// values that would otherwise leave the secret silently inaccessible.
+func parseClusterSecretPolicy(policy string) ([]clusterSecretPolicyRule, error) {
+	if strings.TrimSpace(policy) == "" {
+		return nil, nil
+	}
+
+	var document []map[string]yaml.Node
+	if err := yaml.Unmarshal([]byte(policy), &document); err != nil {
+		return nil, fmt.Errorf("the policy must be a YAML list of rules: %w", err)
+	}
+
+	rules := make([]clusterSecretPolicyRule, 0, len(document))
+	for i, raw := range document {
+		rule := clusterSecretPolicyRule{}
+		for name, node := range raw {
+			if !slices.Contains(clusterSecretPolicyClaimNames(), name) {
+				return nil, fmt.Errorf("rule %d: unknown claim %q, expected one of %s", i+1, name, strings.Join(clusterSecretPolicyClaimNames(), ", "))
+			}
+			var values []string
+			switch node.Kind {
+			case yaml.ScalarNode:
+				values = []string{node.Value}
+			case yaml.SequenceNode:
+				for _, item := range node.Content {
+					if item.Kind != yaml.ScalarNode {
+						return nil, fmt.Errorf("rule %d: %s must be a string or a list of strings", i+1, name)
+					}
+					values = append(values, item.Value)
+				}
+			default:
+				return nil, fmt.Errorf("rule %d: %s must be a string or a list of strings", i+1, name)
+			}
+			slices.Sort(values)
+			rule[name] = slices.Compact(values)
+		}
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// renderClusterSecretPolicy renders rules as canonical YAML: claims in a fixed order, and a single
+// value as a scalar rather than a one-item list.
+func renderClusterSecretPolicy(rules []clusterSecretPolicyRule) string {
+	document := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, rule := range rules {
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, claim := range clusterSecretPolicyClaims {
+			values, ok := rule[claim.name]
+			if !ok {
+				continue
+			}
+			value := &yaml.Node{Kind: yaml.SequenceNode}
+			for _, v := range values {
+				value.Content = append(value.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v})
+			}
+			if len(values) == 1 {
+				value = value.Content[0]
+			}
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: claim.name}, value)
+		}
+		document.Content = append(document.Content, mapping)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	// Encoding a tree of plain scalars cannot fail.
+	_ = encoder.Encode(document)
+	_ = encoder.Close()
+	return buf.String()
+}
+
+// clusterSecretPoliciesEqual reports whether two YAML policies grant the same access, ignoring
+// formatting, claim order and value order. Policies that do not parse are compared as text.
+func clusterSecretPoliciesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	rulesA, errA := parseClusterSecretPolicy(a)
+	rulesB, errB := parseClusterSecretPolicy(b)
+	if errA != nil || errB != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+	return renderClusterSecretPolicy(rulesA) == renderClusterSecretPolicy(rulesB)
+}
+
+// clusterSecretPolicyRulesFromList reads access_policy rules. It returns false when a rule is not
+// yet known.
+func clusterSecretPolicyRulesFromList(ctx context.Context, list types.List) ([]clusterSecretPolicyRule, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if list.IsUnknown() {
+		return nil, false, diags
+	}
+
+	var objects []types.Object
+	diags.Append(list.ElementsAs(ctx, &objects, false)...)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+
+	rules := make([]clusterSecretPolicyRule, 0, len(objects))
+	for _, object := range objects {
+		if object.IsUnknown() {
+			return nil, false, diags
+		}
+		rule := clusterSecretPolicyRule{}
+		attributes := object.Attributes()
+		for _, claim := range clusterSecretPolicyClaims {
+			set, ok := attributes[claim.attribute].(basetypes.SetValue)
+			if !ok || set.IsNull() {
+				continue
+			}
+			if set.IsUnknown() || slices.ContainsFunc(set.Elements(), attr.Value.IsUnknown) {
+				return nil, false, diags
+			}
+			var values []string
+			diags.Append(set.ElementsAs(ctx, &values, false)...)
+			slices.Sort(values)
+			rule[claim.name] = slices.Compact(values)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, true, diags
+}
+
+// clusterSecretPolicyRulesToList converts rules to an access_policy value.
+func clusterSecretPolicyRulesToList(ctx context.Context, rules []clusterSecretPolicyRule) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	objectType := types.ObjectType{AttrTypes: clusterSecretPolicyRuleAttrTypes}
+	objects := make([]attr.Value, 0, len(rules))
+	for _, rule := range rules {
+		attributes := make(map[string]attr.Value, len(clusterSecretPolicyClaims))
+		for _, claim := range clusterSecretPolicyClaims {
+			values, ok := rule[claim.name]
+			if !ok {
+				attributes[claim.attribute] = types.SetNull(types.StringType)
+				continue
+			}
+			set, setDiags := types.SetValueFrom(ctx, types.StringType, values)
+			diags.Append(setDiags...)
+			attributes[claim.attribute] = set
+		}
+		object, objectDiags := types.ObjectValue(clusterSecretPolicyRuleAttrTypes, attributes)
+		diags.Append(objectDiags...)
+		objects = append(objects, object)
+	}
+
+	list, listDiags := types.ListValue(objectType, objects)
+	diags.Append(listDiags...)
+	return list, diags
+}
+
+// planClusterSecretPolicy validates the configured policy or access_policy rules and plans the
+// policy attribute, which is rendered from the rules when they are used.
+func planClusterSecretPolicy(ctx context.Context, client *Client, policy types.String, accessPolicy types.List, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var statePolicy types.String
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("policy"), &statePolicy)...)
+	}
+
+	var rules []clusterSecretPolicyRule
+	switch {
+	case !policy.IsNull():
+		if policy.IsUnknown() {
+			return
+		}
+		var err error
+		if rules, err = parseClusterSecretPolicy(policy.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("policy"), "Invalid cluster secret policy", err.Error())
+			return
+		}
+	case !accessPolicy.IsNull():
+		var known bool
+		var diags diag.Diagnostics
+		rules, known, diags = clusterSecretPolicyRulesFromList(ctx, accessPolicy)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() || !known {
+			return
+		}
+		for i, rule := range rules {
+			if err := rule.validate(); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("access_policy").AtListIndex(i), "Invalid cluster secret access policy rule", err.Error())
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		rendered := types.StringValue(renderClusterSecretPolicy(rules))
+		// Keep the API's formatting of an equivalent policy so the plan has no change.
+		if !statePolicy.IsNull() && clusterSecretPoliciesEqual(statePolicy.ValueString(), rendered.ValueString()) {
+			rendered = statePolicy
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("policy"), rendered)...)
+	default:
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("policy"), types.StringNull())...)
+	}
+
+	if statePolicy.IsNull() || !clusterSecretPoliciesEqual(statePolicy.ValueString(), renderClusterSecretPolicy(rules)) {
+		warnMissingPolicyPipelines(ctx, client, rules, resp)
+	}
+}
+
+// warnMissingPolicyPipelines warns about policy pipeline slugs that do not match a pipeline, which
+// is usually a typo. It is not an error because the pipeline may be created in the same apply.
+func warnMissingPolicyPipelines(ctx context.Context, client *Client, rules []clusterSecretPolicyRule, resp *resource.ModifyPlanResponse) {
+	if client == nil {
+		return
+	}
+
+	checked := map[string]bool{}
+	for _, rule := range rules {
+		for _, slug := range rule["pipeline_slug"] {
+			if checked[slug] {
+				continue
+			}
+			checked[slug] = true
+
+			pipeline, err := getPipeline(ctx, client.genqlient, fmt.Sprintf("%s/%s", client.organization, slug))
+			if err != nil || pipeline.Pipeline.Id != "" {
+				continue
+			}
+			resp.Diagnostics.AddWarning(
+				"Cluster secret policy references an unknown pipeline",
+				fmt.Sprintf("No pipeline with the slug %q exists in the %s organization, so the policy does not grant it access to this secret. Check the slug for typos.", slug, client.organization),
+			)
+		}
+	}
+}
+
+// refreshClusterSecretPolicy records the policy the API returned unless it means the same as the
+// one in state, so the API's formatting does not cause a diff. Drift also shows in access_policy
+// when it is used; a policy the rules cannot express clears them, so the next apply restores them.
+func refreshClusterSecretPolicy(ctx context.Context, policy *types.String, accessPolicy *types.List, apiPolicy string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !policy.IsNull() && clusterSecretPoliciesEqual(policy.ValueString(), apiPolicy) {
+		return diags
+	}
+
+	*policy = types.StringValue(apiPolicy)
+	if !accessPolicy.IsNull() {
+		*accessPolicy = types.ListNull(types.ObjectType{AttrTypes: clusterSecretPolicyRuleAttrTypes})
+		if rules, err := parseClusterSecretPolicy(apiPolicy); err == nil {
+			*accessPolicy, diags = clusterSecretPolicyRulesToList(ctx, rules)
+		}
+	}
+	return diags
+}
@@ -0,0 +1,170 @@
+package buildkite
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestParseClusterSecretPolicy(t *testing.T) {
+	t.Parallel()
+
+	rules, err := parseClusterSecretPolicy(`
+- pipeline_slug: deploy
+  build_branch: [release/*, main, main]
+- build_source: schedule
+  cluster_queue_key: "0123"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `- pipeline_slug: deploy
+  build_branch:
+    - main
+    - release/*
+- build_source: schedule
+  cluster_queue_key: "0123"
+`
+	if got := renderClusterSecretPolicy(rules); got != want {
+		t.Errorf("renderClusterSecretPolicy() =\n%s\nwant\n%s", got, want)
+	}
+
+	if rules, err := parseClusterSecretPolicy("  \n"); err != nil || len(rules) != 0 {
+		t.Errorf("an empty policy = %v, %v, want no rules", rules, err)
+	}
+}
+
+func TestParseClusterSecretPolicyRejectsTypos(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"pipline_slug: deploy":                        "must be a YAML list of rules",
+		"- pipline_slug: deploy":                      `rule 1: unknown claim "pipline_slug"`,
+		"- pipeline_slug: Deploy Pipeline":            `"Deploy Pipeline" is not a pipeline slug`,
+		"- pipeline_slug: {name: deploy}":             "pipeline_slug must be a string or a list of strings",
+		"- build_branch: main\n- build_source: cron":  `rule 2: build_source: "cron" is not a build source`,
+		"- build_creator: not-a-uuid":                 `"not-a-uuid" is not a user UUID`,
+		"- build_branch: main branch":                 "must not contain whitespace",
+		"- {}":                                        "the rule matches no claims",
+		"- pipeline_slug: [deploy, [nested, values]]": "must be a string or a list of strings",
+	}
+
+	for policy, want := range testCases {
+		t.Run(policy, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseClusterSecretPolicy(policy)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("parseClusterSecretPolicy(%q) error = %v, want one containing %q", policy, err, want)
+			}
+		})
+	}
+}
+
+func TestClusterSecretPoliciesEqual(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		a, b string
+		want bool
+	}{
+		{
+			a:    "- pipeline_slug: deploy\n  build_branch: main\n",
+			b:    "---\n- build_branch: [\"main\"]\n  pipeline_slug: 'deploy'",
+			want: true,
+		},
+		{
+			a:    "- build_branch: [main, develop]",
+			b:    "- build_branch:\n    - develop\n    - main\n",
+			want: true,
+		},
+		{
+			a:    "- pipeline_slug: deploy",
+			b:    "- pipeline_slug: deploy-staging",
+			want: false,
+		},
+		{
+			a:    "- pipeline_slug: deploy\n- build_branch: main",
+			b:    "- pipeline_slug: deploy\n  build_branch: main",
+			want: false,
+		},
+		{
+			a:    "- unknown_claim: x\n",
+			b:    "- unknown_claim: x",
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := clusterSecretPoliciesEqual(tc.a, tc.b); got != tc.want {
+			t.Errorf("clusterSecretPoliciesEqual(%q, %q) = %t, want %t", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestUnitBuildkiteClusterSecretAccessPolicy(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	server.HandleGraphQL("getPipeline", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		if variables["slug"] == "test-org/deploy" {
+			return fakebuildkite.Object{"pipeline": fakebuildkite.Object{"id": "UGlwZWxpbmUtLS0x", "slug": "deploy"}}, nil
+		}
+		return fakebuildkite.Object{"pipeline": nil}, nil
+	})
+
+	config := func(rules string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_cluster" "cluster" {
+				name = "cluster"
+			}
+
+			resource "buildkite_cluster_secret" "secret" {
+				cluster_id    = buildkite_cluster.cluster.uuid
+				key           = "DEPLOY_TOKEN"
+				value         = "secret"
+				access_policy = %s
+			}
+		`, rules)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config(`[{ pipeline_slugs = ["deploy"], build_branches = ["main", "release/*"] }]`),
+				Check: resource.TestCheckResourceAttr("buildkite_cluster_secret.secret", "policy",
+					"- pipeline_slug: deploy\n  build_branch:\n    - main\n    - release/*\n"),
+			},
+			{
+				// The API re-serialises the policy; the typed rules still match it.
+				PreConfig: func() {
+					server.Update(func(st *fakebuildkite.State) {
+						cluster := st.Nodes("Cluster", nil)[0]
+						for _, secret := range st.Objects(fmt.Sprintf("/v2/organizations/test-org/clusters/%s/secrets", cluster["uuid"])) {
+							secret["policy"] = "---\n- build_branch:\n  - release/*\n  - main\n  pipeline_slug: deploy\n"
+						}
+					})
+				},
+				Config: config(`[{ pipeline_slugs = ["deploy"], build_branches = ["main", "release/*"] }]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_cluster_secret.secret", plancheck.ResourceActionNoop),
+					},
+				},
+			},
+			{
+				Config:      config(`[{ pipeline_slugs = ["deploy"], build_sources = ["cron"] }]`),
+				ExpectError: regexp.MustCompile(`build_source: "cron" is not a build source`),
+			},
+			{
+				Config: config(`[{ pipeline_slugs = ["deploy", "depoly"] }]`),
+				Check: resource.TestCheckResourceAttr("buildkite_cluster_secret.secret", "policy",
+					"- pipeline_slug:\n    - deploy\n    - depoly\n"),
+			},
+		},
+	})
+}
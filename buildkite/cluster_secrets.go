@@ -30,6 +30,27 @@ func (c *Client) GetClusterSecret(ctx context.Context, orgSlug, clusterID, secre
 	return &secret, nil
 }
 
+// clusterSecretsPageSize is the most secrets the REST API returns per page.
+const clusterSecretsPageSize = 100
+
+// ListClusterSecrets retrieves every secret in a cluster, following pagination
+func (c *Client) ListClusterSecrets(ctx context.Context, orgSlug, clusterID string) ([]ClusterSecret, error) {
+	var secrets []ClusterSecret
+	for page := 1; ; page++ {
+		path := fmt.Sprintf("/v2/organizations/%s/clusters/%s/secrets?page=%d&per_page=%d", orgSlug, clusterID, page, clusterSecretsPageSize)
+
+		var batch []ClusterSecret
+		if err := c.makeRequest(ctx, http.MethodGet, path, nil, &batch); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, batch...)
+
+		if len(batch) < clusterSecretsPageSize {
+			return secrets, nil
+		}
+	}
+}
+
 // CreateClusterSecret creates a new cluster secret
 func (c *Client) CreateClusterSecret(ctx context.Context, orgSlug, clusterID string, secret *ClusterSecret) (*ClusterSecret, error) {
 	path := fmt.Sprintf("/v2/organizations/%s/clusters/%s/secrets", orgSlug, clusterID)
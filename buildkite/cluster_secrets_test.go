@@ -3,8 +3,10 @@ package buildkite
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 )
 
@@ -52,6 +54,44 @@ func TestGetClusterSecret(t *testing.T) {
 	}
 }
 
+func TestListClusterSecrets(t *testing.T) {
+	const total = clusterSecretsPageSize + 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/organizations/test-org/clusters/cluster-123/secrets" {
+			t.Errorf("Expected path /v2/organizations/test-org/clusters/cluster-123/secrets, got %s", r.URL.Path)
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+
+		secrets := []ClusterSecret{}
+		for i := (page - 1) * perPage; i < min(page*perPage, total); i++ {
+			secrets = append(secrets, ClusterSecret{ID: fmt.Sprintf("secret-%d", i), Key: fmt.Sprintf("SECRET_%d", i)})
+		}
+		if err := json.NewEncoder(w).Encode(secrets); err != nil {
+			t.Fatalf("failed to encode secrets: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	client := &Client{
+		http:         server.Client(),
+		restURL:      server.URL,
+		organization: "test-org",
+	}
+
+	secrets, err := client.ListClusterSecrets(context.Background(), "test-org", "cluster-123")
+	if err != nil {
+		t.Fatalf("ListClusterSecrets failed: %v", err)
+	}
+
+	if len(secrets) != total {
+		t.Fatalf("Expected %d secrets, got %d", total, len(secrets))
+	}
+	if secrets[total-1].Key != fmt.Sprintf("SECRET_%d", total-1) {
+		t.Errorf("Expected the last secret from the second page, got %s", secrets[total-1].Key)
+	}
+}
+
 func TestCreateClusterSecret(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
@@ -0,0 +1,310 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	// Embedded so that timezone suffixes validate the same way on machines without a zoneinfo
+	// database, such as Windows and minimal CI images.
+	_ "time/tzdata"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+// cronlineShortcuts are the predefined intervals Buildkite accepts in place of the five cron
+// fields, with or without the leading @.
+var cronlineShortcuts = map[string]string{
+	"hourly":   "0 * * * *",
+	"daily":    "0 0 * * *",
+	"midnight": "0 0 * * *",
+	"weekly":   "0 0 * * 0",
+	"monthly":  "0 0 1 * *",
+	"yearly":   "0 0 1 1 *",
+	"annually": "0 0 1 1 *",
+}
+
+var (
+	cronlineMonths   = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+	cronlineWeekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+)
+
+// cronlineSearchYears bounds the search for the next run. Every valid expression fires at least
+// once in eight years, the longest gap between two leap days.
+const cronlineSearchYears = 8
+
+// cronline is a parsed Buildkite schedule: standard five-field cron, optionally followed by an IANA
+// timezone, with the predefined intervals as shorthand. Each field is a bit set of the values it
+// allows.
+type cronline struct {
+	minutes, hours, days, months, weekdays uint64
+	// daysStar and weekdaysStar record a field that starts with *. As in Vixie cron, when both day
+	// fields are restricted a day matching either one fires.
+	daysStar, weekdaysStar bool
+	// lastDay is set by L in the day of month field.
+	lastDay bool
+	// nthWeekdays holds day of week items such as mon#2, the second Monday of the month.
+	nthWeekdays []nthWeekday
+	location    *time.Location
+}
+
+type nthWeekday struct {
+	weekday time.Weekday
+	n       int
+}
+
+// parseCronline parses a cronline the way Buildkite's scheduler reads it, returning an error that
+// names the offending field.
+func parseCronline(value string) (*cronline, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("cronline is empty")
+	}
+
+	if expansion, ok := cronlineShortcuts[strings.TrimPrefix(strings.ToLower(fields[0]), "@")]; ok {
+		fields = append(strings.Fields(expansion), fields[1:]...)
+	} else if strings.HasPrefix(fields[0], "@") {
+		return nil, fmt.Errorf("unknown interval %q, expected one of @hourly, @daily, @weekly, @monthly or @yearly", fields[0])
+	}
+
+	c := &cronline{location: time.UTC}
+	switch len(fields) {
+	case 5:
+	case 6:
+		location, err := time.LoadLocation(fields[5])
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone %q, expected an IANA name such as Australia/Melbourne", fields[5])
+		}
+		c.location = location
+	default:
+		return nil, fmt.Errorf("expected 5 fields (minute, hour, day of month, month, day of week) and an optional timezone, got %d", len(fields))
+	}
+
+	var err error
+	if c.minutes, err = parseCronField(fields[0], "minute", 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if c.hours, err = parseCronField(fields[1], "hour", 0, 23, nil); err != nil {
+		return nil, err
+	}
+
+	days := fields[2]
+	c.daysStar = strings.HasPrefix(days, "*")
+	var dayItems []string
+	for _, item := range strings.Split(days, ",") {
+		if strings.EqualFold(item, "l") {
+			c.lastDay = true
+			continue
+		}
+		dayItems = append(dayItems, item)
+	}
+	if len(dayItems) > 0 {
+		if c.days, err = parseCronField(strings.Join(dayItems, ","), "day of month", 1, 31, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.months, err = parseCronField(fields[3], "month", 1, 12, cronlineMonths); err != nil {
+		return nil, err
+	}
+
+	weekdays := fields[4]
+	c.weekdaysStar = strings.HasPrefix(weekdays, "*")
+	var weekdayItems []string
+	for _, item := range strings.Split(weekdays, ",") {
+		day, nth, ok := strings.Cut(item, "#")
+		if !ok {
+			weekdayItems = append(weekdayItems, item)
+			continue
+		}
+		weekday, err := parseCronValue(day, "day of week", 0, 7, cronlineWeekdays)
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(nth)
+		if err != nil || n < 1 || n > 5 {
+			return nil, fmt.Errorf("invalid day of week %q: the number after # must be between 1 and 5", item)
+		}
+		c.nthWeekdays = append(c.nthWeekdays, nthWeekday{weekday: time.Weekday(weekday % 7), n: n})
+	}
+	if len(weekdayItems) > 0 {
+		if c.weekdays, err = parseCronField(strings.Join(weekdayItems, ","), "day of week", 0, 7, cronlineWeekdays); err != nil {
+			return nil, err
+		}
+		// Both 0 and 7 are Sunday.
+		if c.weekdays&(1<<7) != 0 {
+			c.weekdays |= 1
+		}
+	}
+
+	if _, ok := c.next(time.Now()); !ok {
+		return nil, fmt.Errorf("cronline %q never runs", value)
+	}
+
+	return c, nil
+}
+
+// parseCronField parses a comma-separated list of values, ranges and steps such as 1-5,*/15.
+func parseCronField(field, name string, min, max int, names []string) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		span, stepText, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid %s %q: the step must be a positive number", name, item)
+			}
+		}
+
+		low, high := min, max
+		switch {
+		case span == "*":
+		case strings.Contains(span, "-"):
+			from, to, _ := strings.Cut(span, "-")
+			var err error
+			if low, err = parseCronValue(from, name, min, max, names); err != nil {
+				return 0, err
+			}
+			if high, err = parseCronValue(to, name, min, max, names); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid %s %q: the range is backwards", name, item)
+			}
+		default:
+			var err error
+			if low, err = parseCronValue(span, name, min, max, names); err != nil {
+				return 0, err
+			}
+			// A single value with a step, such as 5/15, runs from that value to the end of the range.
+			if !hasStep {
+				high = low
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			set |= 1 << value
+		}
+	}
+
+	return set, nil
+}
+
+func parseCronValue(text, name string, min, max int, names []string) (int, error) {
+	for i, candidate := range names {
+		if strings.EqualFold(text, candidate) {
+			return i + min, nil
+		}
+	}
+
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", name, text)
+	}
+	if value < min || value > max {
+		return 0, fmt.Errorf("invalid %s %q: must be between %d and %d", name, text, min, max)
+	}
+
+	return value, nil
+}
+
+func (c *cronline) matchesDay(t time.Time) bool {
+	if c.months&(1<<int(t.Month())) == 0 {
+		return false
+	}
+
+	day := t.Day()
+	lastDay := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	dayMatches := c.days&(1<<day) != 0 || (c.lastDay && day == lastDay)
+
+	weekdayMatches := c.weekdays&(1<<int(t.Weekday())) != 0
+	for _, nth := range c.nthWeekdays {
+		if t.Weekday() == nth.weekday && (day-1)/7+1 == nth.n {
+			weekdayMatches = true
+		}
+	}
+
+	switch {
+	case c.daysStar && c.weekdaysStar:
+		return true
+	case c.daysStar:
+		return weekdayMatches
+	case c.weekdaysStar:
+		return dayMatches
+	default:
+		return dayMatches || weekdayMatches
+	}
+}
+
+// next returns the first time after the given one that the schedule fires, in the schedule's
+// timezone. Local times skipped by a daylight saving change never fire.
+func (c *cronline) next(after time.Time) (time.Time, bool) {
+	local := after.In(c.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, c.location)
+	end := day.AddDate(cronlineSearchYears, 0, 0)
+
+	for ; day.Before(end); day = time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, c.location) {
+		if !c.matchesDay(day) {
+			continue
+		}
+		for hours := c.hours; hours != 0; hours &= hours - 1 {
+			hour := bits.TrailingZeros64(hours)
+			for minutes := c.minutes; minutes != 0; minutes &= minutes - 1 {
+				minute := bits.TrailingZeros64(minutes)
+				candidate := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, c.location)
+				if candidate.Hour() != hour || candidate.Minute() != minute {
+					continue
+				}
+				if candidate.After(after) {
+					return candidate, true
+				}
+			}
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// nextRuns returns up to n times after the given one that the schedule fires.
+func (c *cronline) nextRuns(after time.Time, n int) []time.Time {
+	runs := make([]time.Time, 0, n)
+	for len(runs) < n {
+		run, ok := c.next(after)
+		if !ok {
+			break
+		}
+		runs = append(runs, run)
+		after = run
+	}
+
+	return runs
+}
+
+// cronlineValidator rejects cronlines Buildkite's scheduler would refuse, at plan time rather than
+// when the API is called during apply.
+type cronlineValidator struct{}
+
+func (v cronlineValidator) Description(ctx context.Context) string {
+	return "value must be a five-field cron expression or predefined interval, optionally followed by an IANA timezone"
+}
+
+func (v cronlineValidator) MarkdownDescription(ctx context.Context) string {
+	return "value must be a five-field cron expression or predefined interval such as `@daily`, optionally followed by an IANA timezone"
+}
+
+func (v cronlineValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+	if _, err := parseCronline(req.ConfigValue.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid cronline",
+			err.Error(),
+		)
+	}
+}
@@ -0,0 +1,261 @@
+package buildkite
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestCronlineNextRuns(t *testing.T) {
+	t.Parallel()
+
+	// A Wednesday.
+	after := time.Date(2024, time.January, 31, 12, 30, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		cronline string
+		want     []string
+	}{
+		"daily shortcut": {
+			cronline: "@daily",
+			want:     []string{"2024-02-01T00:00:00Z", "2024-02-02T00:00:00Z", "2024-02-03T00:00:00Z"},
+		},
+		"shortcut without the @ and with a timezone": {
+			cronline: "hourly Australia/Melbourne",
+			want:     []string{"2024-02-01T00:00:00+11:00", "2024-02-01T01:00:00+11:00", "2024-02-01T02:00:00+11:00"},
+		},
+		"timezone suffix": {
+			cronline: "0 2 * * * Australia/Melbourne",
+			want:     []string{"2024-02-01T02:00:00+11:00", "2024-02-02T02:00:00+11:00", "2024-02-03T02:00:00+11:00"},
+		},
+		"steps and lists": {
+			cronline: "*/20 13,14 * * *",
+			want:     []string{"2024-01-31T13:00:00Z", "2024-01-31T13:20:00Z", "2024-01-31T13:40:00Z"},
+		},
+		"weekday names and ranges": {
+			cronline: "0 9 * * mon-fri",
+			want:     []string{"2024-02-01T09:00:00Z", "2024-02-02T09:00:00Z", "2024-02-05T09:00:00Z"},
+		},
+		"sunday as 7": {
+			cronline: "0 0 * * 7",
+			want:     []string{"2024-02-04T00:00:00Z", "2024-02-11T00:00:00Z", "2024-02-18T00:00:00Z"},
+		},
+		"month names": {
+			cronline: "0 0 1 mar,jun *",
+			want:     []string{"2024-03-01T00:00:00Z", "2024-06-01T00:00:00Z", "2025-03-01T00:00:00Z"},
+		},
+		"last day of the month": {
+			cronline: "0 0 L * *",
+			want:     []string{"2024-02-29T00:00:00Z", "2024-03-31T00:00:00Z", "2024-04-30T00:00:00Z"},
+		},
+		"nth weekday": {
+			cronline: "0 0 * * mon#2",
+			want:     []string{"2024-02-12T00:00:00Z", "2024-03-11T00:00:00Z", "2024-04-08T00:00:00Z"},
+		},
+		"restricted day of month and day of week fire on either": {
+			cronline: "0 0 15 * fri",
+			want:     []string{"2024-02-02T00:00:00Z", "2024-02-09T00:00:00Z", "2024-02-15T00:00:00Z"},
+		},
+		"leap day": {
+			cronline: "0 0 29 2 *",
+			want:     []string{"2024-02-29T00:00:00Z", "2028-02-29T00:00:00Z", "2032-02-29T00:00:00Z"},
+		},
+		"time skipped by daylight saving": {
+			// Clocks in New York jump from 02:00 to 03:00 on 10 March 2024.
+			cronline: "30 2 10,11 3 * America/New_York",
+			want:     []string{"2024-03-11T02:30:00-04:00", "2025-03-10T02:30:00-04:00", "2025-03-11T02:30:00-04:00"},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			schedule, err := parseCronline(tc.cronline)
+			if err != nil {
+				t.Fatalf("parseCronline(%q) error = %v", tc.cronline, err)
+			}
+			var got []string
+			for _, run := range schedule.nextRuns(after, len(tc.want)) {
+				got = append(got, run.Format(time.RFC3339))
+			}
+			if strings.Join(got, " ") != strings.Join(tc.want, " ") {
+				t.Errorf("nextRuns() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCronlineRejectsInvalidExpressions(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"":                         "cronline is empty",
+		"@fortnightly":             "unknown interval",
+		"0 2 * *":                  "expected 5 fields",
+		"0 0 0 2 * * *":            "expected 5 fields",
+		"0 2 * * * Mars/Olympus":   "unknown timezone",
+		"60 * * * *":               "invalid minute \"60\": must be between 0 and 59",
+		"0 24 * * *":               "invalid hour",
+		"0 0 0 * *":                "invalid day of month",
+		"0 0 * 13 *":               "invalid month",
+		"0 0 * * 8":                "invalid day of week",
+		"0 0 * * mon#6":            "between 1 and 5",
+		"*/0 * * * *":              "the step must be a positive number",
+		"0 5-1 * * *":              "the range is backwards",
+		"0 0 * foo *":              "invalid month \"foo\"",
+		"0 0 30 feb *":             "never runs",
+		"0 0 31 apr,jun,sep,nov *": "never runs",
+	}
+
+	for cronline, want := range testCases {
+		t.Run(cronline, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseCronline(cronline)
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("parseCronline(%q) error = %v, want one containing %q", cronline, err, want)
+			}
+		})
+	}
+}
+
+func TestCronlineValidator(t *testing.T) {
+	t.Parallel()
+
+	for value, wantError := range map[string]bool{
+		"@daily":                        false,
+		"0 2 * * * Australia/Melbourne": false,
+		"0 2 * * * Melbourne":           true,
+	} {
+		resp := &validator.StringResponse{}
+		cronlineValidator{}.ValidateString(context.Background(), validator.StringRequest{
+			Path:        path.Root("cronline"),
+			ConfigValue: types.StringValue(value),
+		}, resp)
+		if resp.Diagnostics.HasError() != wantError {
+			t.Errorf("ValidateString(%q) diagnostics = %v, want error %t", value, resp.Diagnostics, wantError)
+		}
+	}
+
+	resp := &validator.StringResponse{}
+	cronlineValidator{}.ValidateString(context.Background(), validator.StringRequest{ConfigValue: types.StringUnknown()}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Errorf("an unknown cronline should not be validated: %v", resp.Diagnostics)
+	}
+}
+
+func TestPipelineScheduleRuns(t *testing.T) {
+	now := time.Date(2024, time.January, 31, 12, 30, 0, 0, time.UTC)
+	pipelineScheduleNow = func() time.Time { return now }
+	t.Cleanup(func() { pipelineScheduleNow = time.Now })
+	ctx := context.Background()
+
+	nextRunAt, nextRuns, diags := pipelineScheduleRuns(ctx, "0 2 * * * Australia/Melbourne", true, nil)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if nextRunAt.ValueString() != "2024-02-01T02:00:00+11:00" || len(nextRuns.Elements()) != pipelineScheduleNextRunsCount {
+		t.Errorf("next_run_at = %s, next_runs = %s", nextRunAt, nextRuns)
+	}
+
+	// Buildkite's own answer wins, shown in the schedule's timezone.
+	nextBuildAt := time.Date(2024, time.January, 31, 16, 0, 0, 0, time.UTC)
+	nextRunAt, _, _ = pipelineScheduleRuns(ctx, "0 2 * * * Australia/Melbourne", true, &nextBuildAt)
+	if nextRunAt.ValueString() != "2024-02-01T03:00:00+11:00" {
+		t.Errorf("next_run_at = %s, want Buildkite's next build time", nextRunAt)
+	}
+
+	nextRunAt, nextRuns, _ = pipelineScheduleRuns(ctx, "@daily", false, nil)
+	if !nextRunAt.IsNull() || len(nextRuns.Elements()) != 0 {
+		t.Errorf("a disabled schedule should not run: next_run_at = %s, next_runs = %s", nextRunAt, nextRuns)
+	}
+
+	// A cronline Buildkite accepted but the parser does not understand is not an error on read.
+	nextRunAt, nextRuns, diags = pipelineScheduleRuns(ctx, "every day at noon", true, &nextBuildAt)
+	if diags.HasError() || nextRunAt.ValueString() != "2024-01-31T16:00:00Z" || !nextRuns.IsNull() {
+		t.Errorf("next_run_at = %s, next_runs = %s, diags = %v", nextRunAt, nextRuns, diags)
+	}
+}
+
+func TestPipelineScheduleModifyPlan(t *testing.T) {
+	now := time.Date(2024, time.January, 31, 12, 30, 0, 0, time.UTC)
+	pipelineScheduleNow = func() time.Time { return now }
+	t.Cleanup(func() { pipelineScheduleNow = time.Now })
+	ctx := context.Background()
+
+	ps := &pipelineSchedule{}
+	var schemaResp frameworkresource.SchemaResponse
+	ps.Schema(ctx, frameworkresource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	model := func(cronline string, nextRunAt types.String, nextRuns types.List) pipelineScheduleResourceModel {
+		return pipelineScheduleResourceModel{
+			Id: types.StringValue("UGlwZWxpbmVTY2hlZHVsZS0tLTE="), Uuid: types.StringValue("schedule"), Label: types.StringValue("nightly"),
+			Cronline: types.StringValue(cronline), Commit: types.StringValue("HEAD"), Branch: types.StringValue("main"), Message: types.StringNull(),
+			Env: types.MapNull(types.StringType), Enabled: types.BoolValue(true), PipelineId: types.StringValue("UGlwZWxpbmUtLS0x"),
+			NextRunAt: nextRunAt, NextRuns: nextRuns,
+		}
+	}
+	modifyPlan := func(state *pipelineScheduleResourceModel, plan pipelineScheduleResourceModel) (*frameworkresource.ModifyPlanResponse, pipelineScheduleResourceModel) {
+		t.Helper()
+
+		req := frameworkresource.ModifyPlanRequest{
+			Plan:  tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)},
+			State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)},
+		}
+		if diags := req.Plan.Set(ctx, &plan); diags.HasError() {
+			t.Fatal(diags)
+		}
+		if state != nil {
+			if diags := req.State.Set(ctx, state); diags.HasError() {
+				t.Fatal(diags)
+			}
+		}
+		resp := &frameworkresource.ModifyPlanResponse{Plan: req.Plan}
+		ps.ModifyPlan(ctx, req, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatal(resp.Diagnostics)
+		}
+
+		var planned pipelineScheduleResourceModel
+		if diags := resp.Plan.Get(ctx, &planned); diags.HasError() {
+			t.Fatal(diags)
+		}
+		return resp, planned
+	}
+
+	read := types.ListValueMust(types.StringType, []attr.Value{types.StringValue("2024-02-01T02:00:00Z")})
+	unknown := model("0 2 * * *", types.StringUnknown(), types.ListUnknown(types.StringType))
+
+	// A new schedule's runs are only known once Buildkite has it, and are previewed as a warning.
+	resp, planned := modifyPlan(nil, unknown)
+	if !planned.NextRunAt.IsUnknown() || !planned.NextRuns.IsUnknown() {
+		t.Errorf("planned next_run_at = %s, next_runs = %s, want both unknown", planned.NextRunAt, planned.NextRuns)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 || !strings.Contains(resp.Diagnostics.Warnings()[0].Detail(), "2024-02-01T02:00:00Z") {
+		t.Errorf("diagnostics = %v, want a warning previewing the next run", resp.Diagnostics)
+	}
+
+	// Unchanged schedules keep what the last refresh read.
+	state := model("0 2 * * *", types.StringValue("2024-02-01T02:00:00Z"), read)
+	resp, planned = modifyPlan(&state, unknown)
+	if !planned.NextRunAt.Equal(state.NextRunAt) || !planned.NextRuns.Equal(read) || resp.Diagnostics.WarningsCount() != 0 {
+		t.Errorf("planned next_run_at = %s, next_runs = %s, diags = %v, want the state's", planned.NextRunAt, planned.NextRuns, resp.Diagnostics)
+	}
+
+	// A changed cronline plans them again at apply time.
+	_, planned = modifyPlan(&state, model("0 3 * * *", state.NextRunAt, read))
+	if !planned.NextRunAt.IsUnknown() || !planned.NextRuns.IsUnknown() {
+		t.Errorf("planned next_run_at = %s, next_runs = %s, want both unknown", planned.NextRunAt, planned.NextRuns)
+	}
+}
@@ -0,0 +1,145 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type clusterSecretsDatasourceModel struct {
+	ClusterID types.String                 `tfsdk:"cluster_id"`
+	Secrets   []clusterSecretMetadataModel `tfsdk:"secrets"`
+}
+
+type clusterSecretMetadataModel struct {
+	ID          types.String `tfsdk:"id"`
+	Key         types.String `tfsdk:"key"`
+	Description types.String `tfsdk:"description"`
+	Policy      types.String `tfsdk:"policy"`
+	CreatedAt   types.String `tfsdk:"created_at"`
+	UpdatedAt   types.String `tfsdk:"updated_at"`
+}
+
+type clusterSecretsDatasource struct {
+	client *Client
+}
+
+func newClusterSecretsDatasource() datasource.DataSource {
+	return &clusterSecretsDatasource{}
+}
+
+func (c *clusterSecretsDatasource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	c.client = req.ProviderData.(*Client)
+}
+
+func (*clusterSecretsDatasource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster_secrets"
+}
+
+func (*clusterSecretsDatasource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Use this data source to list the secrets in a cluster, including any created outside
+			Terraform. Only metadata is returned; secret values are never read.
+
+			The API token must have the read_secrets_details scope.
+		`),
+		Attributes: map[string]schema.Attribute{
+			"cluster_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The UUID of the cluster to list secrets for.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(
+						clusterUUIDRegex,
+						"must be a cluster UUID, for example buildkite_cluster.example.uuid (not the GraphQL id)",
+					),
+				},
+			},
+			"secrets": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The secrets in the cluster, ordered by key.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The UUID of the secret.",
+						},
+						"key": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The key agents use to fetch the secret.",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The description of the secret.",
+						},
+						"policy": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The YAML access policy of the secret.",
+						},
+						"created_at": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The time the secret was created.",
+						},
+						"updated_at": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The time the secret was last updated.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (c *clusterSecretsDatasource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state clusterSecretsDatasourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	clusterID := state.ClusterID.ValueString()
+
+	secrets, err := c.client.ListClusterSecrets(ctx, c.client.organization, clusterID)
+	if err != nil {
+		detail := fmt.Sprintf("Unable to list secrets for cluster %s: %s", clusterID, err.Error())
+		if isAPIStatus(err, http.StatusNotFound) {
+			detail = fmt.Sprintf("Cluster %s was not found in organization %s.", clusterID, c.client.organization)
+		}
+		resp.Diagnostics.AddError("Unable to read cluster secrets", detail)
+		return
+	}
+
+	slices.SortFunc(secrets, func(a, b ClusterSecret) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	// A known empty list rather than null, so configurations can iterate over a cluster without secrets.
+	state.Secrets = make([]clusterSecretMetadataModel, 0, len(secrets))
+	for _, secret := range secrets {
+		state.Secrets = append(state.Secrets, clusterSecretMetadataModel{
+			ID:          types.StringValue(secret.ID),
+			Key:         types.StringValue(secret.Key),
+			Description: types.StringPointerValue(secret.Description),
+			Policy:      types.StringPointerValue(secret.Policy),
+			CreatedAt:   types.StringValue(secret.CreatedAt),
+			UpdatedAt:   types.StringValue(secret.UpdatedAt),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
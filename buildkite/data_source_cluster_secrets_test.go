@@ -0,0 +1,79 @@
+package buildkite
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestUnitBuildkiteClusterSecretsDatasource(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	config := fakeProviderConfig(server) + `
+		resource "buildkite_cluster" "cluster" {
+			name = "cluster"
+		}
+
+		resource "buildkite_cluster_secret" "managed" {
+			cluster_id  = buildkite_cluster.cluster.uuid
+			key         = "MANAGED_TOKEN"
+			value       = "managed"
+			description = "Managed by Terraform"
+		}
+
+		data "buildkite_cluster_secrets" "all" {
+			cluster_id = buildkite_cluster.cluster.uuid
+			depends_on = [buildkite_cluster_secret.managed]
+		}
+	`
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.buildkite_cluster_secrets.all", "secrets.#", "1"),
+					resource.TestCheckResourceAttrPair("data.buildkite_cluster_secrets.all", "secrets.0.id", "buildkite_cluster_secret.managed", "id"),
+					resource.TestCheckResourceAttr("data.buildkite_cluster_secrets.all", "secrets.0.description", "Managed by Terraform"),
+					resource.TestCheckNoResourceAttr("data.buildkite_cluster_secrets.all", "secrets.0.value"),
+				),
+			},
+			{
+				// A secret created outside Terraform shows up, ordered by key.
+				PreConfig: func() {
+					server.Update(func(st *fakebuildkite.State) {
+						cluster := st.Nodes("Cluster", nil)[0]
+						_, uuid := st.NewID("Secret")
+						st.PutObject(fmt.Sprintf("/v2/organizations/test-org/clusters/%s/secrets/%s", cluster["uuid"], uuid), fakebuildkite.Object{
+							"id":         uuid,
+							"key":        "CONSOLE_TOKEN",
+							"policy":     "- pipeline_slug: deploy\n",
+							"created_at": fakebuildkite.Timestamp,
+							"updated_at": fakebuildkite.Timestamp,
+						})
+					})
+				},
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.buildkite_cluster_secrets.all", "secrets.#", "2"),
+					resource.TestCheckResourceAttr("data.buildkite_cluster_secrets.all", "secrets.0.key", "CONSOLE_TOKEN"),
+					resource.TestCheckResourceAttr("data.buildkite_cluster_secrets.all", "secrets.0.policy", "- pipeline_slug: deploy\n"),
+					resource.TestCheckNoResourceAttr("data.buildkite_cluster_secrets.all", "secrets.0.description"),
+					resource.TestCheckResourceAttr("data.buildkite_cluster_secrets.all", "secrets.1.key", "MANAGED_TOKEN"),
+				),
+			},
+			{
+				Config: fakeProviderConfig(server) + `
+					data "buildkite_cluster_secrets" "missing" {
+						cluster_id = "01234567-89ab-cdef-0123-456789abcdef"
+					}
+				`,
+				ExpectError: regexp.MustCompile("Cluster 01234567-89ab-cdef-0123-456789abcdef was not found"),
+			},
+		},
+	})
+}
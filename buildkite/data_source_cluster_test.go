@@ -5,14 +5,13 @@ import (
 	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 func TestAccBuildkiteClusterDatasource(t *testing.T) {
 	t.Run("timeout reading cluster", func(t *testing.T) {
 		t.Skip()
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -37,7 +36,7 @@ func TestAccBuildkiteClusterDatasource(t *testing.T) {
 	})
 
 	t.Run("can find a cluster", func(t *testing.T) {
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
 			Steps: []resource.TestStep{
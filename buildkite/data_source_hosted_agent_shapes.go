@@ -0,0 +1,368 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	hostedAgentOSLinux = "linux"
+	hostedAgentOSMacOS = "macos"
+)
+
+var (
+	// hostedAgentShapeSize reads the size out of a shape description such as
+	// "Linux 2 vCPU x 4 GB Memory".
+	hostedAgentShapeSize = regexp.MustCompile(`(\d+) vCPU x (\d+) GB`)
+	// hostedAgentMacOSVersionNumber reads the version out of a description such as
+	// "macOS Sonoma (14.8.3)".
+	hostedAgentMacOSVersionNumber = regexp.MustCompile(`\(([\d.]+)\)`)
+)
+
+// hostedAgentCatalogue is the set of hosted agent instance shapes and macOS versions Buildkite
+// offers. The API has no catalogue query, so it is read from the values and descriptions of the
+// HostedAgentInstanceShapeName and HostedAgentMacOSVersion enums, which track what is available.
+type hostedAgentCatalogue struct {
+	Shapes        []hostedAgentShape
+	MacOSVersions []hostedAgentMacOSVersion
+}
+
+type hostedAgentShape struct {
+	Name         string
+	OS           string
+	Architecture string
+	VCPU         int64
+	MemoryGB     int64
+	Description  string
+	Deprecated   bool
+}
+
+type hostedAgentMacOSVersion struct {
+	Name        string
+	Version     string
+	Description string
+	Deprecated  bool
+}
+
+type hostedAgentEnumValue struct {
+	Name         string
+	Description  string
+	IsDeprecated bool
+}
+
+// shape returns the named instance shape, if Buildkite offers it.
+func (c *hostedAgentCatalogue) shape(name string) (hostedAgentShape, bool) {
+	for _, shape := range c.Shapes {
+		if shape.Name == name {
+			return shape, true
+		}
+	}
+	return hostedAgentShape{}, false
+}
+
+// macOSVersion returns the named macOS version, if Buildkite offers it.
+func (c *hostedAgentCatalogue) macOSVersion(name string) (hostedAgentMacOSVersion, bool) {
+	for _, version := range c.MacOSVersions {
+		if version.Name == name {
+			return version, true
+		}
+	}
+	return hostedAgentMacOSVersion{}, false
+}
+
+// shapeNames lists the current, non-deprecated shapes for the given OS, or for every OS when it is
+// empty, for error messages.
+func (c *hostedAgentCatalogue) shapeNames(os string) []string {
+	var names []string
+	for _, shape := range c.Shapes {
+		if (os == "" || shape.OS == os) && !shape.Deprecated {
+			names = append(names, shape.Name)
+		}
+	}
+	return names
+}
+
+// macOSVersionNames lists the current, non-deprecated macOS versions, for error messages.
+func (c *hostedAgentCatalogue) macOSVersionNames() []string {
+	var names []string
+	for _, version := range c.MacOSVersions {
+		if !version.Deprecated {
+			names = append(names, version.Name)
+		}
+	}
+	return names
+}
+
+// hostedAgentOS returns the OS of an instance shape from its name, such as macos for
+// MACOS_ARM64_M4_6X28, or an empty string for a name that is not a shape.
+func hostedAgentOS(shape string) string {
+	switch {
+	case strings.HasPrefix(shape, "LINUX_"):
+		return hostedAgentOSLinux
+	case strings.HasPrefix(shape, "MACOS_"):
+		return hostedAgentOSMacOS
+	default:
+		return ""
+	}
+}
+
+func newHostedAgentShape(value hostedAgentEnumValue) hostedAgentShape {
+	shape := hostedAgentShape{
+		Name:         value.Name,
+		OS:           hostedAgentOS(value.Name),
+		Architecture: "arm64",
+		Description:  value.Description,
+		Deprecated:   value.IsDeprecated,
+	}
+	// Every Mac shape is Apple silicon, whether or not ARM64 is in its name.
+	if strings.Contains(value.Name, "_AMD64_") {
+		shape.Architecture = "amd64"
+	}
+	if match := hostedAgentShapeSize.FindStringSubmatch(value.Description); match != nil {
+		shape.VCPU, _ = strconv.ParseInt(match[1], 10, 64)
+		shape.MemoryGB, _ = strconv.ParseInt(match[2], 10, 64)
+	}
+	return shape
+}
+
+func newHostedAgentMacOSVersion(value hostedAgentEnumValue) hostedAgentMacOSVersion {
+	version := hostedAgentMacOSVersion{
+		Name:        value.Name,
+		Description: value.Description,
+		Deprecated:  value.IsDeprecated,
+	}
+	if match := hostedAgentMacOSVersionNumber.FindStringSubmatch(value.Description); match != nil {
+		version.Version = match[1]
+	}
+	return version
+}
+
+// HostedAgentCatalogue returns the hosted agent instance shapes and macOS versions Buildkite
+// offers, fetching them once per client.
+func (client *Client) HostedAgentCatalogue(ctx context.Context) (*hostedAgentCatalogue, error) {
+	client.lookups.hostedAgentCatalogueMu.Lock()
+	defer client.lookups.hostedAgentCatalogueMu.Unlock()
+	if client.lookups.hostedAgentCatalogue != nil {
+		return client.lookups.hostedAgentCatalogue, nil
+	}
+
+	var query struct {
+		InstanceShapes struct {
+			EnumValues []hostedAgentEnumValue `graphql:"enumValues(includeDeprecated: true)"`
+		} `graphql:"instanceShapes: __type(name: \"HostedAgentInstanceShapeName\")"`
+		MacOSVersions struct {
+			EnumValues []hostedAgentEnumValue `graphql:"enumValues(includeDeprecated: true)"`
+		} `graphql:"macosVersions: __type(name: \"HostedAgentMacOSVersion\")"`
+	}
+	if err := client.graphql.Query(ctx, &query, nil); err != nil {
+		return nil, err
+	}
+
+	catalogue := &hostedAgentCatalogue{}
+	for _, value := range query.InstanceShapes.EnumValues {
+		catalogue.Shapes = append(catalogue.Shapes, newHostedAgentShape(value))
+	}
+	for _, value := range query.MacOSVersions.EnumValues {
+		catalogue.MacOSVersions = append(catalogue.MacOSVersions, newHostedAgentMacOSVersion(value))
+	}
+	if len(catalogue.Shapes) == 0 {
+		return nil, fmt.Errorf("the API did not return any hosted agent instance shapes")
+	}
+	client.lookups.hostedAgentCatalogue = catalogue
+
+	return client.lookups.hostedAgentCatalogue, nil
+}
+
+type hostedAgentShapesDatasourceModel struct {
+	OS                types.String                   `tfsdk:"os"`
+	IncludeDeprecated types.Bool                     `tfsdk:"include_deprecated"`
+	Shapes            []hostedAgentShapeModel        `tfsdk:"shapes"`
+	MacOSVersions     []hostedAgentMacOSVersionModel `tfsdk:"macos_versions"`
+}
+
+type hostedAgentShapeModel struct {
+	Name         types.String `tfsdk:"name"`
+	OS           types.String `tfsdk:"os"`
+	Architecture types.String `tfsdk:"architecture"`
+	VCPU         types.Int64  `tfsdk:"vcpu"`
+	Memory       types.Int64  `tfsdk:"memory"`
+	Description  types.String `tfsdk:"description"`
+	Deprecated   types.Bool   `tfsdk:"deprecated"`
+}
+
+type hostedAgentMacOSVersionModel struct {
+	Name        types.String `tfsdk:"name"`
+	Version     types.String `tfsdk:"version"`
+	Description types.String `tfsdk:"description"`
+	Deprecated  types.Bool   `tfsdk:"deprecated"`
+}
+
+type hostedAgentShapesDatasource struct {
+	client *Client
+}
+
+func newHostedAgentShapesDatasource() datasource.DataSource {
+	return &hostedAgentShapesDatasource{}
+}
+
+func (h *hostedAgentShapesDatasource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	h.client = req.ProviderData.(*Client)
+}
+
+func (h *hostedAgentShapesDatasource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_hosted_agent_shapes"
+}
+
+func (h *hostedAgentShapesDatasource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Use this data source to look up the instance shapes and macOS versions available to
+			[Buildkite hosted agents](https://buildkite.com/docs/pipelines/hosted-agents), for use in the
+			` + "`hosted_agents`" + ` block of ` + "`buildkite_cluster_queue`" + `.
+
+			The API does not publish the Xcode versions installed on each macOS version or the Linux
+			images available, so those are not included. See the hosted agents documentation for them.
+		`),
+		Attributes: map[string]schema.Attribute{
+			"os": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Only return shapes for this operating system, either `linux` or `macos`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(hostedAgentOSLinux, hostedAgentOSMacOS),
+				},
+			},
+			"include_deprecated": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to include shapes and macOS versions that are deprecated and can no longer be selected for new queues. Defaults to `false`.",
+			},
+			"shapes": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The available instance shapes.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the shape, as used for `hosted_agents.instance_shape`.",
+						},
+						"os": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The operating system of the shape, either `linux` or `macos`.",
+						},
+						"architecture": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The CPU architecture of the shape, either `amd64` or `arm64`.",
+						},
+						"vcpu": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "The number of virtual CPUs.",
+						},
+						"memory": schema.Int64Attribute{
+							Computed:            true,
+							MarkdownDescription: "The memory in GB.",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Buildkite's description of the shape.",
+						},
+						"deprecated": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the shape is deprecated.",
+						},
+					},
+				},
+			},
+			"macos_versions": schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The macOS versions available to macOS shapes. Empty when `os` is `linux`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the version, as used for `hosted_agents.mac.macos_version`.",
+						},
+						"version": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The macOS version number, such as `14.8.3`.",
+						},
+						"description": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "Buildkite's description of the version.",
+						},
+						"deprecated": schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether the version is deprecated.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (h *hostedAgentShapesDatasource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state hostedAgentShapesDatasourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	catalogue, err := h.client.HostedAgentCatalogue(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read hosted agent shapes",
+			fmt.Sprintf("Unable to read hosted agent shapes: %s", err.Error()),
+		)
+		return
+	}
+
+	os := state.OS.ValueString()
+	includeDeprecated := state.IncludeDeprecated.ValueBool()
+
+	state.Shapes = []hostedAgentShapeModel{}
+	for _, shape := range catalogue.Shapes {
+		if (os != "" && shape.OS != os) || (shape.Deprecated && !includeDeprecated) {
+			continue
+		}
+		state.Shapes = append(state.Shapes, hostedAgentShapeModel{
+			Name:         types.StringValue(shape.Name),
+			OS:           types.StringValue(shape.OS),
+			Architecture: types.StringValue(shape.Architecture),
+			VCPU:         types.Int64Value(shape.VCPU),
+			Memory:       types.Int64Value(shape.MemoryGB),
+			Description:  types.StringValue(shape.Description),
+			Deprecated:   types.BoolValue(shape.Deprecated),
+		})
+	}
+
+	state.MacOSVersions = []hostedAgentMacOSVersionModel{}
+	if os != hostedAgentOSLinux {
+		for _, version := range catalogue.MacOSVersions {
+			if version.Deprecated && !includeDeprecated {
+				continue
+			}
+			state.MacOSVersions = append(state.MacOSVersions, hostedAgentMacOSVersionModel{
+				Name:        types.StringValue(version.Name),
+				Version:     types.StringValue(version.Version),
+				Description: types.StringValue(version.Description),
+				Deprecated:  types.BoolValue(version.Deprecated),
+			})
+		}
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
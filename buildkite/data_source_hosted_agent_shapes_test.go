@@ -0,0 +1,163 @@
+package buildkite
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestHostedAgentCatalogue(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+
+	catalogue, err := client.HostedAgentCatalogue(context.Background())
+	if err != nil {
+		t.Fatalf("HostedAgentCatalogue() error = %v", err)
+	}
+
+	for name, want := range map[string]hostedAgentShape{
+		"LINUX_AMD64_2X4":     {OS: "linux", Architecture: "amd64", VCPU: 2, MemoryGB: 4},
+		"LINUX_ARM64_16X64":   {OS: "linux", Architecture: "arm64", VCPU: 16, MemoryGB: 64},
+		"MACOS_M2_4X7":        {OS: "macos", Architecture: "arm64", VCPU: 4, MemoryGB: 7},
+		"MACOS_ARM64_M4_6X28": {OS: "macos", Architecture: "arm64", VCPU: 6, MemoryGB: 28},
+	} {
+		got, ok := catalogue.shape(name)
+		if !ok {
+			t.Errorf("shape %s is missing", name)
+			continue
+		}
+		if got.OS != want.OS || got.Architecture != want.Architecture || got.VCPU != want.VCPU || got.MemoryGB != want.MemoryGB {
+			t.Errorf("shape %s = %+v, want %+v", name, got, want)
+		}
+	}
+
+	if version, ok := catalogue.macOSVersion("SONOMA"); !ok || version.Version != "14.8.3" {
+		t.Errorf("macOS version SONOMA = %+v, want version 14.8.3", version)
+	}
+
+	if _, err := client.HostedAgentCatalogue(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if requests := server.Requests(); len(requests) != 1 {
+		t.Errorf("made %d requests, want the catalogue fetched once", len(requests))
+	}
+}
+
+func TestCheckHostedAgentSelection(t *testing.T) {
+	t.Parallel()
+
+	catalogue := &hostedAgentCatalogue{
+		Shapes: []hostedAgentShape{
+			newHostedAgentShape(hostedAgentEnumValue{Name: "LINUX_AMD64_2X4", Description: "Linux 2 vCPU x 4 GB Memory"}),
+			newHostedAgentShape(hostedAgentEnumValue{Name: "MACOS_M2_4X7", Description: "macOS 4 vCPU x 7 GB Memory", IsDeprecated: true}),
+			newHostedAgentShape(hostedAgentEnumValue{Name: "MACOS_ARM64_M4_6X28", Description: "macOS 6 vCPU x 28 GB Memory"}),
+		},
+		MacOSVersions: []hostedAgentMacOSVersion{
+			newHostedAgentMacOSVersion(hostedAgentEnumValue{Name: "SONOMA", Description: "macOS Sonoma (14.8.3)"}),
+		},
+	}
+
+	testCases := map[string]struct {
+		shape, macosVersion types.String
+		wantError           string
+		wantWarning         string
+	}{
+		"offered": {
+			shape:        types.StringValue("MACOS_ARM64_M4_6X28"),
+			macosVersion: types.StringValue("SONOMA"),
+		},
+		"not checked": {
+			shape:        types.StringNull(),
+			macosVersion: types.StringUnknown(),
+		},
+		"withdrawn shape lists the others for its platform": {
+			shape:        types.StringValue("LINUX_AMD64_64X256"),
+			macosVersion: types.StringNull(),
+			wantError:    "Available shapes are: LINUX_AMD64_2X4",
+		},
+		"deprecated shape": {
+			shape:        types.StringValue("MACOS_M2_4X7"),
+			macosVersion: types.StringNull(),
+			wantWarning:  "The instance shape MACOS_M2_4X7 is deprecated. Available shapes are: MACOS_ARM64_M4_6X28",
+		},
+		"unknown macOS version": {
+			shape:        types.StringNull(),
+			macosVersion: types.StringValue("VENTURA"),
+			wantError:    "Buildkite does not offer the macOS version VENTURA. Available versions are: SONOMA",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			diags := checkHostedAgentSelection(catalogue, tc.shape, tc.macosVersion)
+			var errors, warnings []string
+			for _, d := range diags.Errors() {
+				errors = append(errors, d.Detail())
+			}
+			for _, d := range diags.Warnings() {
+				warnings = append(warnings, d.Detail())
+			}
+
+			if (tc.wantError == "") != (len(errors) == 0) || !strings.Contains(strings.Join(errors, "\n"), tc.wantError) {
+				t.Errorf("errors = %q, want one containing %q", errors, tc.wantError)
+			}
+			if (tc.wantWarning == "") != (len(warnings) == 0) || !strings.Contains(strings.Join(warnings, "\n"), tc.wantWarning) {
+				t.Errorf("warnings = %q, want one containing %q", warnings, tc.wantWarning)
+			}
+		})
+	}
+}
+
+func TestUnitBuildkiteHostedAgentShapesDatasource(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	server.Update(func(state *fakebuildkite.State) {
+		shapes := state.EnumValues("HostedAgentInstanceShapeName")
+		shapes[len(shapes)-1]["isDeprecated"] = true
+	})
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: fakeProviderConfig(server) + `
+					data "buildkite_hosted_agent_shapes" "mac" {
+						os = "macos"
+					}
+				`,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.buildkite_hosted_agent_shapes.mac", "shapes.#", "5"),
+					resource.TestCheckResourceAttr("data.buildkite_hosted_agent_shapes.mac", "shapes.0.name", "MACOS_M2_4X7"),
+					resource.TestCheckResourceAttr("data.buildkite_hosted_agent_shapes.mac", "shapes.0.vcpu", "4"),
+					resource.TestCheckResourceAttr("data.buildkite_hosted_agent_shapes.mac", "shapes.0.memory", "7"),
+					resource.TestCheckResourceAttr("data.buildkite_hosted_agent_shapes.mac", "macos_versions.0.version", "14.8.3"),
+				),
+			},
+			{
+				Config: fakeProviderConfig(server) + `
+					resource "buildkite_cluster" "cluster" {
+						name = "cluster"
+					}
+
+					resource "buildkite_cluster_queue" "queue" {
+						cluster_id = buildkite_cluster.cluster.id
+						key        = "hosted"
+						hosted_agents = {
+							instance_shape = "LINUX_AMD64_64X256"
+							linux = {
+								agent_image_ref = "ubuntu:24.04"
+							}
+						}
+					}
+				`,
+				ExpectError: regexp.MustCompile("Buildkite does not offer the instance shape LINUX_AMD64_64X256"),
+			},
+		},
+	})
+}
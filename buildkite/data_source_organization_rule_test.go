@@ -5,7 +5,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -220,8 +219,8 @@ func TestAccBuildkiteOrganizationRuleDatasource(t *testing.T) {
 
 	for _, action := range ruleActions {
 		t.Run(fmt.Sprintf("loads a pipeline.%s.pipeline organization rule with required attributes by id", action), func(t *testing.T) {
-			randdNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randdNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -250,8 +249,8 @@ func TestAccBuildkiteOrganizationRuleDatasource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("loads a pipeline.%s.pipeline organization rule with all attributes by id", action), func(t *testing.T) {
-			randdNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randdNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -281,8 +280,8 @@ func TestAccBuildkiteOrganizationRuleDatasource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("loads a pipeline.%s.pipeline organization rule with required attributes by uuid", action), func(t *testing.T) {
-			randdNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randdNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -311,8 +310,8 @@ func TestAccBuildkiteOrganizationRuleDatasource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("loads a pipeline.%s.pipeline organization rule with all attributes by uuid", action), func(t *testing.T) {
-			randdNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randdNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
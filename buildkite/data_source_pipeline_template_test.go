@@ -5,7 +5,6 @@ import (
 	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -104,7 +103,7 @@ func TestAccBuildkitePipelineTemplateDatasource(t *testing.T) {
 	}
 
 	t.Run("loads a pipeline template by id", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		var ptr pipelineTemplateResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -133,7 +132,7 @@ func TestAccBuildkitePipelineTemplateDatasource(t *testing.T) {
 	})
 
 	t.Run("loads a pipeline template by name", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		var ptr pipelineTemplateResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -162,8 +161,8 @@ func TestAccBuildkitePipelineTemplateDatasource(t *testing.T) {
 	})
 
 	t.Run("errors when unable to find a pipeline template by name", func(t *testing.T) {
-		randName := acctest.RandString(12)
-		altName := acctest.RandString(12)
+		randName := randString(t, 12)
+		altName := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -178,7 +177,7 @@ func TestAccBuildkitePipelineTemplateDatasource(t *testing.T) {
 	})
 
 	t.Run("invalid attribute combination", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -5,14 +5,13 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 func TestAccBuildkitePipelineDataSource(t *testing.T) {
 	var pipeline getPipelinePipeline
-	pipelineName := acctest.RandString(12)
+	pipelineName := randString(t, 12)
 
 	loadPipeline := func(pipeline *getPipelinePipeline) resource.TestCheckFunc {
 		return func(s *terraform.State) error {
@@ -0,0 +1,180 @@
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+type portalInvocationDatasourceModel struct {
+	Slug      types.String `tfsdk:"slug"`
+	Token     types.String `tfsdk:"token"`
+	Variables types.String `tfsdk:"variables"`
+	Result    types.String `tfsdk:"result"`
+}
+
+type portalInvocationDatasource struct {
+	client *Client
+}
+
+func newPortalInvocationDatasource() datasource.DataSource {
+	return &portalInvocationDatasource{}
+}
+
+func (p *portalInvocationDatasource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	p.client = req.ProviderData.(*Client)
+}
+
+func (p *portalInvocationDatasource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_portal_invocation"
+}
+
+func (p *portalInvocationDatasource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Use this data source to invoke a portal and read its result. The portal is called with its own
+			token rather than the provider's API token, so a configuration can consume the data a portal
+			curates, such as a list of queues, without broader access to the organization.
+
+			Portals are invoked at the provider's ` + "`portal_url`" + `. The query runs on every plan, so
+			portals used this way should be cheap to run.
+		`),
+		Attributes: map[string]schema.Attribute{
+			"slug": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The slug of the portal to invoke.",
+			},
+			"token": schema.StringAttribute{
+				Required:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The token to invoke the portal with, for example `buildkite_portal.example.token`.",
+			},
+			"variables": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "A JSON object of the variables to pass to the portal's query, usually built with `jsonencode`.",
+			},
+			"result": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The `data` the portal's query returned, as JSON. Use `jsondecode` to read it.",
+			},
+		},
+	}
+}
+
+func (p *portalInvocationDatasource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state portalInvocationDatasourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var variables map[string]interface{}
+	if !state.Variables.IsNull() {
+		if err := json.Unmarshal([]byte(state.Variables.ValueString()), &variables); err != nil || variables == nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("variables"),
+				"Invalid portal variables",
+				"variables must be a JSON object, for example jsonencode({ pipeline = \"my-pipeline\" }).",
+			)
+			return
+		}
+	}
+
+	result, err := p.client.invokePortal(ctx, state.Slug.ValueString(), state.Token.ValueString(), variables)
+	if err != nil {
+		detail := fmt.Sprintf("Unable to invoke portal %s: %s", state.Slug.ValueString(), err.Error())
+		switch {
+		case isAPIStatus(err, http.StatusUnauthorized):
+			detail = fmt.Sprintf("The token was rejected by portal %s. If the portal's token was rotated, pass the new one.", state.Slug.ValueString())
+		case isAPIStatus(err, http.StatusNotFound):
+			detail = fmt.Sprintf("Could not find portal with slug %q in organization %s.", state.Slug.ValueString(), p.client.organization)
+		}
+		resp.Diagnostics.AddError("Unable to invoke portal", detail)
+		return
+	}
+
+	state.Result = types.StringValue(string(result))
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// portalInvocationResponse is what a portal returns: the GraphQL response of its query.
+type portalInvocationResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// invokePortal runs a portal with the given token and returns the data its query produced. Portals
+// are served from their own host and authenticate with the portal token, so this goes through
+// portalHTTP rather than makeRequest.
+func (client *Client) invokePortal(ctx context.Context, slug, token string, variables map[string]interface{}) (json.RawMessage, error) {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		readTimeout, diags := client.timeouts.Read(ctx, DefaultTimeout)
+		if !diags.HasError() {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, readTimeout)
+			defer cancel()
+		}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{"variables": variables})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	requestURL := fmt.Sprintf("%s/organizations/%s/portals/%s", strings.TrimSuffix(client.portalURL, "/"), client.organization, url.PathEscape(slug))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.portalHTTP.Do(req)
+	if err != nil {
+		return nil, &apiError{Method: http.MethodPost, URL: requestURL, Err: requestCause(err)}
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			tflog.Warn(ctx, "Failed to close response body", map[string]interface{}{"error": closeErr.Error()})
+		}
+	}()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxCapturedBodyBytes))
+		return nil, &apiError{Method: http.MethodPost, URL: requestURL, StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var result portalInvocationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		messages := make([]string, 0, len(result.Errors))
+		for _, queryErr := range result.Errors {
+			messages = append(messages, queryErr.Message)
+		}
+		return nil, fmt.Errorf("the portal's query failed: %s", strings.Join(messages, "; "))
+	}
+
+	return result.Data, nil
+}
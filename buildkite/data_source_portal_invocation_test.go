@@ -0,0 +1,86 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestInvokePortal(t *testing.T) {
+	t.Parallel()
+
+	server := fakebuildkite.New(t, "test-org")
+	server.Update(func(st *fakebuildkite.State) {
+		st.PutObject("/v2/organizations/test-org/portals/queues", fakebuildkite.Object{"slug": "queues", "token": "portal-token"})
+	})
+	server.HandleREST(http.MethodPost, "/organizations/{org}/portals/broken", func(*fakebuildkite.State, *fakebuildkite.RESTRequest) (int, interface{}) {
+		return http.StatusOK, fakebuildkite.Object{"data": nil, "errors": []fakebuildkite.Object{{"message": "Field 'nmae' doesn't exist on type 'User'"}}}
+	})
+	client := newFakeClient(server)
+
+	t.Run("passes variables and returns data", func(t *testing.T) {
+		result, err := client.invokePortal(context.Background(), "queues", "portal-token", map[string]interface{}{"cluster": "default"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data map[string]map[string]string
+		if err := json.Unmarshal(result, &data); err != nil || data["variables"]["cluster"] != "default" {
+			t.Errorf("result = %s, want the variables echoed back", result)
+		}
+	})
+
+	t.Run("rejects a token that is not the portal's", func(t *testing.T) {
+		_, err := client.invokePortal(context.Background(), "queues", "test", nil)
+		if !isAPIStatus(err, http.StatusUnauthorized) {
+			t.Errorf("err = %v, want a 401", err)
+		}
+	})
+
+	t.Run("reports query errors", func(t *testing.T) {
+		_, err := client.invokePortal(context.Background(), "broken", "portal-token", nil)
+		if err == nil || err.Error() != "the portal's query failed: Field 'nmae' doesn't exist on type 'User'" {
+			t.Errorf("err = %v", err)
+		}
+	})
+}
+
+func TestUnitBuildkitePortalInvocationDatasource(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	config := func(token string) string {
+		return fakeProviderConfig(server) + `
+			resource "buildkite_portal" "queues" {
+				slug  = "cluster-queues"
+				name  = "Cluster queues"
+				query = "query($cluster: ID!) { organization(slug: \"test-org\") { cluster(id: $cluster) { name } } }"
+			}
+
+			data "buildkite_portal_invocation" "queues" {
+				slug      = buildkite_portal.queues.slug
+				token     = ` + token + `
+				variables = jsonencode({ cluster = "default" })
+			}
+		`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config("buildkite_portal.queues.token"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.buildkite_portal_invocation.queues", "result", `{"variables":{"cluster":"default"}}`),
+				),
+			},
+			{
+				Config:      config(`"not-the-token"`),
+				ExpectError: regexp.MustCompile(`The token was rejected by portal cluster-queues`),
+			},
+		},
+	})
+}
@@ -4,12 +4,11 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 func TestAccBuildkitePortalDataSource(t *testing.T) {
-	randName := acctest.RandString(10)
+	randName := randString(t, 10)
 
 	config := func(name string) string {
 		return fmt.Sprintf(`
@@ -4,13 +4,12 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
 func TestAccBuildkitePortalsDataSource(t *testing.T) {
-	randName1 := acctest.RandString(10)
-	randName2 := acctest.RandString(10)
+	randName1 := randString(t, 10)
+	randName2 := randString(t, 10)
 
 	config := func(name1, name2 string) string {
 		return fmt.Sprintf(`
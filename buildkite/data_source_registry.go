@@ -2,16 +2,13 @@ package buildkite
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 )
 
 // Ensure provider defined types fully satisfy framework interfaces
@@ -123,78 +120,41 @@ func (d *registryDatasource) Read(ctx context.Context, req datasource.ReadReques
 		return
 	}
 
-	timeoutDuration, diags := d.client.timeouts.Read(ctx, DefaultTimeout)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	var dataFound bool
-
-	err := retry.RetryContext(ctx, timeoutDuration, func() *retry.RetryError {
-		slug := state.Slug.ValueString()
-		url := fmt.Sprintf("%s/v2/packages/organizations/%s/registries/%s", d.client.restURL, d.client.organization, slug)
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error creating HTTP request: %w", err))
-		}
-		req.Header.Set("Accept", "application/json")
-
-		httpResp, err := d.client.http.Do(req)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error making HTTP request to %s: %w", url, err))
-		}
-		defer httpResp.Body.Close()
-
-		if httpResp.StatusCode == http.StatusNotFound {
-			resp.Diagnostics.AddWarning("Registry not found", fmt.Sprintf("No registry found with slug '%s'", slug))
-			resp.State.RemoveResource(ctx)
-			dataFound = false
-			return nil
-		}
-
-		if httpResp.StatusCode >= 400 {
-			bodyBytes, _ := io.ReadAll(httpResp.Body)
-			return retry.NonRetryableError(fmt.Errorf("error fetching registry (status %d from %s): %s", httpResp.StatusCode, url, string(bodyBytes)))
-		}
-
-		var result registryAPIResponse
-		if err := json.NewDecoder(httpResp.Body).Decode(&result); err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error decoding registry response body from %s: %w", url, err))
-		}
+	slug := state.Slug.ValueString()
 
-		if result.GraphQLID == "" {
-			resp.Diagnostics.AddWarning("Registry data incomplete", fmt.Sprintf("Registry found with slug '%s' but GraphQL ID is missing from response", slug))
-			resp.State.RemoveResource(ctx)
-			dataFound = false
-			return nil
+	var result registryAPIResponse
+	err := d.client.makeRequest(ctx, http.MethodGet, registryPath(d.client.organization, slug), nil, &result)
+	if err != nil {
+		if isAPIStatus(err, http.StatusNotFound) {
+			resp.Diagnostics.AddError(
+				"Registry not found",
+				fmt.Sprintf("Could not find registry with slug \"%s\"", slug),
+			)
+			return
 		}
+		resp.Diagnostics.AddError("Failed to query Registry", fmt.Sprintf("Failed to query Registry with slug '%s': %s", slug, err.Error()))
+		return
+	}
 
-		dataFound = true
-		state.ID = types.StringValue(result.GraphQLID)
-		state.UUID = types.StringValue(result.ID)
-		state.Name = types.StringValue(result.Name)
-		state.Slug = types.StringValue(result.Slug)
-		state.Ecosystem = types.StringValue(result.Ecosystem)
+	if result.GraphQLID == "" {
+		resp.Diagnostics.AddError("Registry data incomplete", fmt.Sprintf("Registry found with slug '%s' but GraphQL ID is missing from response", slug))
+		return
+	}
 
-		state.Description = optionalStringValue(result.Description)
-		state.Emoji = optionalStringValue(result.Emoji)
-		state.Color = optionalStringValue(result.Color)
-		state.OIDCPolicy = optionalStringValue(result.OIDCPolicy)
+	state.ID = types.StringValue(result.GraphQLID)
+	state.UUID = types.StringValue(result.ID)
+	state.Name = types.StringValue(result.Name)
+	state.Slug = types.StringValue(result.Slug)
+	state.Ecosystem = types.StringValue(result.Ecosystem)
 
-		state.Public = types.BoolValue(result.Public)
-		state.RegistryType = types.StringValue(result.RegistryType)
-		state.TeamIDs = handleTeamIDs(result.TeamIDs, state.TeamIDs)
+	state.Description = optionalStringValue(result.Description)
+	state.Emoji = optionalStringValue(result.Emoji)
+	state.Color = optionalStringValue(result.Color)
+	state.OIDCPolicy = optionalStringValue(result.OIDCPolicy)
 
-		return nil
-	})
-	if err != nil {
-		resp.Diagnostics.AddError("Failed to query Registry", fmt.Sprintf("Failed to query Registry with slug '%s' after multiple attempts: %s", state.Slug.ValueString(), err.Error()))
-		return
-	}
+	state.Public = types.BoolValue(result.Public)
+	state.RegistryType = types.StringValue(result.RegistryType)
+	state.TeamIDs = handleTeamIDs(result.TeamIDs, state.TeamIDs)
 
-	if dataFound {
-		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
-	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
@@ -0,0 +1,203 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+type testSuitesDatasourceModel struct {
+	Search     types.String      `tfsdk:"search"`
+	TestSuites []testSuitesModel `tfsdk:"test_suites"`
+}
+
+type testSuitesModel struct {
+	ID              types.String          `tfsdk:"id"`
+	UUID            types.String          `tfsdk:"uuid"`
+	Name            types.String          `tfsdk:"name"`
+	Slug            types.String          `tfsdk:"slug"`
+	DefaultBranch   types.String          `tfsdk:"default_branch"`
+	ApplicationName types.String          `tfsdk:"application_name"`
+	Teams           []testSuitesTeamModel `tfsdk:"teams"`
+}
+
+type testSuitesTeamModel struct {
+	TeamID      types.String `tfsdk:"team_id"`
+	TeamSlug    types.String `tfsdk:"team_slug"`
+	AccessLevel types.String `tfsdk:"access_level"`
+}
+
+type testSuitesDatasource struct {
+	client *Client
+}
+
+func newTestSuitesDatasource() datasource.DataSource {
+	return &testSuitesDatasource{}
+}
+
+func (t *testSuitesDatasource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	t.client = req.ProviderData.(*Client)
+}
+
+func (t *testSuitesDatasource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_test_suites"
+}
+
+func (t *testSuitesDatasource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Use this data source to retrieve the test suites of an organization, along with the teams that own them. You can
+			find out more about test suites in the Buildkite [documentation](https://buildkite.com/docs/test-engine).
+		`),
+		Attributes: map[string]schema.Attribute{
+			"search": schema.StringAttribute{
+				MarkdownDescription: "Only return test suites with names containing this value, case insensitively.",
+				Optional:            true,
+			},
+			"test_suites": schema.ListNestedAttribute{
+				MarkdownDescription: "The test suites of the organization, ordered by name.",
+				Computed:            true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							MarkdownDescription: "The GraphQL ID of the test suite.",
+							Computed:            true,
+						},
+						"uuid": schema.StringAttribute{
+							MarkdownDescription: "The UUID of the test suite.",
+							Computed:            true,
+						},
+						"name": schema.StringAttribute{
+							MarkdownDescription: "The name of the test suite.",
+							Computed:            true,
+						},
+						"slug": schema.StringAttribute{
+							MarkdownDescription: "The slug of the test suite.",
+							Computed:            true,
+						},
+						"default_branch": schema.StringAttribute{
+							MarkdownDescription: "The default branch for the repository this test suite is for.",
+							Computed:            true,
+						},
+						"application_name": schema.StringAttribute{
+							MarkdownDescription: "The name of the application this test suite is for.",
+							Computed:            true,
+						},
+						"teams": schema.ListNestedAttribute{
+							MarkdownDescription: "The teams the test suite is assigned to, ordered by name.",
+							Computed:            true,
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"team_id": schema.StringAttribute{
+										MarkdownDescription: "The GraphQL ID of the team.",
+										Computed:            true,
+									},
+									"team_slug": schema.StringAttribute{
+										MarkdownDescription: "The slug of the team.",
+										Computed:            true,
+									},
+									"access_level": schema.StringAttribute{
+										MarkdownDescription: "The access level the team has to the test suite, either `MANAGE_AND_READ` or `READ_ONLY`.",
+										Computed:            true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (t *testSuitesDatasource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state testSuitesDatasourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.TestSuites = []testSuitesModel{}
+
+	var cursor *string
+	for {
+		res, err := GetOrganizationTestSuites(ctx, t.client.genqlient, t.client.organization, cursor, state.Search.ValueStringPointer())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to get organization test suites",
+				fmt.Sprintf("Error getting organization test suites: %s", err.Error()),
+			)
+			return
+		}
+
+		for _, suite := range res.Organization.Suites.Edges {
+			teams, err := t.testSuiteTeams(ctx, suite.Node.Id, suite.Node.Teams.OrganizationTestSuiteTeams)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Unable to get test suite teams",
+					fmt.Sprintf("Error getting the teams of test suite %s: %s", suite.Node.Slug, err.Error()),
+				)
+				return
+			}
+			updateTestSuitesDatasourceState(&state, suite, teams)
+		}
+
+		if !res.Organization.Suites.PageInfo.HasNextPage {
+			break
+		}
+
+		cursor = &res.Organization.Suites.PageInfo.EndCursor
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// testSuiteTeams returns every team of a suite, fetching the pages after the first one returned with
+// the suite.
+func (t *testSuitesDatasource) testSuiteTeams(ctx context.Context, suiteID string, page OrganizationTestSuiteTeams) ([]OrganizationTestSuiteTeamsEdgesTeamSuiteEdge, error) {
+	edges := page.Edges
+	for page.PageInfo.HasNextPage {
+		res, err := GetOrganizationTestSuiteTeams(ctx, t.client.genqlient, suiteID, page.PageInfo.EndCursor)
+		if err != nil {
+			return nil, err
+		}
+		suite, ok := res.Suite.(*GetOrganizationTestSuiteTeamsSuite)
+		if !ok {
+			return nil, fmt.Errorf("test suite %s not found", suiteID)
+		}
+		page = suite.Teams.OrganizationTestSuiteTeams
+		edges = append(edges, page.Edges...)
+	}
+
+	return edges, nil
+}
+
+func updateTestSuitesDatasourceState(state *testSuitesDatasourceModel, data GetOrganizationTestSuitesOrganizationSuitesSuiteConnectionEdgesSuiteEdge, teams []OrganizationTestSuiteTeamsEdgesTeamSuiteEdge) {
+	suiteState := testSuitesModel{
+		ID:              types.StringValue(data.Node.Id),
+		UUID:            types.StringValue(data.Node.Uuid),
+		Name:            types.StringValue(data.Node.Name),
+		Slug:            types.StringValue(data.Node.Slug),
+		DefaultBranch:   types.StringPointerValue(data.Node.DefaultBranch),
+		ApplicationName: types.StringPointerValue(data.Node.ApplicationName),
+		Teams:           []testSuitesTeamModel{},
+	}
+
+	for _, team := range teams {
+		suiteState.Teams = append(suiteState.Teams, testSuitesTeamModel{
+			TeamID:      types.StringValue(team.Node.Team.Id),
+			TeamSlug:    types.StringValue(team.Node.Team.Slug),
+			AccessLevel: types.StringValue(string(team.Node.AccessLevel)),
+		})
+	}
+
+	state.TestSuites = append(state.TestSuites, suiteState)
+}
@@ -0,0 +1,167 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestTestSuitesDatasource(t *testing.T) {
+	t.Parallel()
+
+	server := fakebuildkite.New(t, "test-org")
+	suites := []fakebuildkite.Object{
+		{"id": "U3VpdGUtLS0x", "uuid": "suite-1", "name": "api", "slug": "api", "defaultBranch": "main", "applicationName": "API", "teams": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "1", "hasNextPage": true},
+			"edges": []fakebuildkite.Object{
+				{"node": fakebuildkite.Object{"accessLevel": "MANAGE_AND_READ", "team": fakebuildkite.Object{"id": "VGVhbS0tLTE=", "slug": "backend"}}},
+			},
+		}},
+		{"id": "U3VpdGUtLS0y", "uuid": "suite-2", "name": "api-e2e", "slug": "api-e2e", "defaultBranch": nil, "applicationName": nil, "teams": fakebuildkite.Object{"edges": []fakebuildkite.Object{}}},
+		{"id": "U3VpdGUtLS0z", "uuid": "suite-3", "name": "web", "slug": "web", "defaultBranch": "main", "applicationName": nil, "teams": fakebuildkite.Object{"edges": []fakebuildkite.Object{}}},
+	}
+	// The api suite has more teams than fit on the page returned with it.
+	server.HandleGraphQL("GetOrganizationTestSuiteTeams", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		if variables["id"] != "U3VpdGUtLS0x" || variables["cursor"] != "1" {
+			return nil, fmt.Errorf("unexpected teams page %v", variables)
+		}
+		return fakebuildkite.Object{"suite": fakebuildkite.Object{"__typename": "Suite", "teams": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "2", "hasNextPage": false},
+			"edges": []fakebuildkite.Object{
+				{"node": fakebuildkite.Object{"accessLevel": "READ_ONLY", "team": fakebuildkite.Object{"id": "VGVhbS0tLTI=", "slug": "frontend"}}},
+			},
+		}}}, nil
+	})
+	server.HandleGraphQL("GetOrganizationTestSuites", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		matching := suites
+		if search, _ := variables["search"].(string); search != "" {
+			matching = nil
+			for _, suite := range suites {
+				if strings.Contains(suite["name"].(string), search) {
+					matching = append(matching, suite)
+				}
+			}
+		}
+
+		start := 0
+		if cursor, _ := variables["cursor"].(string); cursor != "" {
+			fmt.Sscan(cursor, &start)
+		}
+		end := min(start+2, len(matching))
+		edges := []fakebuildkite.Object{}
+		for _, suite := range matching[start:end] {
+			edges = append(edges, fakebuildkite.Object{"node": suite})
+		}
+
+		return fakebuildkite.Object{"organization": fakebuildkite.Object{"suites": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": fmt.Sprint(end), "hasNextPage": end < len(matching)},
+			"edges":    edges,
+		}}}, nil
+	})
+
+	ctx := context.Background()
+	testSuites := &testSuitesDatasource{client: newFakeClient(server)}
+	var schemaResp datasource.SchemaResponse
+	testSuites.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+	suitesType := objectType.(tftypes.Object).AttributeTypes["test_suites"]
+
+	read := func(search interface{}) testSuitesDatasourceModel {
+		t.Helper()
+
+		req := datasource.ReadRequest{Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw: tftypes.NewValue(objectType, map[string]tftypes.Value{
+				"search":      tftypes.NewValue(tftypes.String, search),
+				"test_suites": tftypes.NewValue(suitesType, nil),
+			}),
+		}}
+		resp := &datasource.ReadResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+		testSuites.Read(ctx, req, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatal(resp.Diagnostics)
+		}
+
+		var state testSuitesDatasourceModel
+		if diags := resp.State.Get(ctx, &state); diags.HasError() {
+			t.Fatal(diags)
+		}
+		return state
+	}
+
+	state := read(nil)
+	if len(state.TestSuites) != 3 {
+		t.Fatalf("got %d test suites, want all 3 across both pages", len(state.TestSuites))
+	}
+	api := state.TestSuites[0]
+	if api.Slug.ValueString() != "api" || api.ApplicationName.ValueString() != "API" || len(api.Teams) != 2 {
+		t.Errorf("first suite = %+v, want api with both pages of teams", api)
+	}
+	if team := api.Teams[1]; team.TeamSlug.ValueString() != "frontend" || team.AccessLevel.ValueString() != "READ_ONLY" {
+		t.Errorf("api second team = %+v, want frontend from the second page", team)
+	}
+	if team := api.Teams[0]; team.TeamSlug.ValueString() != "backend" || team.AccessLevel.ValueString() != "MANAGE_AND_READ" {
+		t.Errorf("api team = %+v, want backend with MANAGE_AND_READ", team)
+	}
+	if e2e := state.TestSuites[1]; !e2e.DefaultBranch.IsNull() || e2e.Teams == nil {
+		t.Errorf("second suite = %+v, want a null default branch and no teams", e2e)
+	}
+
+	if state := read("web"); len(state.TestSuites) != 1 || state.TestSuites[0].Slug.ValueString() != "web" {
+		t.Errorf("search for web = %+v, want only the web suite", state.TestSuites)
+	}
+	if state := read("ios"); state.TestSuites == nil || len(state.TestSuites) != 0 {
+		t.Errorf("search for ios = %+v, want an empty list", state.TestSuites)
+	}
+}
+
+func TestAccBuildkiteTestSuitesDatasource(t *testing.T) {
+	t.Run("test suites data source lists suites and their teams", func(t *testing.T) {
+		suiteName := randString(t, 12)
+		config := fmt.Sprintf(`
+		resource "buildkite_team" "acc_tests_team" {
+			name = "%s-team"
+			privacy = "VISIBLE"
+			default_team = false
+			default_member_role = "MEMBER"
+		}
+
+		resource "buildkite_test_suite" "acc_tests" {
+			name = "%s"
+			default_branch = "main"
+			application_name = "My App"
+			team_owner_id = buildkite_team.acc_tests_team.id
+		}
+
+		data "buildkite_test_suites" "suites" {
+			depends_on = [buildkite_test_suite.acc_tests]
+			search = "%s"
+		}
+		`, suiteName, suiteName, suiteName)
+
+		resource.ParallelTest(t, resource.TestCase{
+			PreCheck:                 func() { testAccPreCheck(t) },
+			ProtoV6ProviderFactories: protoV6ProviderFactories(),
+			Steps: []resource.TestStep{
+				{
+					Config: config,
+					Check: resource.ComposeAggregateTestCheckFunc(
+						resource.TestCheckResourceAttr("data.buildkite_test_suites.suites", "test_suites.#", "1"),
+						resource.TestCheckResourceAttrPair("data.buildkite_test_suites.suites", "test_suites.0.id", "buildkite_test_suite.acc_tests", "id"),
+						resource.TestCheckResourceAttr("data.buildkite_test_suites.suites", "test_suites.0.default_branch", "main"),
+						resource.TestCheckResourceAttr("data.buildkite_test_suites.suites", "test_suites.0.application_name", "My App"),
+						resource.TestCheckResourceAttrPair("data.buildkite_test_suites.suites", "test_suites.0.teams.0.team_id", "buildkite_team.acc_tests_team", "id"),
+						resource.TestCheckResourceAttr("data.buildkite_test_suites.suites", "test_suites.0.teams.0.access_level", "MANAGE_AND_READ"),
+					),
+				},
+			},
+		})
+	})
+}
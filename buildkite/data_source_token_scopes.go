@@ -0,0 +1,270 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// graphqlScope is the scope that grants a token access to the GraphQL API at all. The REST API
+// reports it alongside the REST scopes.
+const graphqlScope = "graphql"
+
+// resourceTokenRequirements lists, for each resource type, the token scopes its CRUD operations
+// need and the organization permissions the token's user needs to create it. Resources that read
+// and write through GraphQL alone only need graphqlScope; the REST scopes are those of the
+// endpoints the resource calls through makeRequest.
+var resourceTokenRequirements = map[string]struct {
+	scopes      []string
+	permissions []string
+}{
+	"buildkite_agent_token":           {scopes: []string{graphqlScope}, permissions: []string{"agent_token_create"}},
+	"buildkite_cluster":               {scopes: []string{graphqlScope}},
+	"buildkite_cluster_agent_token":   {scopes: []string{graphqlScope}, permissions: []string{"agent_token_create"}},
+	"buildkite_cluster_default_queue": {scopes: []string{graphqlScope}},
+	"buildkite_cluster_maintainer":    {scopes: []string{"read_clusters", "write_clusters"}},
+	"buildkite_cluster_queue":         {scopes: []string{graphqlScope, "read_clusters", "write_clusters"}},
+	"buildkite_cluster_secret":        {scopes: []string{"read_secrets_details", "write_secrets"}},
+	"buildkite_cluster_secrets":       {scopes: []string{"read_secrets_details", "write_secrets"}},
+	"buildkite_notification_service":  {scopes: []string{"read_notification_services", "write_notification_services"}, permissions: []string{"notification_service_update"}},
+	"buildkite_organization":          {scopes: []string{graphqlScope}, permissions: []string{"organization_update"}},
+	"buildkite_organization_banner":   {scopes: []string{graphqlScope}, permissions: []string{"organization_update"}},
+	"buildkite_organization_rule":     {scopes: []string{graphqlScope}, permissions: []string{"organization_update"}},
+	"buildkite_pipeline":              {scopes: []string{graphqlScope, "read_pipelines", "write_pipelines"}, permissions: []string{"pipeline_create"}},
+	"buildkite_pipeline_schedule":     {scopes: []string{graphqlScope}},
+	"buildkite_pipeline_team":         {scopes: []string{graphqlScope}},
+	"buildkite_pipeline_template":     {scopes: []string{graphqlScope}},
+	"buildkite_pipeline_webhook":      {scopes: []string{graphqlScope}},
+	"buildkite_portal":                {scopes: []string{"read_portals", "write_portals"}},
+	"buildkite_registry":              {scopes: []string{"read_registries", "write_registries", "delete_registries"}},
+	"buildkite_team":                  {scopes: []string{graphqlScope}, permissions: []string{"team_create"}},
+	"buildkite_team_member":           {scopes: []string{graphqlScope}, permissions: []string{"team_admin"}},
+	"buildkite_test_suite":            {scopes: []string{"read_suites", "write_suites"}},
+	"buildkite_test_suite_team":       {scopes: []string{graphqlScope}},
+}
+
+type accessTokenResponse struct {
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Scopes      []string `json:"scopes"`
+}
+
+type tokenScopesDatasource struct {
+	client *Client
+}
+
+type tokenScopesDatasourceModel struct {
+	ResourceTypes           types.List   `tfsdk:"resource_types"`
+	FailOnMissing           types.Bool   `tfsdk:"fail_on_missing"`
+	UUID                    types.String `tfsdk:"uuid"`
+	Description             types.String `tfsdk:"description"`
+	Scopes                  types.List   `tfsdk:"scopes"`
+	OrganizationPermissions types.Map    `tfsdk:"organization_permissions"`
+	Missing                 types.Map    `tfsdk:"missing"`
+}
+
+func newTokenScopesDatasource() datasource.DataSource {
+	return &tokenScopesDatasource{}
+}
+
+func (t *tokenScopesDatasource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	t.client = req.ProviderData.(*Client)
+}
+
+func (*tokenScopesDatasource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_token_scopes"
+}
+
+func (t *tokenScopesDatasource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state tokenScopesDatasourceModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var token accessTokenResponse
+	if err := t.client.makeRequest(ctx, http.MethodGet, "/v2/access-token", nil, &token); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to read API token",
+			fmt.Sprintf("Unable to read the API token's scopes: %s", err.Error()),
+		)
+		return
+	}
+	sort.Strings(token.Scopes)
+
+	// Without the graphql scope the permissions query fails outright, and the missing scope is the
+	// more useful thing to report, so the permissions are simply left empty.
+	permissions := map[string]bool{}
+	if slices.Contains(token.Scopes, graphqlScope) {
+		response, err := getOrganizationPermissions(ctx, t.client.genqlient, t.client.organization)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to read organization permissions",
+				fmt.Sprintf("Unable to read the API token's organization permissions: %s", err.Error()),
+			)
+			return
+		}
+		p := response.Organization.Permissions
+		permissions = map[string]bool{
+			"agent_token_create":          p.AgentTokenCreate.Allowed,
+			"notification_service_update": p.NotificationServiceUpdate.Allowed,
+			"organization_member_update":  p.OrganizationMemberUpdate.Allowed,
+			"organization_update":         p.OrganizationUpdate.Allowed,
+			"pipeline_create":             p.PipelineCreate.Allowed,
+			"team_admin":                  p.TeamAdmin.Allowed,
+			"team_create":                 p.TeamCreate.Allowed,
+		}
+	}
+
+	var resourceTypes []string
+	resp.Diagnostics.Append(state.ResourceTypes.ElementsAs(ctx, &resourceTypes, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	missing := missingTokenRequirements(resourceTypes, token.Scopes, permissions)
+
+	state.UUID = types.StringValue(token.UUID)
+	state.Description = types.StringValue(token.Description)
+	scopes, diags := types.ListValueFrom(ctx, types.StringType, token.Scopes)
+	resp.Diagnostics.Append(diags...)
+	state.Scopes = scopes
+	permissionValues, diags := types.MapValueFrom(ctx, types.BoolType, permissions)
+	resp.Diagnostics.Append(diags...)
+	state.OrganizationPermissions = permissionValues
+	missingValues, diags := types.MapValueFrom(ctx, types.ListType{ElemType: types.StringType}, missing)
+	resp.Diagnostics.Append(diags...)
+	state.Missing = missingValues
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if len(missing) > 0 && (state.FailOnMissing.IsNull() || state.FailOnMissing.ValueBool()) {
+		resp.Diagnostics.AddError(
+			"API token is missing required access",
+			describeMissingTokenRequirements(missing),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// missingTokenRequirements returns, for each resource type that cannot be fully managed, the scopes
+// and permissions it needs that the token lacks. Permissions are prefixed "permission:" so the two
+// kinds stay distinguishable in a single list. Permissions are only judged when the token can read
+// them, since without the graphql scope the missing scope already explains the failure.
+func missingTokenRequirements(resourceTypes, scopes []string, permissions map[string]bool) map[string][]string {
+	missing := map[string][]string{}
+	for _, resourceType := range resourceTypes {
+		requirements := resourceTokenRequirements[resourceType]
+
+		var lacking []string
+		for _, scope := range requirements.scopes {
+			if !slices.Contains(scopes, scope) {
+				lacking = append(lacking, scope)
+			}
+		}
+		if len(permissions) > 0 {
+			for _, permission := range requirements.permissions {
+				if !permissions[permission] {
+					lacking = append(lacking, "permission:"+permission)
+				}
+			}
+		}
+
+		if len(lacking) > 0 {
+			missing[resourceType] = lacking
+		}
+	}
+
+	return missing
+}
+
+func describeMissingTokenRequirements(missing map[string][]string) string {
+	resourceTypes := make([]string, 0, len(missing))
+	for resourceType := range missing {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	var detail strings.Builder
+	detail.WriteString("The API token cannot manage every configured resource type. Nothing has been changed yet.\n\n")
+	for _, resourceType := range resourceTypes {
+		fmt.Fprintf(&detail, "  %s: %s\n", resourceType, strings.Join(missing[resourceType], ", "))
+	}
+	detail.WriteString("\nAdd the missing scopes to the token, or ask an organization administrator to grant the missing permissions to its user.")
+
+	return detail.String()
+}
+
+func (*tokenScopesDatasource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resourceTypes := make([]string, 0, len(resourceTokenRequirements))
+	for resourceType := range resourceTokenRequirements {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Use this data source to check the provider's API token before any change is made. It reads the
+			token's scopes from the [access token](https://buildkite.com/docs/apis/rest-api/access-token) endpoint
+			and the organization permissions of the token's user from GraphQL.
+
+			Data sources are read during plan, so listing the resource types a configuration manages in
+			'resource_types' turns a 403 part way through an apply into a plan-time error naming every missing scope.
+		`),
+		Attributes: map[string]schema.Attribute{
+			"resource_types": schema.ListAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Resource types the configuration manages, for example `buildkite_pipeline`. Each is checked against the scopes and permissions it needs.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(resourceTypes...)),
+				},
+			},
+			"fail_on_missing": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Whether to fail when a resource type in `resource_types` lacks a scope or permission. Defaults to `true`. Set to `false` to inspect `missing` from a `check` block instead.",
+			},
+			"uuid": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The UUID of the API token.",
+			},
+			"description": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The description of the API token.",
+			},
+			"scopes": schema.ListAttribute{
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The scopes granted to the API token, sorted.",
+			},
+			"organization_permissions": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.BoolType,
+				MarkdownDescription: "Organization permissions of the token's user, keyed by permission name. Empty when the token lacks the `graphql` scope.",
+			},
+			"missing": schema.MapAttribute{
+				Computed:            true,
+				ElementType:         types.ListType{ElemType: types.StringType},
+				MarkdownDescription: "For each resource type in `resource_types` that cannot be fully managed, the scopes it needs that the token lacks. Missing organization permissions are listed with a `permission:` prefix.",
+			},
+		},
+	}
+}
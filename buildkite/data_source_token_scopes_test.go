@@ -0,0 +1,137 @@
+package buildkite
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccBuildkiteTokenScopesDatasource(t *testing.T) {
+	config := `
+		provider "buildkite" {
+			timeouts = {
+				create = "10s"
+				read = "10s"
+				update = "10s"
+				delete = "10s"
+			}
+		}
+
+		data "buildkite_token_scopes" "token" {
+			resource_types = ["buildkite_pipeline", "buildkite_team"]
+		}
+	`
+
+	resource.ParallelTest(t, resource.TestCase{
+		PreCheck:                 func() { testAccPreCheck(t) },
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("data.buildkite_token_scopes.token", "uuid"),
+					resource.TestCheckTypeSetElemAttr("data.buildkite_token_scopes.token", "scopes.*", "graphql"),
+					resource.TestCheckResourceAttr("data.buildkite_token_scopes.token", "organization_permissions.pipeline_create", "true"),
+					resource.TestCheckResourceAttr("data.buildkite_token_scopes.token", "missing.%", "0"),
+				),
+			},
+		},
+	})
+}
+
+// Every resource the provider registers must appear in the requirements table, or the data source
+// would silently report nothing missing for it.
+func TestResourceTokenRequirementsCoverEveryResource(t *testing.T) {
+	t.Parallel()
+
+	provider := New("testing")
+	for _, newResource := range provider.Resources(context.Background()) {
+		var metadata frameworkresource.MetadataResponse
+		newResource().Metadata(context.Background(), frameworkresource.MetadataRequest{ProviderTypeName: "buildkite"}, &metadata)
+
+		if _, ok := resourceTokenRequirements[metadata.TypeName]; !ok {
+			t.Errorf("resourceTokenRequirements has no entry for %s", metadata.TypeName)
+		}
+	}
+}
+
+func TestMissingTokenRequirements(t *testing.T) {
+	t.Parallel()
+
+	allowed := map[string]bool{"pipeline_create": true, "team_create": false}
+
+	tests := []struct {
+		name          string
+		resourceTypes []string
+		scopes        []string
+		permissions   map[string]bool
+		want          map[string][]string
+	}{
+		{
+			name:          "nothing requested",
+			resourceTypes: nil,
+			scopes:        nil,
+			want:          map[string][]string{},
+		},
+		{
+			name:          "everything granted",
+			resourceTypes: []string{"buildkite_pipeline"},
+			scopes:        []string{"graphql", "read_pipelines", "write_pipelines"},
+			permissions:   allowed,
+			want:          map[string][]string{},
+		},
+		{
+			name:          "missing REST scope",
+			resourceTypes: []string{"buildkite_pipeline", "buildkite_test_suite"},
+			scopes:        []string{"graphql", "read_pipelines", "read_suites"},
+			permissions:   allowed,
+			want: map[string][]string{
+				"buildkite_pipeline":   {"write_pipelines"},
+				"buildkite_test_suite": {"write_suites"},
+			},
+		},
+		{
+			name:          "missing permission",
+			resourceTypes: []string{"buildkite_team"},
+			scopes:        []string{"graphql"},
+			permissions:   allowed,
+			want:          map[string][]string{"buildkite_team": {"permission:team_create"}},
+		},
+		{
+			name:          "permissions are not judged without graphql",
+			resourceTypes: []string{"buildkite_team"},
+			scopes:        []string{"read_pipelines"},
+			want:          map[string][]string{"buildkite_team": {"graphql"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := missingTokenRequirements(tt.resourceTypes, tt.scopes, tt.permissions)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingTokenRequirements() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDescribeMissingTokenRequirements(t *testing.T) {
+	t.Parallel()
+
+	detail := describeMissingTokenRequirements(map[string][]string{
+		"buildkite_test_suite": {"write_suites"},
+		"buildkite_pipeline":   {"write_pipelines", "permission:pipeline_create"},
+	})
+
+	pipeline := strings.Index(detail, "buildkite_pipeline: write_pipelines, permission:pipeline_create")
+	suite := strings.Index(detail, "buildkite_test_suite: write_suites")
+	if pipeline == -1 || suite == -1 || pipeline > suite {
+		t.Errorf("expected resource types in sorted order with their missing scopes, got:\n%s", detail)
+	}
+}
@@ -0,0 +1,96 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure provider defined types fully satisfy framework interfaces
+var (
+	_ ephemeral.EphemeralResource              = &testSuiteApiTokenEphemeralResource{}
+	_ ephemeral.EphemeralResourceWithConfigure = &testSuiteApiTokenEphemeralResource{}
+)
+
+type testSuiteApiTokenEphemeralModel struct {
+	Slug     types.String `tfsdk:"slug"`
+	ApiToken types.String `tfsdk:"api_token"`
+}
+
+type testSuiteApiTokenEphemeralResource struct {
+	client *Client
+}
+
+func newTestSuiteApiTokenEphemeralResource() ephemeral.EphemeralResource {
+	return &testSuiteApiTokenEphemeralResource{}
+}
+
+func (t *testSuiteApiTokenEphemeralResource) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	t.client = req.ProviderData.(*Client)
+}
+
+func (t *testSuiteApiTokenEphemeralResource) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_test_suite_api_token"
+}
+
+func (t *testSuiteApiTokenEphemeralResource) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Use this ephemeral resource to read a test suite's API token without it being stored in Terraform
+			plan or state artifacts, for example to write it to a secret manager through a write-only attribute.
+			Pair it with ` + "`store_api_token = false`" + ` on the ` + "`buildkite_test_suite`" + ` resource to keep the token
+			out of state entirely. Ephemeral resources require Terraform 1.10 or later.
+		`),
+		Attributes: map[string]schema.Attribute{
+			"slug": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The slug of the test suite.",
+			},
+			"api_token": schema.StringAttribute{
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The API token to use to send test run data to the API.",
+			},
+		},
+	}
+}
+
+func (t *testSuiteApiTokenEphemeralResource) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var data testSuiteApiTokenEphemeralModel
+
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	path := fmt.Sprintf("/v2/analytics/organizations/%s/suites/%s?show_api_token=true", t.client.organization, data.Slug.ValueString())
+
+	var response testSuiteResponse
+	if err := t.client.makeRequest(ctx, http.MethodGet, path, nil, &response); err != nil {
+		if isAPIStatus(err, http.StatusNotFound) {
+			resp.Diagnostics.AddError(
+				"Test suite not found",
+				fmt.Sprintf("Could not find test suite with slug \"%s\"", data.Slug.ValueString()),
+			)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Unable to read test suite API token",
+			fmt.Sprintf("Unable to read test suite API token: %s", err.Error()),
+		)
+		return
+	}
+
+	data.ApiToken = types.StringValue(response.ApiToken)
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &data)...)
+}
@@ -0,0 +1,82 @@
+package buildkite
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func TestTestSuiteApiTokenEphemeralResource(t *testing.T) {
+	t.Parallel()
+
+	server := fakebuildkite.New(t, "test-org")
+	server.HandleREST(http.MethodGet, "/v2/analytics/organizations/{org}/suites/{slug}", func(st *fakebuildkite.State, req *fakebuildkite.RESTRequest) (int, interface{}) {
+		if req.Params["slug"] != "app" {
+			return http.StatusNotFound, fakebuildkite.Object{"message": "Not Found"}
+		}
+		suite := fakebuildkite.Object{"slug": "app", "name": "app"}
+		if req.Query.Get("show_api_token") == "true" {
+			suite["api_token"] = "suite-token"
+		}
+		return http.StatusOK, suite
+	})
+
+	ctx := context.Background()
+	tokens := &testSuiteApiTokenEphemeralResource{client: newFakeClient(server)}
+	var schemaResp ephemeral.SchemaResponse
+	tokens.Schema(ctx, ephemeral.SchemaRequest{}, &schemaResp)
+	objectType := schemaResp.Schema.Type().TerraformType(ctx)
+
+	open := func(slug string) (*ephemeral.OpenResponse, testSuiteApiTokenEphemeralModel) {
+		req := ephemeral.OpenRequest{Config: tfsdk.Config{
+			Schema: schemaResp.Schema,
+			Raw: tftypes.NewValue(objectType, map[string]tftypes.Value{
+				"slug":      tftypes.NewValue(tftypes.String, slug),
+				"api_token": tftypes.NewValue(tftypes.String, nil),
+			}),
+		}}
+		resp := &ephemeral.OpenResponse{Result: tfsdk.EphemeralResultData{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+		tokens.Open(ctx, req, resp)
+
+		var result testSuiteApiTokenEphemeralModel
+		if !resp.Diagnostics.HasError() {
+			resp.Diagnostics.Append(resp.Result.Get(ctx, &result)...)
+		}
+		return resp, result
+	}
+
+	resp, result := open("app")
+	if resp.Diagnostics.HasError() {
+		t.Fatal(resp.Diagnostics)
+	}
+	if !result.ApiToken.Equal(types.StringValue("suite-token")) {
+		t.Errorf("api_token = %v, want the suite's token", result.ApiToken)
+	}
+
+	if resp, _ := open("missing"); !resp.Diagnostics.HasError() || resp.Diagnostics[0].Summary() != "Test suite not found" {
+		t.Errorf("opening a missing suite = %v, want it reported as not found", resp.Diagnostics)
+	}
+}
+
+func TestStoredApiToken(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		store types.Bool
+		want  types.String
+	}{
+		{store: types.BoolValue(true), want: types.StringValue("token")},
+		{store: types.BoolNull(), want: types.StringValue("token")},
+		{store: types.BoolValue(false), want: types.StringNull()},
+	} {
+		if got := storedApiToken(tc.store, "token"); !got.Equal(tc.want) {
+			t.Errorf("storedApiToken(%v) = %v, want %v", tc.store, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+)
+
+// These tests run the generated operations against the fake API, so a handler that answers in a
+// shape the provider cannot decode fails here rather than in a resource test.
+
+func TestFakeAPIPipelines(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+	ctx := context.Background()
+	_, orgID, _ := server.Organization()
+
+	team, err := teamCreate(ctx, client.genqlient, orgID, "Platform", "", "VISIBLE", false, "MEMBER", true, true, false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	teamID := team.TeamCreate.TeamEdge.Node.Id
+	template, err := createPipelineTemplate(ctx, client.genqlient, orgID, "Deploy", "steps: []", nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	created, err := createPipeline(ctx, client.genqlient, PipelineCreateInput{
+		OrganizationId:     orgID,
+		Name:               "Web App",
+		Repository:         PipelineRepositoryInput{Url: "git@github.com:acme/web.git"},
+		Steps:              PipelineStepsInput{Yaml: "steps: []"},
+		PipelineTemplateId: template.PipelineTemplateCreate.PipelineTemplate.Id,
+		Tags:               []PipelineTagInput{{Label: "frontend"}},
+		Teams:              []PipelineTeamAssignmentInput{{Id: teamID, AccessLevel: PipelineAccessLevelsManageBuildAndRead}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pipeline := created.PipelineCreate.Pipeline
+	if pipeline.Slug != "web-app" || pipeline.PipelineTemplate.Id == nil || len(pipeline.Tags) != 1 {
+		t.Errorf("created pipeline = %+v, want slug web-app with the template and tag", pipeline)
+	}
+	if pipeline.Teams.Count != 1 || pipeline.Teams.Edges[0].Node.Team.Id != teamID {
+		t.Errorf("created pipeline teams = %+v, want the default team", pipeline.Teams)
+	}
+
+	read, err := getPipeline(ctx, client.genqlient, "test-org/web-app")
+	if err != nil || read.Pipeline.Id != pipeline.Id {
+		t.Fatalf("getPipeline = %+v, %v, want the created pipeline", read, err)
+	}
+	node, err := getNode(ctx, client.genqlient, pipeline.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := node.Node.(*getNodeNodePipeline); !ok || got.PipelineUuid != pipeline.PipelineUuid {
+		t.Errorf("getNode = %#v, want the pipeline", node.Node)
+	}
+
+	// The REST API renames the pipeline and sets its provider settings, which GraphQL then reports
+	var extraInfo PipelineExtraInfo
+	body := map[string]any{"slug": "web", "provider_settings": map[string]any{"build_tags": true, "trigger_mode": "code"}}
+	if err := client.makeRequest(ctx, http.MethodPatch, "/v2/organizations/test-org/pipelines/web-app", body, &extraInfo); err != nil {
+		t.Fatal(err)
+	}
+	if extraInfo.Slug != "web" || extraInfo.Provider.Settings.BuildTags == nil || !*extraInfo.Provider.Settings.BuildTags {
+		t.Errorf("PATCH pipeline = %+v, want the new slug and provider settings", extraInfo)
+	}
+	settings, err := getPipelineProviderSettings(ctx, client.genqlient, pipeline.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapped := mapProviderSettingsFromGraphQL(settings.Node.(*getPipelineProviderSettingsNodePipeline).Repository.RepositoryProviderSettingsFields); mapped == nil || mapped.TriggerMode.ValueString() != "code" {
+		t.Errorf("provider settings = %+v, want trigger_mode code", mapped)
+	}
+
+	// Team access
+	other, err := teamCreate(ctx, client.genqlient, orgID, "Apps", "", "VISIBLE", false, "MEMBER", true, true, false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	access, err := createTeamPipeline(ctx, client.genqlient, other.TeamCreate.TeamEdge.Node.Id, pipeline.Id, PipelineAccessLevelsReadOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	accessID := access.TeamPipelineCreate.TeamPipelineEdge.Node.Id
+	if _, err := updateTeamPipeline(ctx, client.genqlient, accessID, PipelineAccessLevelsBuildAndRead); err != nil {
+		t.Fatal(err)
+	}
+	teams, err := getPipelineTeams(ctx, client.genqlient, "test-org/web", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edges := teams.Pipeline.Teams.Edges; len(edges) != 2 || edges[0].Node.AccessLevel != PipelineAccessLevelsBuildAndRead {
+		t.Errorf("pipeline teams = %+v, want Apps with BUILD_AND_READ first", edges)
+	}
+	if _, err := deleteTeamPipeline(ctx, client.genqlient, accessID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Schedules
+	label, cronline, env := "Nightly", "@midnight", "FOO=bar\nBAZ=qux"
+	schedule, err := createPipelineSchedule(ctx, client.genqlient, pipeline.Id, &label, &cronline, nil, nil, nil, &env, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduleNode := schedule.PipelineScheduleCreate.PipelineScheduleEdge.Node
+	if len(scheduleNode.Env) != 2 || !scheduleNode.Enabled {
+		t.Errorf("created schedule = %+v, want two env lines and enabled", scheduleNode)
+	}
+	disabled := false
+	if _, err := updatePipelineSchedule(ctx, client.genqlient, PipelineScheduleUpdateInput{Id: scheduleNode.Id, Enabled: &disabled}); err != nil {
+		t.Fatal(err)
+	}
+	bySlug, err := getPipelineScheduleBySlug(ctx, client.genqlient, "test-org/web/"+scheduleNode.Uuid)
+	if err != nil || bySlug.PipelineSchedule.Enabled {
+		t.Errorf("getPipelineScheduleBySlug = %+v, %v, want the disabled schedule", bySlug, err)
+	}
+
+	// Rules between pipelines
+	target, err := createPipeline(ctx, client.genqlient, PipelineCreateInput{OrganizationId: orgID, Name: "API", Repository: PipelineRepositoryInput{Url: "https://gitlab.com/acme/api.git"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := fmt.Sprintf(`{"source_pipeline":%q,"target_pipeline":%q}`, pipeline.PipelineUuid, target.PipelineCreate.Pipeline.PipelineUuid)
+	rule, err := createOrganizationRule(ctx, client.genqlient, orgID, nil, "pipeline.trigger_build.pipeline", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := rule.RuleCreate.Rule; got.Action != RuleActionTriggerBuild || got.Source.(*OrganizationRuleFieldsSourcePipeline).Uuid != pipeline.PipelineUuid {
+		t.Errorf("created rule = %+v, want a trigger_build rule from the pipeline", got)
+	}
+	if _, err := getOrganizationRule(ctx, client.genqlient, rule.RuleCreate.Rule.Uuid); err != nil {
+		t.Fatal(err)
+	}
+	rules, err := GetOrganizationRules(ctx, client.genqlient, "test-org", nil)
+	if err != nil || len(rules.Organization.Rules.Edges) != 1 {
+		t.Errorf("GetOrganizationRules = %+v, %v, want the rule", rules, err)
+	}
+
+	// Webhooks are only created for GitHub repositories
+	if _, err := createPipelineWebhook(ctx, client.genqlient, target.PipelineCreate.Pipeline.Id); err == nil {
+		t.Error("created a webhook for a GitLab repository")
+	}
+	if _, err := createPipelineWebhook(ctx, client.genqlient, pipeline.Id); err != nil {
+		t.Fatal(err)
+	}
+	webhook, err := getPipelineWebhook(ctx, client.genqlient, pipeline.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := extractWebhookFromPipeline(webhook.Node.(*getPipelineWebhookNodePipeline)); err != nil || info == nil {
+		t.Errorf("webhook = %+v, %v, want the created webhook", info, err)
+	}
+
+	listed, err := GetOrganizationPipelines(ctx, client.genqlient, "test-org", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edges := listed.Organization.Pipelines.Edges; len(edges) != 2 || edges[0].Node.Name != "API" || len(edges[1].Node.Schedules.Edges) != 1 {
+		t.Errorf("GetOrganizationPipelines = %+v, want both pipelines by name with the schedule", edges)
+	}
+	templates, err := getPipelineTemplates(ctx, client.genqlient, "test-org", nil)
+	if err != nil || len(templates.Organization.PipelineTemplates.Edges) != 1 {
+		t.Errorf("getPipelineTemplates = %+v, %v, want the template", templates, err)
+	}
+
+	if _, err := deletePipeline(ctx, client.genqlient, pipeline.Id); err != nil {
+		t.Fatal(err)
+	}
+	server.Update(func(st *fakebuildkite.State) {
+		if schedules := st.Nodes("PipelineSchedule", nil); len(schedules) != 0 {
+			t.Errorf("deleting the pipeline left %d schedules behind", len(schedules))
+		}
+	})
+}
+
+func TestFakeAPITeamsAndMembers(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+	ctx := context.Background()
+	_, orgID, _ := server.Organization()
+
+	var user fakebuildkite.Object
+	server.Update(func(st *fakebuildkite.State) {
+		user = st.AddUser("Alice", "alice@example.com")
+	})
+
+	team, err := teamCreate(ctx, client.genqlient, orgID, "Platform", "", "VISIBLE", false, "MEMBER", true, true, false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	member, err := createTeamMember(ctx, client.genqlient, team.TeamCreate.TeamEdge.Node.Id, user["id"].(string), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := updateTeamMember(ctx, client.genqlient, member.TeamMemberCreate.TeamMemberEdge.Node.Id, "MAINTAINER"); err != nil {
+		t.Fatal(err)
+	}
+	members, err := GetTeamMembers(ctx, client.genqlient, "test-org/platform", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edges := members.Team.Members.Edges; len(edges) != 1 || edges[0].Node.Role != "MAINTAINER" {
+		t.Errorf("GetTeamMembers = %+v, want Alice as a maintainer", edges)
+	}
+
+	byEmail, err := GetOrganizationMemberByEmail(ctx, client.genqlient, "test-org", "ALICE@example.com")
+	if err != nil || len(byEmail.Organization.Members.Edges) != 1 {
+		t.Errorf("GetOrganizationMemberByEmail = %+v, %v, want Alice", byEmail, err)
+	}
+	all, err := GetOrganizationMembers(ctx, client.genqlient, "test-org", nil)
+	if err != nil || len(all.Organization.Members.Edges) != 1 {
+		t.Errorf("GetOrganizationMembers = %+v, %v, want Alice", all, err)
+	}
+
+	// Deleting the team removes its members
+	if _, err := teamDelete(ctx, client.genqlient, team.TeamCreate.TeamEdge.Node.Id); err != nil {
+		t.Fatal(err)
+	}
+	node, err := getNode(ctx, client.genqlient, member.TeamMemberCreate.TeamMemberEdge.Node.Id)
+	if err != nil || node.Node != nil {
+		t.Errorf("getNode of a deleted team's member = %+v, %v, want nothing", node, err)
+	}
+}
+
+func TestFakeAPIOrganization(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+	ctx := context.Background()
+	_, orgID, _ := server.Organization()
+
+	description := "CI"
+	token, err := createAgentToken(ctx, client.genqlient, orgID, &description)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uuid := token.AgentTokenCreate.AgentTokenEdge.Node.Uuid
+	if read, err := getAgentToken(ctx, client.genqlient, "test-org/"+uuid); err != nil || read.AgentToken.Uuid != uuid {
+		t.Errorf("getAgentToken = %+v, %v, want the created token", read, err)
+	}
+	if _, err := revokeAgentToken(ctx, client.genqlient, token.AgentTokenCreate.AgentTokenEdge.Node.Id, "done"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, message := range []string{"Maintenance tonight", "Maintenance tomorrow"} {
+		if _, err := upsertBanner(ctx, client.genqlient, orgID, message); err != nil {
+			t.Fatal(err)
+		}
+	}
+	banners, err := getOrganiztionBanner(ctx, client.genqlient, "test-org")
+	if edges := banners.Organization.Banners.Edges; err != nil || len(edges) != 1 || edges[0].Node.Message != "Maintenance tomorrow" {
+		t.Errorf("getOrganiztionBanner = %+v, %v, want the one banner, updated", banners, err)
+	}
+	if _, err := deleteBanner(ctx, client.genqlient, orgID); err != nil {
+		t.Fatal(err)
+	}
+
+	settings, err := client.updateOrganizationAPISettings(ctx, map[string]any{"revoke_inactive_tokens_after_days": 30})
+	if err != nil || settings.RevokeInactiveTokensAfterDays == nil || *settings.RevokeInactiveTokensAfterDays != 30 {
+		t.Errorf("updateOrganizationAPISettings = %+v, %v, want 30 days", settings, err)
+	}
+
+	var registry map[string]any
+	if err := client.makeRequest(ctx, http.MethodPost, "/v2/packages/organizations/test-org/registries", map[string]any{"name": "images", "ecosystem": "container"}, &registry); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := GetRegistryID(ctx, client.genqlient, "test-org/images"); err != nil || id.Registry.Id != registry["graphql_id"] {
+		t.Errorf("GetRegistryID = %+v, %v, want %v", id, err, registry["graphql_id"])
+	}
+}
+
+func TestFakeAPITestSuites(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+	ctx := context.Background()
+	_, orgID, _ := server.Organization()
+
+	team, err := teamCreate(ctx, client.genqlient, orgID, "Platform", "", "VISIBLE", false, "MEMBER", true, true, false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var suite testSuiteResponse
+	payload := map[string]any{"name": "Unit Tests", "default_branch": "main", "show_api_token": true, "team_ids": []string{team.TeamCreate.TeamEdge.Node.Uuid}}
+	if err := client.makeRequest(ctx, http.MethodPost, "/v2/analytics/organizations/test-org/suites", payload, &suite); err != nil {
+		t.Fatal(err)
+	}
+	if suite.Slug != "unit-tests" || suite.ApiToken == "" {
+		t.Errorf("created suite = %+v, want slug unit-tests and an API token", suite)
+	}
+
+	read, err := getTestSuite(ctx, client.genqlient, suite.GraphqlID, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, ok := read.Suite.(*getTestSuiteSuite)
+	if !ok || len(node.Teams.Edges) != 1 || node.Teams.Edges[0].Node.AccessLevel != SuiteAccessLevelsManageAndRead {
+		t.Errorf("getTestSuite = %#v, want the owning team", read.Suite)
+	}
+
+	other, err := teamCreate(ctx, client.genqlient, orgID, "Apps", "", "VISIBLE", false, "MEMBER", true, true, false, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := createTestSuiteTeam(ctx, client.genqlient, other.TeamCreate.TeamEdge.Node.Id, suite.GraphqlID, SuiteAccessLevelsReadOnly); err != nil {
+		t.Fatal(err)
+	}
+	search := "unit"
+	suites, err := GetOrganizationTestSuites(ctx, client.genqlient, "test-org", nil, &search)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edges := suites.Organization.Suites.Edges; len(edges) != 1 || len(edges[0].Node.Teams.Edges) != 2 || edges[0].Node.Teams.Edges[0].Node.Team.Slug != "apps" {
+		t.Errorf("GetOrganizationTestSuites = %+v, want the suite with both teams by name", edges)
+	}
+	suiteTeams, err := GetOrganizationTestSuiteTeams(ctx, client.genqlient, suites.Organization.Suites.Edges[0].Node.Id, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if suite, ok := suiteTeams.Suite.(*GetOrganizationTestSuiteTeamsSuite); !ok || len(suite.Teams.Edges) != 2 {
+		t.Errorf("GetOrganizationTestSuiteTeams = %+v, want both teams", suiteTeams.Suite)
+	}
+}
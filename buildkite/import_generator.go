@@ -0,0 +1,672 @@
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// ImportGeneratorConfig configures GenerateImports. Empty fields fall back to the same environment
+// variables and defaults the provider uses.
+type ImportGeneratorConfig struct {
+	Organization string
+	APIToken     string
+	GraphQLURL   string
+	Version      string
+}
+
+// GenerateImports writes Terraform configuration for the pipelines, pipeline schedules, pipeline
+// templates, clusters, cluster queues, teams, team members and organization rules of an existing
+// organization to w. Each resource gets an import block alongside a resource block matching this
+// provider's schema, and resources refer to one another rather than to hard-coded IDs.
+func GenerateImports(ctx context.Context, config ImportGeneratorConfig, w io.Writer) error {
+	if config.Organization == "" {
+		config.Organization = getenv("BUILDKITE_ORGANIZATION_SLUG")
+	}
+	if config.APIToken == "" {
+		config.APIToken = os.Getenv("BUILDKITE_API_TOKEN")
+	}
+	if config.GraphQLURL == "" {
+		config.GraphQLURL = defaultGraphqlEndpoint
+		if v, ok := os.LookupEnv("BUILDKITE_GRAPHQL_URL"); ok {
+			config.GraphQLURL = v
+		}
+	}
+
+	if config.Organization == "" {
+		return errors.New("an organization is required, set BUILDKITE_ORGANIZATION_SLUG or pass one")
+	}
+	if config.APIToken == "" {
+		return errors.New("an API token is required, set BUILDKITE_API_TOKEN")
+	}
+
+	client := NewClient(&clientConfig{
+		org:        strings.TrimSpace(config.Organization),
+		apiToken:   strings.TrimSpace(config.APIToken),
+		graphqlURL: strings.TrimSpace(config.GraphQLURL),
+		restURL:    defaultRestEndpoint,
+		portalURL:  defaultPortalEndpoint,
+		userAgent:  fmt.Sprintf("terraform-provider-buildkite/%s (generate-imports)", config.Version),
+		maxRetries: DefaultRetryMaxAttempts,
+	})
+
+	file, err := newImportGenerator(client).generate(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(file.Bytes())
+	return err
+}
+
+// importGenerator builds the configuration GenerateImports writes. It remembers the label each
+// resource was given, keyed by the ID other resources see it by, so later resources can refer to
+// it.
+type importGenerator struct {
+	client *Client
+	file   *hclwrite.File
+	// headed is set when a section heading was the last thing written
+	headed bool
+
+	labels    map[string]map[string]bool
+	users     map[string]string
+	teams     map[string]string
+	clusters  map[string]string
+	queues    map[string]string
+	templates map[string]string
+	pipelines map[string]string
+}
+
+func newImportGenerator(client *Client) *importGenerator {
+	return &importGenerator{
+		client:    client,
+		file:      hclwrite.NewEmptyFile(),
+		labels:    map[string]map[string]bool{},
+		users:     map[string]string{},
+		teams:     map[string]string{},
+		clusters:  map[string]string{},
+		queues:    map[string]string{},
+		templates: map[string]string{},
+		pipelines: map[string]string{},
+	}
+}
+
+func (g *importGenerator) generate(ctx context.Context) (*hclwrite.File, error) {
+	g.file.Body().AppendUnstructuredTokens(comment(fmt.Sprintf(
+		"Generated by terraform-provider-buildkite generate-imports for the %s organization. Review the\n"+
+			"output of terraform plan before applying it.", g.client.organization)))
+
+	// Resources are written in dependency order, so each one's references are known by the time
+	// it is written.
+	for _, step := range []func(context.Context) error{
+		g.generateTeams,
+		g.generateClusters,
+		g.generatePipelineTemplates,
+		g.generatePipelines,
+		g.generateOrganizationRules,
+	} {
+		if err := step(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.file, nil
+}
+
+func (g *importGenerator) generateTeams(ctx context.Context) error {
+	var cursor *string
+	for {
+		res, err := GetOrganizationMembers(ctx, g.client.genqlient, g.client.organization, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting organization members: %w", err)
+		}
+		for _, member := range res.Organization.Members.Edges {
+			user := member.Node.User
+			name, _, _ := strings.Cut(user.Email, "@")
+			if name == "" {
+				name = user.Name
+			}
+			g.users[user.Id] = name
+		}
+		if !res.Organization.Members.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &res.Organization.Members.PageInfo.EndCursor
+	}
+
+	var teams []GetOrganizationTeamsOrganizationTeamsTeamConnectionEdgesTeamEdgeNodeTeam
+	cursor = nil
+	for {
+		res, err := GetOrganizationTeams(ctx, g.client.genqlient, g.client.organization, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting organization teams: %w", err)
+		}
+		for _, team := range res.Organization.Teams.Edges {
+			teams = append(teams, team.Node)
+		}
+		if !res.Organization.Teams.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &res.Organization.Teams.PageInfo.EndCursor
+	}
+	sort.SliceStable(teams, func(i, j int) bool { return teams[i].Slug < teams[j].Slug })
+
+	if len(teams) > 0 {
+		g.section("Teams")
+	}
+	for _, team := range teams {
+		label := g.label("buildkite_team", team.Slug)
+		g.teams[team.Id] = label
+
+		body := g.resource("buildkite_team", label, team.Id)
+		body.SetAttributeValue("name", cty.StringVal(team.Name))
+		setOptionalString(body, "description", team.Description)
+		body.SetAttributeValue("privacy", cty.StringVal(team.Privacy))
+		body.SetAttributeValue("default_team", cty.BoolVal(team.IsDefaultTeam))
+		body.SetAttributeValue("default_member_role", cty.StringVal(team.DefaultMemberRole))
+		setTrue(body, "members_can_create_pipelines", team.MembersCanCreatePipelines)
+		setTrue(body, "members_can_create_suites", team.MembersCanCreateSuites)
+		setTrue(body, "members_can_create_registries", team.MembersCanCreateRegistries)
+		setTrue(body, "members_can_destroy_registries", team.MembersCanDestroyRegistries)
+		setTrue(body, "members_can_destroy_packages", team.MembersCanDestroyPackages)
+
+		if err := g.generateTeamMembers(ctx, team.Slug, label); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *importGenerator) generateTeamMembers(ctx context.Context, teamSlug, teamLabel string) error {
+	var cursor *string
+	for {
+		res, err := GetTeamMembers(ctx, g.client.genqlient, fmt.Sprintf("%s/%s", g.client.organization, teamSlug), cursor)
+		if err != nil {
+			return fmt.Errorf("error getting members of team %s: %w", teamSlug, err)
+		}
+		for _, member := range res.Team.Members.Edges {
+			user, ok := g.users[member.Node.User.Id]
+			if !ok {
+				user = member.Node.Uuid
+			}
+
+			body := g.resource("buildkite_team_member", g.label("buildkite_team_member", teamLabel+"_"+user), member.Node.Id)
+			body.SetAttributeTraversal("team_id", reference("buildkite_team", teamLabel, "id"))
+			body.SetAttributeValue("user_id", cty.StringVal(member.Node.User.Id))
+			body.SetAttributeValue("role", cty.StringVal(member.Node.Role))
+		}
+		if !res.Team.Members.PageInfo.HasNextPage {
+			return nil
+		}
+		cursor = &res.Team.Members.PageInfo.EndCursor
+	}
+}
+
+func (g *importGenerator) generateClusters(ctx context.Context) error {
+	var clusters []GetOrganizationClustersOrganizationClustersClusterConnectionEdgesClusterEdgeNodeCluster
+	var cursor *string
+	for {
+		res, err := GetOrganizationClusters(ctx, g.client.genqlient, g.client.organization, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting organization clusters: %w", err)
+		}
+		for _, cluster := range res.Organization.Clusters.Edges {
+			clusters = append(clusters, cluster.Node)
+		}
+		if !res.Organization.Clusters.PageInfo.HasNextPage {
+			break
+		}
+		cursor = &res.Organization.Clusters.PageInfo.EndCursor
+	}
+	sort.SliceStable(clusters, func(i, j int) bool { return clusters[i].Name < clusters[j].Name })
+
+	if len(clusters) > 0 {
+		g.section("Clusters")
+	}
+	for _, cluster := range clusters {
+		label := g.label("buildkite_cluster", cluster.Name)
+		g.clusters[cluster.Id] = label
+
+		body := g.resource("buildkite_cluster", label, cluster.Id)
+		body.SetAttributeValue("name", cty.StringVal(cluster.Name))
+		setOptionalStringPointer(body, "description", cluster.Description)
+		setOptionalStringPointer(body, "emoji", cluster.Emoji)
+		setOptionalStringPointer(body, "color", cluster.Color)
+
+		if err := g.generateClusterQueues(ctx, cluster.Uuid, label); err != nil {
+			return err
+		}
+
+		if cluster.DefaultQueue != nil {
+			queueLabel, ok := g.queues[cluster.DefaultQueue.Id]
+			if !ok {
+				continue
+			}
+			body := g.resource("buildkite_cluster_default_queue", g.label("buildkite_cluster_default_queue", label), cluster.Id)
+			body.SetAttributeTraversal("cluster_id", reference("buildkite_cluster", label, "id"))
+			body.SetAttributeTraversal("queue_id", reference("buildkite_cluster_queue", queueLabel, "id"))
+		}
+	}
+
+	return nil
+}
+
+func (g *importGenerator) generateClusterQueues(ctx context.Context, clusterUUID, clusterLabel string) error {
+	var cursor *string
+	for {
+		res, err := getClusterQueues(ctx, g.client.genqlient, g.client.organization, clusterUUID, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting queues of cluster %s: %w", clusterUUID, err)
+		}
+		for _, edge := range res.Organization.Cluster.Queues.Edges {
+			queue := edge.Node
+			label := g.label("buildkite_cluster_queue", clusterLabel+"_"+queue.Key)
+			g.queues[queue.Id] = label
+
+			// Cluster queues import from the queue's ID and its cluster's UUID
+			body := g.resource("buildkite_cluster_queue", label, fmt.Sprintf("%s,%s", queue.Id, clusterUUID))
+			body.SetAttributeTraversal("cluster_id", reference("buildkite_cluster", clusterLabel, "id"))
+			body.SetAttributeValue("key", cty.StringVal(queue.Key))
+			setOptionalStringPointer(body, "description", queue.Description)
+			setTrue(body, "dispatch_paused", queue.DispatchPaused)
+			if queue.Hosted {
+				body.SetAttributeValue("hosted_agents", hostedAgentsValue(queue.HostedAgents.HostedAgentsQueueSettingsValues))
+			}
+		}
+		if !res.Organization.Cluster.Queues.PageInfo.HasNextPage {
+			return nil
+		}
+		cursor = &res.Organization.Cluster.Queues.PageInfo.EndCursor
+	}
+}
+
+func (g *importGenerator) generatePipelineTemplates(ctx context.Context) error {
+	var cursor *string
+	first := true
+	for {
+		res, err := getPipelineTemplates(ctx, g.client.genqlient, g.client.organization, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting pipeline templates: %w", err)
+		}
+		for _, edge := range res.Organization.PipelineTemplates.Edges {
+			if first {
+				g.section("Pipeline templates")
+				first = false
+			}
+			template := edge.Node
+			label := g.label("buildkite_pipeline_template", template.Name)
+			g.templates[template.Id] = label
+
+			body := g.resource("buildkite_pipeline_template", label, template.Id)
+			body.SetAttributeValue("name", cty.StringVal(template.Name))
+			setOptionalStringPointer(body, "description", template.Description)
+			setTrue(body, "available", template.Available)
+			body.SetAttributeRaw("configuration", stringTokens(template.Configuration))
+		}
+		if !res.Organization.PipelineTemplates.PageInfo.HasNextPage {
+			return nil
+		}
+		cursor = &res.Organization.PipelineTemplates.PageInfo.EndCursor
+	}
+}
+
+func (g *importGenerator) generatePipelines(ctx context.Context) error {
+	var cursor *string
+	first := true
+	for {
+		res, err := GetOrganizationPipelines(ctx, g.client.genqlient, g.client.organization, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting organization pipelines: %w", err)
+		}
+		for _, edge := range res.Organization.Pipelines.Edges {
+			if first {
+				g.section("Pipelines")
+				first = false
+			}
+			pipeline := edge.Node
+			label := g.label("buildkite_pipeline", pipeline.Slug)
+			g.pipelines[pipeline.PipelineUuid] = label
+
+			body := g.resource("buildkite_pipeline", label, pipeline.Id)
+			body.SetAttributeValue("name", cty.StringVal(pipeline.Name))
+			body.SetAttributeValue("repository", cty.StringVal(pipeline.Repository.Url))
+			setOptionalString(body, "description", pipeline.Description)
+			setOptionalString(body, "default_branch", pipeline.DefaultBranch)
+			setOptionalStringPointer(body, "branch_configuration", pipeline.BranchConfiguration)
+			if id := pipeline.Cluster.Id; id != nil {
+				g.setReference(body, "cluster_id", "buildkite_cluster", g.clusters, *id)
+			}
+			if id := pipeline.PipelineTemplate.Id; id != nil {
+				g.setReference(body, "pipeline_template_id", "buildkite_pipeline_template", g.templates, *id)
+			} else {
+				body.SetAttributeRaw("steps", stringTokens(pipeline.Steps.Yaml))
+			}
+			setOptionalStringPointer(body, "emoji", pipeline.Emoji)
+			setOptionalStringPointer(body, "color", pipeline.Color)
+			setOptionalStringPointer(body, "clone_mirror_url", pipeline.CloneMirrorUrl)
+			if pipeline.DefaultTimeoutInMinutes != nil {
+				body.SetAttributeValue("default_timeout_in_minutes", cty.NumberIntVal(int64(*pipeline.DefaultTimeoutInMinutes)))
+			}
+			if pipeline.MaximumTimeoutInMinutes != nil {
+				body.SetAttributeValue("maximum_timeout_in_minutes", cty.NumberIntVal(int64(*pipeline.MaximumTimeoutInMinutes)))
+			}
+			setTrue(body, "cancel_intermediate_builds", pipeline.CancelIntermediateBuilds)
+			setOptionalString(body, "cancel_intermediate_builds_branch_filter", pipeline.CancelIntermediateBuildsBranchFilter)
+			setTrue(body, "skip_intermediate_builds", pipeline.SkipIntermediateBuilds)
+			setOptionalString(body, "skip_intermediate_builds_branch_filter", pipeline.SkipIntermediateBuildsBranchFilter)
+			if !pipeline.AllowRebuilds {
+				body.SetAttributeValue("allow_rebuilds", cty.False)
+			}
+			if pipeline.Visibility == PipelineVisibilityPublic {
+				body.SetAttributeValue("visibility", cty.StringVal(string(pipeline.Visibility)))
+			}
+			if len(pipeline.Tags) > 0 {
+				tags := make([]cty.Value, len(pipeline.Tags))
+				for i, tag := range pipeline.Tags {
+					tags[i] = cty.StringVal(tag.Label)
+				}
+				body.SetAttributeValue("tags", cty.ListVal(tags))
+			}
+			setTrue(body, "archived", pipeline.Archived)
+
+			if listed := len(pipeline.Schedules.Edges); pipeline.Schedules.Count > listed {
+				g.section(fmt.Sprintf(
+					"The %s pipeline has %d schedules, but the Buildkite API lists only %d of them, so only those\n"+
+						"are generated below. Import the rest by their GraphQL IDs.", pipeline.Name, pipeline.Schedules.Count, listed))
+			}
+			for i, schedule := range pipeline.Schedules.Edges {
+				name := fmt.Sprint(i + 1)
+				if schedule.Node.Label != nil {
+					name = *schedule.Node.Label
+				}
+				g.generatePipelineSchedule(schedule.Node.PipelineScheduleValues, g.label("buildkite_pipeline_schedule", label+"_"+name), label)
+			}
+		}
+		if !res.Organization.Pipelines.PageInfo.HasNextPage {
+			return nil
+		}
+		cursor = &res.Organization.Pipelines.PageInfo.EndCursor
+	}
+}
+
+func (g *importGenerator) generatePipelineSchedule(schedule PipelineScheduleValues, label, pipelineLabel string) {
+	body := g.resource("buildkite_pipeline_schedule", label, schedule.Id)
+	body.SetAttributeTraversal("pipeline_id", reference("buildkite_pipeline", pipelineLabel, "id"))
+	body.SetAttributeValue("label", cty.StringVal(stringOrEmpty(schedule.Label)))
+	body.SetAttributeValue("cronline", cty.StringVal(stringOrEmpty(schedule.Cronline)))
+	body.SetAttributeValue("branch", cty.StringVal(stringOrEmpty(schedule.Branch)))
+	if commit := stringOrEmpty(schedule.Commit); commit != "" && commit != "HEAD" {
+		body.SetAttributeValue("commit", cty.StringVal(commit))
+	}
+	setOptionalStringPointer(body, "message", schedule.Message)
+	if env := schedule.Env; len(env) > 0 {
+		vars := map[string]cty.Value{}
+		for _, envVar := range env {
+			if envVar != nil {
+				key, value, _ := strings.Cut(*envVar, "=")
+				vars[key] = cty.StringVal(value)
+			}
+		}
+		body.SetAttributeValue("env", cty.MapVal(vars))
+	}
+	if !schedule.Enabled {
+		body.SetAttributeValue("enabled", cty.False)
+	}
+}
+
+func (g *importGenerator) generateOrganizationRules(ctx context.Context) error {
+	var cursor *string
+	first := true
+	for {
+		res, err := GetOrganizationRules(ctx, g.client.genqlient, g.client.organization, cursor)
+		if err != nil {
+			return fmt.Errorf("error getting organization rules: %w", err)
+		}
+		for _, edge := range res.Organization.Rules.Edges {
+			if first {
+				g.section("Organization rules")
+				first = false
+			}
+			rule := edge.Node
+
+			value, err := obtainValueJSON(rule.Document)
+			if err != nil {
+				return fmt.Errorf("error reading organization rule %s: %w", rule.Uuid, err)
+			}
+			valueTokens, name, err := g.ruleValue(*value)
+			if err != nil {
+				return fmt.Errorf("error reading organization rule %s: %w", rule.Uuid, err)
+			}
+
+			body := g.resource("buildkite_organization_rule", g.label("buildkite_organization_rule", rule.Type+"_"+name), rule.Id)
+			body.SetAttributeValue("type", cty.StringVal(rule.Type))
+			setOptionalStringPointer(body, "description", rule.Description)
+			body.SetAttributeRaw("value", valueTokens)
+		}
+		if !res.Organization.Rules.PageInfo.HasNextPage {
+			return nil
+		}
+		cursor = &res.Organization.Rules.PageInfo.EndCursor
+	}
+}
+
+// ruleValue returns a jsonencode call producing an organization rule's value, referring to the
+// pipelines it names by their resources. It also returns a name for the rule made from those
+// pipelines' labels.
+func (g *importGenerator) ruleValue(value string) (hclwrite.Tokens, string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return nil, "", err
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var attrs []hclwrite.ObjectAttrTokens
+	var names []string
+	for _, key := range keys {
+		var tokens hclwrite.Tokens
+
+		var uuid string
+		if json.Unmarshal(fields[key], &uuid) == nil && g.pipelines[uuid] != "" {
+			tokens = hclwrite.TokensForTraversal(reference("buildkite_pipeline", g.pipelines[uuid], "uuid"))
+			names = append(names, g.pipelines[uuid])
+		} else {
+			ty, err := ctyjson.ImpliedType(fields[key])
+			if err != nil {
+				return nil, "", err
+			}
+			val, err := ctyjson.Unmarshal(fields[key], ty)
+			if err != nil {
+				return nil, "", err
+			}
+			tokens = hclwrite.TokensForValue(val)
+		}
+
+		name := hclwrite.TokensForIdentifier(key)
+		if !hclsyntax.ValidIdentifier(key) {
+			name = hclwrite.TokensForValue(cty.StringVal(key))
+		}
+		attrs = append(attrs, hclwrite.ObjectAttrTokens{Name: name, Value: tokens})
+	}
+
+	return hclwrite.TokensForFunctionCall("jsonencode", hclwrite.TokensForObject(attrs)), strings.Join(names, "_"), nil
+}
+
+// resource appends an import block and an empty resource block for it, returning the body of the
+// resource block.
+func (g *importGenerator) resource(resourceType, label, importID string) *hclwrite.Body {
+	body := g.file.Body()
+
+	if !g.headed {
+		body.AppendNewline()
+	}
+	g.headed = false
+	importBlock := body.AppendNewBlock("import", nil).Body()
+	importBlock.SetAttributeTraversal("to", hcl.Traversal{hcl.TraverseRoot{Name: resourceType}, hcl.TraverseAttr{Name: label}})
+	importBlock.SetAttributeValue("id", cty.StringVal(importID))
+
+	body.AppendNewline()
+	return body.AppendNewBlock("resource", []string{resourceType, label}).Body()
+}
+
+// section appends a comment heading the resources that follow it.
+func (g *importGenerator) section(title string) {
+	g.file.Body().AppendNewline()
+	g.file.Body().AppendUnstructuredTokens(comment(title))
+	g.headed = true
+}
+
+// setReference sets name to refer to the resource the given ID was generated as, or to the ID
+// itself for one that was not generated.
+func (g *importGenerator) setReference(body *hclwrite.Body, name, resourceType string, labels map[string]string, id string) {
+	if label, ok := labels[id]; ok {
+		body.SetAttributeTraversal(name, reference(resourceType, label, "id"))
+		return
+	}
+	body.SetAttributeValue(name, cty.StringVal(id))
+}
+
+var nonLabelCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+// label returns a unique resource name of the given type, derived from name.
+func (g *importGenerator) label(resourceType, name string) string {
+	base := strings.Trim(nonLabelCharacters.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if base == "" {
+		base = strings.TrimPrefix(resourceType, "buildkite_")
+	}
+	// Resource names cannot start with a digit
+	if base[0] >= '0' && base[0] <= '9' {
+		base = "_" + base
+	}
+
+	used, ok := g.labels[resourceType]
+	if !ok {
+		used = map[string]bool{}
+		g.labels[resourceType] = used
+	}
+
+	label := base
+	for i := 2; used[label]; i++ {
+		label = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[label] = true
+
+	return label
+}
+
+func reference(resourceType, label, attribute string) hcl.Traversal {
+	return hcl.Traversal{
+		hcl.TraverseRoot{Name: resourceType},
+		hcl.TraverseAttr{Name: label},
+		hcl.TraverseAttr{Name: attribute},
+	}
+}
+
+func hostedAgentsValue(settings HostedAgentsQueueSettingsValues) cty.Value {
+	attrs := map[string]cty.Value{
+		"instance_shape": cty.StringVal(string(settings.InstanceShape.Name)),
+	}
+	if ref := settings.PlatformSettings.Linux.AgentImageRef; ref != "" {
+		attrs["linux"] = cty.ObjectVal(map[string]cty.Value{"agent_image_ref": cty.StringVal(ref)})
+	}
+	if xcode := settings.PlatformSettings.Macos.XcodeVersion; xcode != "" {
+		mac := map[string]cty.Value{"xcode_version": cty.StringVal(xcode)}
+		if version := settings.PlatformSettings.Macos.MacosVersion; version != nil {
+			mac["macos_version"] = cty.StringVal(string(*version))
+		}
+		attrs["mac"] = cty.ObjectVal(mac)
+	}
+
+	return cty.ObjectVal(attrs)
+}
+
+// stringTokens returns a multi-line string, such as pipeline steps, as a heredoc and any other
+// string as a quoted literal.
+func stringTokens(value string) hclwrite.Tokens {
+	if !strings.Contains(value, "\n") {
+		return hclwrite.TokensForValue(cty.StringVal(value))
+	}
+
+	delimiter := "EOT"
+	for i := 2; containsLine(value, delimiter); i++ {
+		delimiter = fmt.Sprintf("EOT%d", i)
+	}
+
+	// A heredoc always ends in a newline, so a value without one has it chomped back off
+	content := value
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	tokens := hclwrite.Tokens{
+		{Type: hclsyntax.TokenOHeredoc, Bytes: []byte("<<" + delimiter + "\n")},
+		{Type: hclsyntax.TokenStringLit, Bytes: []byte(strings.NewReplacer("${", "$${", "%{", "%%{").Replace(content))},
+		{Type: hclsyntax.TokenCHeredoc, Bytes: []byte(delimiter)},
+	}
+	if content == value {
+		return tokens
+	}
+
+	tokens = append(tokens, &hclwrite.Token{Type: hclsyntax.TokenNewline, Bytes: []byte("\n")})
+	return hclwrite.TokensForFunctionCall("chomp", tokens)
+}
+
+func containsLine(value, line string) bool {
+	for _, l := range strings.Split(value, "\n") {
+		if strings.TrimSpace(l) == line {
+			return true
+		}
+	}
+	return false
+}
+
+func comment(text string) hclwrite.Tokens {
+	var buf bytes.Buffer
+	for _, line := range strings.Split(text, "\n") {
+		buf.WriteString("# " + line + "\n")
+	}
+	return hclwrite.Tokens{{Type: hclsyntax.TokenComment, Bytes: buf.Bytes()}}
+}
+
+func setOptionalString(body *hclwrite.Body, name, value string) {
+	if value != "" {
+		body.SetAttributeRaw(name, stringTokens(value))
+	}
+}
+
+func setOptionalStringPointer(body *hclwrite.Body, name string, value *string) {
+	if value != nil {
+		setOptionalString(body, name, *value)
+	}
+}
+
+// setTrue sets an attribute that defaults to false only when it is true.
+func setTrue(body *hclwrite.Body, name string, value bool) {
+	if value {
+		body.SetAttributeValue(name, cty.True)
+	}
+}
+
+func stringOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
@@ -0,0 +1,334 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestImportGenerator(t *testing.T) {
+	t.Parallel()
+
+	server := fakebuildkite.New(t, "test-org")
+
+	var backend, platform, cluster, defaultQueue, hostedQueue fakebuildkite.Object
+	server.Update(func(st *fakebuildkite.State) {
+		team := func(name, slug string) fakebuildkite.Object {
+			id, uuid := st.NewID("Team")
+			team := fakebuildkite.Object{
+				"__typename": "Team", "id": id, "uuid": uuid, "name": name, "slug": slug, "description": "",
+				"privacy": "VISIBLE", "isDefaultTeam": false, "defaultMemberRole": "MEMBER",
+				"membersCanCreatePipelines": false, "membersCanCreateSuites": false, "membersCanCreateRegistries": false,
+				"membersCanDestroyRegistries": false, "membersCanDestroyPackages": false,
+			}
+			st.PutNode(team)
+			return team
+		}
+		backend = team("Backend", "backend")
+		platform = team("Platform Team", "platform-team")
+		platform["description"] = "Runs the platform"
+		platform["privacy"] = "SECRET"
+		platform["membersCanCreatePipelines"] = true
+
+		id, uuid := st.NewID("Cluster")
+		cluster = fakebuildkite.Object{"__typename": "Cluster", "id": id, "uuid": uuid, "name": "Default Cluster", "description": nil, "emoji": ":rocket:", "color": nil}
+		st.PutNode(cluster)
+
+		queue := func(key string) fakebuildkite.Object {
+			id, uuid := st.NewID("ClusterQueue")
+			queue := fakebuildkite.Object{
+				"__typename": "ClusterQueue", "id": id, "uuid": uuid, "key": key, "description": nil,
+				"cluster": fakebuildkite.Object{"id": cluster["id"], "uuid": cluster["uuid"]},
+				"hosted":  false, "hostedAgents": nil, "dispatchPaused": false,
+			}
+			st.PutNode(queue)
+			return queue
+		}
+		defaultQueue = queue("default")
+		hostedQueue = queue("hosted-linux")
+		hostedQueue["hosted"] = true
+		hostedQueue["dispatchPaused"] = true
+		hostedQueue["hostedAgents"] = fakebuildkite.Object{
+			"instanceShape":    fakebuildkite.Object{"name": "LINUX_AMD64_2X4"},
+			"platformSettings": fakebuildkite.Object{"linux": fakebuildkite.Object{"agentImageRef": "ubuntu:24.04"}, "macos": fakebuildkite.Object{"xcodeVersion": ""}},
+		}
+		cluster["defaultQueue"] = fakebuildkite.Object{"id": defaultQueue["id"], "uuid": defaultQueue["uuid"], "key": "default", "description": nil}
+	})
+
+	server.HandleGraphQL("GetOrganizationMembers", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		return fakebuildkite.Object{"organization": fakebuildkite.Object{"members": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "", "hasNextPage": false},
+			"edges": []fakebuildkite.Object{
+				{"node": fakebuildkite.Object{"user": fakebuildkite.Object{"id": "VXNlci0tLWE=", "uuid": "a", "name": "Alice Smith", "email": "alice.smith@example.com"}}},
+			},
+		}}}, nil
+	})
+	server.HandleGraphQL("GetTeamMembers", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		edges := []fakebuildkite.Object{}
+		if variables["slug"] == "test-org/backend" {
+			edges = append(edges, fakebuildkite.Object{"node": fakebuildkite.Object{
+				"id": "VGVhbU1lbWJlci0tLWE=", "uuid": "a", "role": "MAINTAINER",
+				"team": fakebuildkite.Object{"id": backend["id"]}, "user": fakebuildkite.Object{"id": "VXNlci0tLWE="},
+			}})
+		}
+		return fakebuildkite.Object{"team": fakebuildkite.Object{"members": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "", "hasNextPage": false},
+			"edges":    edges,
+		}}}, nil
+	})
+	server.HandleGraphQL("getPipelineTemplates", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		return fakebuildkite.Object{"organization": fakebuildkite.Object{"pipelineTemplates": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "", "hasNextPage": false},
+			"edges": []fakebuildkite.Object{
+				{"node": fakebuildkite.Object{
+					"id": "UGlwZWxpbmVUZW1wbGF0ZS0tLTE=", "uuid": "1", "name": "Deploy", "description": nil, "available": true,
+					"configuration": "steps:\n  - command: deploy ${BUILDKITE_BRANCH}\n",
+				}},
+			},
+		}}}, nil
+	})
+
+	// Two pages, so the pipelines of the second page still refer to what the first page defined
+	pipelines := []fakebuildkite.Object{
+		{
+			"id": "UGlwZWxpbmUtLS0x", "pipelineUuid": "pipeline-1", "name": "API", "slug": "api", "description": "",
+			"repository": fakebuildkite.Object{"url": "git@github.com:acme/api.git"}, "defaultBranch": "main",
+			"allowRebuilds": true, "visibility": "PRIVATE", "tags": []fakebuildkite.Object{},
+			"cluster":          fakebuildkite.Object{"id": cluster["id"], "name": "Default Cluster"},
+			"pipelineTemplate": fakebuildkite.Object{"id": "UGlwZWxpbmVUZW1wbGF0ZS0tLTE="},
+			"steps":            fakebuildkite.Object{"yaml": "steps:\n  - command: deploy"},
+			"schedules":        fakebuildkite.Object{"count": 0, "edges": []fakebuildkite.Object{}},
+		},
+		{
+			"id": "UGlwZWxpbmUtLS0y", "pipelineUuid": "pipeline-2", "name": "1 Web", "slug": "1-web", "description": "The website",
+			"repository": fakebuildkite.Object{"url": "git@github.com:acme/web.git"}, "defaultBranch": "main",
+			"allowRebuilds": false, "visibility": "PUBLIC", "defaultTimeoutInMinutes": 30,
+			"tags":             []fakebuildkite.Object{{"label": "frontend"}},
+			"cluster":          fakebuildkite.Object{"id": nil, "name": nil},
+			"pipelineTemplate": fakebuildkite.Object{"id": nil},
+			"steps":            fakebuildkite.Object{"yaml": "steps:\n  - command: make"},
+			// More schedules than were listed, as for a pipeline with over 100 of them
+			"schedules": fakebuildkite.Object{"count": 2, "edges": []fakebuildkite.Object{
+				{"node": fakebuildkite.Object{
+					"id": "UGlwZWxpbmVTY2hlZHVsZS0tLTE=", "uuid": "1", "label": "Nightly", "cronline": "@midnight", "branch": "main",
+					"commit": "HEAD", "message": nil, "env": []string{"FOO=bar"}, "enabled": false,
+					"pipeline": fakebuildkite.Object{"id": "UGlwZWxpbmUtLS0y"},
+				}},
+			}},
+		},
+	}
+	server.HandleGraphQL("GetOrganizationPipelines", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		page := 0
+		if variables["cursor"] == "1" {
+			page = 1
+		}
+		return fakebuildkite.Object{"organization": fakebuildkite.Object{"pipelines": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "1", "hasNextPage": page == 0},
+			"edges":    []fakebuildkite.Object{{"node": pipelines[page]}},
+		}}}, nil
+	})
+	server.HandleGraphQL("GetOrganizationRules", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		return fakebuildkite.Object{"organization": fakebuildkite.Object{"rules": fakebuildkite.Object{
+			"pageInfo": fakebuildkite.Object{"endCursor": "", "hasNextPage": false},
+			"edges": []fakebuildkite.Object{
+				{"node": fakebuildkite.Object{
+					"id": "UnVsZS0tLTE=", "uuid": "1", "type": "pipeline.trigger_build.pipeline", "description": "API deploys the website",
+					"document": `{"rule":"pipeline.trigger_build.pipeline","value":{"source_pipeline":"pipeline-1","target_pipeline":"pipeline-2","conditions":["build.branch == 'main'"]}}`,
+				}},
+			},
+		}}}, nil
+	})
+
+	file, err := newImportGenerator(newFakeClient(server)).generate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# The 1 Web pipeline has 2 schedules, but the Buildkite API lists only 1 of them"; !strings.Contains(string(file.Bytes()), want) {
+		t.Errorf("generated configuration does not report the schedules left out:\n%s", file.Bytes())
+	}
+	config, diags := hclsyntax.ParseConfig(file.Bytes(), "imports.tf", hcl.InitialPos)
+	if diags.HasErrors() {
+		t.Fatalf("generated configuration does not parse: %s\n%s", diags, file.Bytes())
+	}
+
+	// References evaluate to their own address, so they can be told apart from hard-coded IDs
+	resources := map[string]map[string]cty.Value{}
+	imports := map[string]string{}
+	for _, block := range config.Body.(*hclsyntax.Body).Blocks {
+		switch block.Type {
+		case "import":
+			traversal := block.Body.Attributes["to"].Expr.(*hclsyntax.ScopeTraversalExpr).Traversal
+			to := traversal.RootName() + "." + traversal[1].(hcl.TraverseAttr).Name
+			id, _ := block.Body.Attributes["id"].Expr.Value(nil)
+			imports[to] = id.AsString()
+		case "resource":
+			resources[block.Labels[0]+"."+block.Labels[1]] = map[string]cty.Value{}
+		}
+	}
+	variables := map[string]cty.Value{}
+	addresses := map[string]map[string]cty.Value{}
+	for address := range resources {
+		resourceType, label, _ := strings.Cut(address, ".")
+		if addresses[resourceType] == nil {
+			addresses[resourceType] = map[string]cty.Value{}
+		}
+		addresses[resourceType][label] = cty.ObjectVal(map[string]cty.Value{
+			"id":   cty.StringVal(address + ".id"),
+			"uuid": cty.StringVal(address + ".uuid"),
+		})
+	}
+	for resourceType, labels := range addresses {
+		variables[resourceType] = cty.ObjectVal(labels)
+	}
+	evalCtx := &hcl.EvalContext{
+		Variables: variables,
+		Functions: map[string]function.Function{"chomp": stdlib.ChompFunc, "jsonencode": stdlib.JSONEncodeFunc},
+	}
+	for _, block := range config.Body.(*hclsyntax.Body).Blocks {
+		if block.Type != "resource" {
+			continue
+		}
+		address := block.Labels[0] + "." + block.Labels[1]
+		if _, ok := imports[address]; !ok {
+			t.Errorf("%s has no import block", address)
+		}
+		for name, attr := range block.Body.Attributes {
+			value, diags := attr.Expr.Value(evalCtx)
+			if diags.HasErrors() {
+				t.Fatalf("%s.%s: %s", address, name, diags)
+			}
+			resources[address][name] = value
+		}
+	}
+
+	wantImports := map[string]string{
+		"buildkite_team.backend":                                                backend["id"].(string),
+		"buildkite_team.platform_team":                                          platform["id"].(string),
+		"buildkite_team_member.backend_alice_smith":                             "VGVhbU1lbWJlci0tLWE=",
+		"buildkite_cluster.default_cluster":                                     cluster["id"].(string),
+		"buildkite_cluster_queue.default_cluster_default":                       fmt.Sprintf("%s,%s", defaultQueue["id"], cluster["uuid"]),
+		"buildkite_cluster_queue.default_cluster_hosted_linux":                  fmt.Sprintf("%s,%s", hostedQueue["id"], cluster["uuid"]),
+		"buildkite_cluster_default_queue.default_cluster":                       cluster["id"].(string),
+		"buildkite_pipeline_template.deploy":                                    "UGlwZWxpbmVUZW1wbGF0ZS0tLTE=",
+		"buildkite_pipeline.api":                                                "UGlwZWxpbmUtLS0x",
+		"buildkite_pipeline._1_web":                                             "UGlwZWxpbmUtLS0y",
+		"buildkite_pipeline_schedule._1_web_nightly":                            "UGlwZWxpbmVTY2hlZHVsZS0tLTE=",
+		"buildkite_organization_rule.pipeline_trigger_build_pipeline_api_1_web": "UnVsZS0tLTE=",
+	}
+	for address, id := range wantImports {
+		if imports[address] != id {
+			t.Errorf("import of %s = %q, want %q", address, imports[address], id)
+		}
+	}
+	if len(imports) != len(wantImports) {
+		t.Errorf("got %d imports, want %d:\n%s", len(imports), len(wantImports), file.Bytes())
+	}
+
+	wantAttributes := map[string]map[string]cty.Value{
+		"buildkite_team.platform_team": {
+			"name":                         cty.StringVal("Platform Team"),
+			"description":                  cty.StringVal("Runs the platform"),
+			"privacy":                      cty.StringVal("SECRET"),
+			"default_team":                 cty.False,
+			"default_member_role":          cty.StringVal("MEMBER"),
+			"members_can_create_pipelines": cty.True,
+		},
+		"buildkite_team_member.backend_alice_smith": {
+			"team_id": cty.StringVal("buildkite_team.backend.id"),
+			"user_id": cty.StringVal("VXNlci0tLWE="),
+			"role":    cty.StringVal("MAINTAINER"),
+		},
+		"buildkite_cluster.default_cluster": {
+			"name":  cty.StringVal("Default Cluster"),
+			"emoji": cty.StringVal(":rocket:"),
+		},
+		"buildkite_cluster_queue.default_cluster_hosted_linux": {
+			"cluster_id":      cty.StringVal("buildkite_cluster.default_cluster.id"),
+			"key":             cty.StringVal("hosted-linux"),
+			"dispatch_paused": cty.True,
+			"hosted_agents": cty.ObjectVal(map[string]cty.Value{
+				"instance_shape": cty.StringVal("LINUX_AMD64_2X4"),
+				"linux":          cty.ObjectVal(map[string]cty.Value{"agent_image_ref": cty.StringVal("ubuntu:24.04")}),
+			}),
+		},
+		"buildkite_cluster_default_queue.default_cluster": {
+			"cluster_id": cty.StringVal("buildkite_cluster.default_cluster.id"),
+			"queue_id":   cty.StringVal("buildkite_cluster_queue.default_cluster_default.id"),
+		},
+		"buildkite_pipeline_template.deploy": {
+			"name":          cty.StringVal("Deploy"),
+			"available":     cty.True,
+			"configuration": cty.StringVal("steps:\n  - command: deploy ${BUILDKITE_BRANCH}\n"),
+		},
+		"buildkite_pipeline.api": {
+			"name":                 cty.StringVal("API"),
+			"repository":           cty.StringVal("git@github.com:acme/api.git"),
+			"default_branch":       cty.StringVal("main"),
+			"cluster_id":           cty.StringVal("buildkite_cluster.default_cluster.id"),
+			"pipeline_template_id": cty.StringVal("buildkite_pipeline_template.deploy.id"),
+		},
+		"buildkite_pipeline._1_web": {
+			"name":                       cty.StringVal("1 Web"),
+			"repository":                 cty.StringVal("git@github.com:acme/web.git"),
+			"description":                cty.StringVal("The website"),
+			"default_branch":             cty.StringVal("main"),
+			"steps":                      cty.StringVal("steps:\n  - command: make"),
+			"default_timeout_in_minutes": cty.NumberIntVal(30),
+			"allow_rebuilds":             cty.False,
+			"visibility":                 cty.StringVal("PUBLIC"),
+			"tags":                       cty.TupleVal([]cty.Value{cty.StringVal("frontend")}),
+		},
+		"buildkite_pipeline_schedule._1_web_nightly": {
+			"pipeline_id": cty.StringVal("buildkite_pipeline._1_web.id"),
+			"label":       cty.StringVal("Nightly"),
+			"cronline":    cty.StringVal("@midnight"),
+			"branch":      cty.StringVal("main"),
+			"env":         cty.ObjectVal(map[string]cty.Value{"FOO": cty.StringVal("bar")}),
+			"enabled":     cty.False,
+		},
+		"buildkite_organization_rule.pipeline_trigger_build_pipeline_api_1_web": {
+			"type":        cty.StringVal("pipeline.trigger_build.pipeline"),
+			"description": cty.StringVal("API deploys the website"),
+			"value":       cty.StringVal(`{"conditions":["build.branch == 'main'"],"source_pipeline":"buildkite_pipeline.api.uuid","target_pipeline":"buildkite_pipeline._1_web.uuid"}`),
+		},
+	}
+	for address, want := range wantAttributes {
+		got := resources[address]
+		for name, value := range want {
+			if got[name].IsNull() || !got[name].RawEquals(value) {
+				t.Errorf("%s.%s = %#v, want %#v", address, name, got[name], value)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s has attributes %v, want only %v", address, got, want)
+		}
+	}
+}
+
+func TestImportGeneratorLabel(t *testing.T) {
+	t.Parallel()
+
+	g := newImportGenerator(nil)
+	for _, tc := range []struct {
+		resourceType, name, want string
+	}{
+		{"buildkite_pipeline", "My Pipeline", "my_pipeline"},
+		{"buildkite_pipeline", "my-pipeline", "my_pipeline_2"},
+		{"buildkite_pipeline", "my_pipeline", "my_pipeline_3"},
+		{"buildkite_team", "my-pipeline", "my_pipeline"},
+		{"buildkite_pipeline", "2fa", "_2fa"},
+		{"buildkite_pipeline", "🚀", "pipeline"},
+	} {
+		if got := g.label(tc.resourceType, tc.name); got != tc.want {
+			t.Errorf("label(%q, %q) = %q, want %q", tc.resourceType, tc.name, got, tc.want)
+		}
+	}
+}
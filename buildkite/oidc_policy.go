@@ -0,0 +1,582 @@
+package buildkite
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"gopkg.in/yaml.v3"
+)
+
+// buildkiteOIDCIssuer issues the OIDC tokens Buildkite agents request for their jobs.
+const buildkiteOIDCIssuer = "https://agent.buildkite.com"
+
+// buildkiteOIDCClaims are the claims of a Buildkite agent OIDC token a policy can match on. Claims
+// of tokens from other issuers, such as GitHub Actions, are not checked.
+var buildkiteOIDCClaims = []string{
+	"organization_id",
+	"organization_slug",
+	"pipeline_id",
+	"pipeline_slug",
+	"build_number",
+	"build_branch",
+	"build_tag",
+	"build_commit",
+	"build_source",
+	"step_key",
+	"job_id",
+	"agent_id",
+	"cluster_id",
+	"cluster_name",
+	"queue_id",
+	"queue_key",
+	"runner_environment",
+}
+
+// buildkiteOIDCAgentTagClaim prefixes the claims an agent adds for its tags, such as agent_tag:queue.
+const buildkiteOIDCAgentTagClaim = "agent_tag:"
+
+// oidcPolicyOperators are the ways a claim can be matched, in the order they are rendered. equals
+// and not_equals take a single value; the others take a list.
+var oidcPolicyOperators = []string{"equals", "not_equals", "in", "not_in", "matches"}
+
+func oidcPolicyOperatorTakesList(operator string) bool {
+	return operator != "equals" && operator != "not_equals"
+}
+
+// oidcPolicyStatement is one entry of an OIDC policy: tokens from the issuer that match every claim
+// are granted the scopes. A policy grants access when any of its statements matches.
+type oidcPolicyStatement struct {
+	Issuer string
+	Scopes []string
+	// Claims maps each claim to the values it must match, by operator. Values are sorted.
+	Claims map[string]map[string][]string
+}
+
+var oidcPolicyConditionAttrTypes = map[string]attr.Type{
+	"claim":    types.StringType,
+	"operator": types.StringType,
+	"values":   types.SetType{ElemType: types.StringType},
+}
+
+var oidcPolicyRuleAttrTypes = map[string]attr.Type{
+	"issuer":     types.StringType,
+	"scopes":     types.SetType{ElemType: types.StringType},
+	"claims":     types.MapType{ElemType: types.StringType},
+	"conditions": types.ListType{ElemType: types.ObjectType{AttrTypes: oidcPolicyConditionAttrTypes}},
+}
+
+// oidcPolicyRulesSchema is the typed alternative to a raw oidc_policy, shared by the resources that
+// accept one.
+func oidcPolicyRulesSchema(subject, scopesExample string) schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "The OIDC policy as typed rules, rendered to `oidc_policy`. A token is exchanged for access to the " + subject + " when it matches every claim and condition of at least one rule. Conflicts with `oidc_policy`.",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"issuer": schema.StringAttribute{
+					Optional:            true,
+					Computed:            true,
+					Default:             stringdefault.StaticString(buildkiteOIDCIssuer),
+					MarkdownDescription: "The issuer of the tokens the rule accepts. Claims are checked against those of Buildkite agent tokens, including `agent_tag:` claims, when this is `" + buildkiteOIDCIssuer + "`, the default.",
+				},
+				"scopes": schema.SetAttribute{
+					Required:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "The scopes granted to matching tokens, for example `" + scopesExample + "`.",
+				},
+				"claims": schema.MapAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "Claims the token must have, mapped to the value each must equal, for example `{ organization_slug = \"my-org\" }`.",
+				},
+				"conditions": schema.ListNestedAttribute{
+					Optional:            true,
+					MarkdownDescription: "Claims the token must match by something other than equality.",
+					NestedObject: schema.NestedAttributeObject{
+						Attributes: map[string]schema.Attribute{
+							"claim": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "The claim to match, for example `build_branch`.",
+							},
+							"operator": schema.StringAttribute{
+								Required:            true,
+								MarkdownDescription: "How to match the claim: one of " + strings.Join(oidcPolicyOperators[1:], ", ") + ".",
+								Validators: []validator.String{
+									stringvalidator.OneOf(oidcPolicyOperators[1:]...),
+								},
+							},
+							"values": schema.SetAttribute{
+								Required:            true,
+								ElementType:         types.StringType,
+								MarkdownDescription: "The values to match. `not_equals` takes exactly one.",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// validate checks that the statement names an issuer, grants scopes and restricts the tokens it
+// accepts. Claims are only checked by name for Buildkite's own issuer.
+func (statement oidcPolicyStatement) validate() error {
+	if statement.Issuer == "" {
+		return fmt.Errorf("the issuer (iss) must be set")
+	}
+	if len(statement.Scopes) == 0 {
+		return fmt.Errorf("the rule grants no scopes")
+	}
+	if len(statement.Claims) == 0 {
+		return fmt.Errorf("the rule matches no claims, so it would accept any token from %s", statement.Issuer)
+	}
+
+	for _, claim := range slices.Sorted(maps.Keys(statement.Claims)) {
+		if statement.Issuer == buildkiteOIDCIssuer && !slices.Contains(buildkiteOIDCClaims, claim) && !strings.HasPrefix(claim, buildkiteOIDCAgentTagClaim) {
+			return fmt.Errorf("unknown claim %q, expected one of %s, or %s followed by a tag name", claim, strings.Join(buildkiteOIDCClaims, ", "), buildkiteOIDCAgentTagClaim)
+		}
+		for operator, values := range statement.Claims[claim] {
+			if len(values) == 0 {
+				return fmt.Errorf("%s: %s needs at least one value", claim, operator)
+			}
+			if !oidcPolicyOperatorTakesList(operator) && len(values) > 1 {
+				return fmt.Errorf("%s: %s takes a single value, use in or not_in to match several", claim, operator)
+			}
+			if slices.Contains(values, "") {
+				return fmt.Errorf("%s: values must not be empty", claim)
+			}
+		}
+	}
+
+	return nil
+}
+
+type oidcPolicyDocumentStatement struct {
+	Issuer string               `yaml:"iss"`
+	Scopes []string             `yaml:"scopes"`
+	Claims map[string]yaml.Node `yaml:"claims"`
+}
+
+// parseOIDCPolicy parses a YAML or JSON OIDC policy. It checks the policy's shape but not its
+// claims, so policies the API returns can be compared even when they use claims this provider does
+// not know about.
+func parseOIDCPolicy(policy string) ([]oidcPolicyStatement, error) {
+	if strings.TrimSpace(policy) == "" {
+		return nil, nil
+	}
+
+	var document []oidcPolicyDocumentStatement
+	decoder := yaml.NewDecoder(strings.NewReader(policy))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("the policy must be a list of rules with iss, scopes and claims: %w", err)
+	}
+
+	statements := make([]oidcPolicyStatement, 0, len(document))
+	for i, raw := range document {
+		statement := oidcPolicyStatement{Issuer: raw.Issuer, Scopes: sortedUnique(raw.Scopes), Claims: map[string]map[string][]string{}}
+		for claim, node := range raw.Claims {
+			conditions, err := parseOIDCPolicyClaim(node)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: %s %w", i+1, claim, err)
+			}
+			statement.Claims[claim] = conditions
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, nil
+}
+
+// parseOIDCPolicyClaim reads a claim's conditions: a bare value is shorthand for equals.
+func parseOIDCPolicyClaim(node yaml.Node) (map[string][]string, error) {
+	scalars := func(node *yaml.Node) ([]string, bool) {
+		switch node.Kind {
+		case yaml.ScalarNode:
+			return []string{node.Value}, true
+		case yaml.SequenceNode:
+			values := make([]string, 0, len(node.Content))
+			for _, item := range node.Content {
+				if item.Kind != yaml.ScalarNode {
+					return nil, false
+				}
+				values = append(values, item.Value)
+			}
+			return sortedUnique(values), true
+		}
+		return nil, false
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return map[string][]string{"equals": {node.Value}}, nil
+	case yaml.MappingNode:
+		conditions := map[string][]string{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			operator := node.Content[i].Value
+			if !slices.Contains(oidcPolicyOperators, operator) {
+				return nil, fmt.Errorf("has unknown operator %q, expected one of %s", operator, strings.Join(oidcPolicyOperators, ", "))
+			}
+			values, ok := scalars(node.Content[i+1])
+			if !ok {
+				return nil, fmt.Errorf("%s must be a string or a list of strings", operator)
+			}
+			conditions[operator] = values
+		}
+		return conditions, nil
+	}
+
+	return nil, fmt.Errorf("must be a value or a map of operators to values")
+}
+
+func sortedUnique(values []string) []string {
+	values = slices.Clone(values)
+	slices.Sort(values)
+	return slices.Compact(values)
+}
+
+// renderOIDCPolicy renders statements as canonical YAML: scopes and values sorted, claims in name
+// order, and a claim matched only by equality as a bare value.
+func renderOIDCPolicy(statements []oidcPolicyStatement) string {
+	document := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, statement := range statements {
+		document.Content = append(document.Content, renderOIDCPolicyStatement(statement))
+	}
+
+	return encodeYAMLNode(document)
+}
+
+func renderOIDCPolicyStatement(statement oidcPolicyStatement) *yaml.Node {
+	str := func(value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+	list := func(values []string) *yaml.Node {
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, value := range values {
+			node.Content = append(node.Content, str(value))
+		}
+		return node
+	}
+
+	claims := &yaml.Node{Kind: yaml.MappingNode}
+	for _, claim := range slices.Sorted(maps.Keys(statement.Claims)) {
+		conditions := statement.Claims[claim]
+		if equals, ok := conditions["equals"]; ok && len(conditions) == 1 && len(equals) == 1 {
+			claims.Content = append(claims.Content, str(claim), str(equals[0]))
+			continue
+		}
+		mapping := &yaml.Node{Kind: yaml.MappingNode}
+		for _, operator := range oidcPolicyOperators {
+			values, ok := conditions[operator]
+			if !ok {
+				continue
+			}
+			value := list(values)
+			if !oidcPolicyOperatorTakesList(operator) && len(values) == 1 {
+				value = str(values[0])
+			}
+			mapping.Content = append(mapping.Content, str(operator), value)
+		}
+		claims.Content = append(claims.Content, str(claim), mapping)
+	}
+
+	return &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		str("iss"), str(statement.Issuer),
+		str("scopes"), list(statement.Scopes),
+		str("claims"), claims,
+	}}
+}
+
+func encodeYAMLNode(node *yaml.Node) string {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	// Encoding a tree of plain scalars cannot fail.
+	_ = encoder.Encode(node)
+	_ = encoder.Close()
+	return buf.String()
+}
+
+// oidcPoliciesEqual reports whether two policies grant the same access, ignoring formatting, the
+// order of rules, claims and values, and whether the policy was written as YAML or JSON. Policies
+// that do not parse are compared as text.
+func oidcPoliciesEqual(a, b string) bool {
+	if a == b {
+		return true
+	}
+	statementsA, errA := parseOIDCPolicy(a)
+	statementsB, errB := parseOIDCPolicy(b)
+	if errA != nil || errB != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+
+	canonical := func(statements []oidcPolicyStatement) []string {
+		rendered := make([]string, len(statements))
+		for i, statement := range statements {
+			rendered[i] = encodeYAMLNode(renderOIDCPolicyStatement(statement))
+		}
+		slices.Sort(rendered)
+		return rendered
+	}
+	return slices.Equal(canonical(statementsA), canonical(statementsB))
+}
+
+// oidcPolicyRulesFromList reads oidc_policy_rules. It returns false when a rule is not yet known.
+// A claim set both in claims and as a condition with the same operator is reported against the rule.
+func oidcPolicyRulesFromList(ctx context.Context, list types.List, attribute path.Path) ([]oidcPolicyStatement, bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if list.IsUnknown() {
+		return nil, false, diags
+	}
+
+	var objects []types.Object
+	diags.Append(list.ElementsAs(ctx, &objects, false)...)
+	if diags.HasError() {
+		return nil, false, diags
+	}
+
+	statements := make([]oidcPolicyStatement, 0, len(objects))
+	for i, object := range objects {
+		if object.IsUnknown() {
+			return nil, false, diags
+		}
+		attributes := object.Attributes()
+		if !oidcPolicyValueKnown(object) {
+			return nil, false, diags
+		}
+
+		statement := oidcPolicyStatement{Issuer: buildkiteOIDCIssuer, Claims: map[string]map[string][]string{}}
+		if issuer, ok := attributes["issuer"].(basetypes.StringValue); ok && !issuer.IsNull() {
+			statement.Issuer = issuer.ValueString()
+		}
+		if scopes, ok := attributes["scopes"].(basetypes.SetValue); ok && !scopes.IsNull() {
+			diags.Append(scopes.ElementsAs(ctx, &statement.Scopes, false)...)
+			statement.Scopes = sortedUnique(statement.Scopes)
+		}
+		if claims, ok := attributes["claims"].(basetypes.MapValue); ok && !claims.IsNull() {
+			values := map[string]string{}
+			diags.Append(claims.ElementsAs(ctx, &values, false)...)
+			for claim, value := range values {
+				statement.Claims[claim] = map[string][]string{"equals": {value}}
+			}
+		}
+		if conditions, ok := attributes["conditions"].(basetypes.ListValue); ok && !conditions.IsNull() {
+			var conditionObjects []types.Object
+			diags.Append(conditions.ElementsAs(ctx, &conditionObjects, false)...)
+			for j, condition := range conditionObjects {
+				conditionAttributes := condition.Attributes()
+				claim := conditionAttributes["claim"].(basetypes.StringValue).ValueString()
+				operator := conditionAttributes["operator"].(basetypes.StringValue).ValueString()
+				var values []string
+				diags.Append(conditionAttributes["values"].(basetypes.SetValue).ElementsAs(ctx, &values, false)...)
+
+				if _, exists := statement.Claims[claim][operator]; exists {
+					diags.AddAttributeError(
+						attribute.AtListIndex(i).AtName("conditions").AtListIndex(j),
+						"Duplicate OIDC policy condition",
+						fmt.Sprintf("The rule already matches %s with %s. Combine the values into one condition.", claim, operator),
+					)
+					continue
+				}
+				if statement.Claims[claim] == nil {
+					statement.Claims[claim] = map[string][]string{}
+				}
+				statement.Claims[claim][operator] = sortedUnique(values)
+			}
+		}
+		statements = append(statements, statement)
+	}
+
+	return statements, !diags.HasError(), diags
+}
+
+// oidcPolicyValueKnown reports whether a rule, and everything nested in it, is known.
+func oidcPolicyValueKnown(value attr.Value) bool {
+	if value.IsUnknown() {
+		return false
+	}
+
+	var elements []attr.Value
+	switch value := value.(type) {
+	case basetypes.ObjectValue:
+		elements = slices.Collect(maps.Values(value.Attributes()))
+	case basetypes.ListValue:
+		elements = value.Elements()
+	case basetypes.SetValue:
+		elements = value.Elements()
+	case basetypes.MapValue:
+		elements = slices.Collect(maps.Values(value.Elements()))
+	}
+	for _, element := range elements {
+		if !oidcPolicyValueKnown(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// oidcPolicyRulesToList converts statements to an oidc_policy_rules value. Equality goes in claims
+// and every other operator becomes a condition.
+func oidcPolicyRulesToList(ctx context.Context, statements []oidcPolicyStatement) (types.List, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	conditionType := types.ObjectType{AttrTypes: oidcPolicyConditionAttrTypes}
+
+	objects := make([]attr.Value, 0, len(statements))
+	for _, statement := range statements {
+		claims := map[string]attr.Value{}
+		conditions := []attr.Value{}
+		for _, claim := range slices.Sorted(maps.Keys(statement.Claims)) {
+			for _, operator := range oidcPolicyOperators {
+				values, ok := statement.Claims[claim][operator]
+				if !ok {
+					continue
+				}
+				if operator == "equals" && len(values) == 1 {
+					claims[claim] = types.StringValue(values[0])
+					continue
+				}
+				set, setDiags := types.SetValueFrom(ctx, types.StringType, values)
+				diags.Append(setDiags...)
+				condition, conditionDiags := types.ObjectValue(oidcPolicyConditionAttrTypes, map[string]attr.Value{
+					"claim":    types.StringValue(claim),
+					"operator": types.StringValue(operator),
+					"values":   set,
+				})
+				diags.Append(conditionDiags...)
+				conditions = append(conditions, condition)
+			}
+		}
+
+		scopes, scopeDiags := types.SetValueFrom(ctx, types.StringType, statement.Scopes)
+		diags.Append(scopeDiags...)
+		claimsValue := types.MapNull(types.StringType)
+		if len(claims) > 0 {
+			var mapDiags diag.Diagnostics
+			claimsValue, mapDiags = types.MapValue(types.StringType, claims)
+			diags.Append(mapDiags...)
+		}
+		conditionsValue := types.ListNull(conditionType)
+		if len(conditions) > 0 {
+			var listDiags diag.Diagnostics
+			conditionsValue, listDiags = types.ListValue(conditionType, conditions)
+			diags.Append(listDiags...)
+		}
+
+		object, objectDiags := types.ObjectValue(oidcPolicyRuleAttrTypes, map[string]attr.Value{
+			"issuer":     types.StringValue(statement.Issuer),
+			"scopes":     scopes,
+			"claims":     claimsValue,
+			"conditions": conditionsValue,
+		})
+		diags.Append(objectDiags...)
+		objects = append(objects, object)
+	}
+
+	list, listDiags := types.ListValue(types.ObjectType{AttrTypes: oidcPolicyRuleAttrTypes}, objects)
+	diags.Append(listDiags...)
+	return list, diags
+}
+
+// planOIDCPolicy validates the configured oidc_policy or oidc_policy_rules and plans oidc_policy,
+// which is rendered from the rules when they are used. When neither is configured the policy is
+// cleared, unless keepOmitted is set for resources that leave an omitted policy unmanaged.
+func planOIDCPolicy(ctx context.Context, policy types.String, rules types.List, keepOmitted bool, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var statePolicy types.String
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("oidc_policy"), &statePolicy)...)
+	}
+
+	switch {
+	case !policy.IsNull():
+		if policy.IsUnknown() {
+			return
+		}
+		// The policy is free-form, so Buildkite has the final say on it when it is saved; problems
+		// found here are only warnings.
+		statements, err := parseOIDCPolicy(policy.ValueString())
+		if err == nil {
+			for i, statement := range statements {
+				if err = statement.validate(); err != nil {
+					err = fmt.Errorf("rule %d: %w", i+1, err)
+					break
+				}
+			}
+		}
+		if err != nil {
+			resp.Diagnostics.AddAttributeWarning(
+				path.Root("oidc_policy"),
+				"OIDC policy may be invalid",
+				fmt.Sprintf("%s\n\nBuildkite checks the policy when it is saved. Use oidc_policy_rules to have it checked at plan time.", err),
+			)
+		}
+	case !rules.IsNull():
+		statements, known, diags := oidcPolicyRulesFromList(ctx, rules, path.Root("oidc_policy_rules"))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() || !known {
+			return
+		}
+		for i, statement := range statements {
+			if err := statement.validate(); err != nil {
+				resp.Diagnostics.AddAttributeError(path.Root("oidc_policy_rules").AtListIndex(i), "Invalid OIDC policy rule", err.Error())
+			}
+		}
+		if resp.Diagnostics.HasError() {
+			return
+		}
+
+		rendered := types.StringValue(renderOIDCPolicy(statements))
+		// Keep the API's formatting of an equivalent policy so the plan has no change.
+		if !statePolicy.IsNull() && oidcPoliciesEqual(statePolicy.ValueString(), rendered.ValueString()) {
+			rendered = statePolicy
+		}
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("oidc_policy"), rendered)...)
+	case !keepOmitted:
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("oidc_policy"), types.StringNull())...)
+	}
+}
+
+// refreshedOIDCPolicy returns the policy to record after reading it from the API: the current value
+// when the API's means the same, so its re-serialisation does not show as a diff.
+func refreshedOIDCPolicy(current, fromAPI types.String) types.String {
+	if !current.IsNull() && !current.IsUnknown() && !fromAPI.IsNull() && oidcPoliciesEqual(current.ValueString(), fromAPI.ValueString()) {
+		return current
+	}
+	return fromAPI
+}
+
+// refreshOIDCPolicyRules brings oidc_policy_rules in line with a refreshed oidc_policy when they are
+// in use, so drift shows in the rules too. A policy the rules cannot express clears them, so the
+// next apply restores them.
+func refreshOIDCPolicyRules(ctx context.Context, policy types.String, rules *types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if rules.IsNull() || rules.IsUnknown() {
+		return diags
+	}
+
+	current, known, _ := oidcPolicyRulesFromList(ctx, *rules, path.Root("oidc_policy_rules"))
+	if known && oidcPoliciesEqual(renderOIDCPolicy(current), policy.ValueString()) {
+		return diags
+	}
+
+	*rules = types.ListNull(types.ObjectType{AttrTypes: oidcPolicyRuleAttrTypes})
+	if statements, err := parseOIDCPolicy(policy.ValueString()); err == nil && len(statements) > 0 {
+		*rules, diags = oidcPolicyRulesToList(ctx, statements)
+	}
+	return diags
+}
@@ -0,0 +1,265 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
+)
+
+func TestParseOIDCPolicy(t *testing.T) {
+	t.Parallel()
+
+	statements, err := parseOIDCPolicy(`
+- iss: https://agent.buildkite.com
+  scopes: [read_packages, read_packages]
+  claims:
+    pipeline_slug:
+      in: [web, api]
+    organization_slug: my-org
+    build_branch:
+      not_equals: gh-pages
+      matches: [release/*]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `- iss: https://agent.buildkite.com
+  scopes:
+    - read_packages
+  claims:
+    build_branch:
+      not_equals: gh-pages
+      matches:
+        - release/*
+    organization_slug: my-org
+    pipeline_slug:
+      in:
+        - api
+        - web
+`
+	if got := renderOIDCPolicy(statements); got != want {
+		t.Errorf("renderOIDCPolicy() =\n%s\nwant\n%s", got, want)
+	}
+
+	if statements, err := parseOIDCPolicy("  \n"); err != nil || len(statements) != 0 {
+		t.Errorf("an empty policy = %v, %v, want no rules", statements, err)
+	}
+}
+
+func TestOIDCPolicyRejectsTypos(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]string{
+		"iss: https://agent.buildkite.com": "must be a list of rules",
+		"- issuer: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {organization_slug: my-org}":     "field issuer not found",
+		"- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {organisation_slug: my-org}":        `rule 1: unknown claim "organisation_slug"`,
+		"- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {build_branch: {like: main}}":       `build_branch has unknown operator "like"`,
+		"- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {build_branch: {equals: [a, b]}}":   "equals takes a single value",
+		"- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {build_branch: [main]}":             "must be a value or a map of operators",
+		"- iss: https://agent.buildkite.com\n  scopes: [read_packages]":                                               "matches no claims",
+		"- iss: https://agent.buildkite.com\n  claims: {organization_slug: my-org}":                                   "grants no scopes",
+		"- scopes: [read_packages]\n  claims: {organization_slug: my-org}":                                            "the issuer (iss) must be set",
+		"- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {organization_slug: my-org}\n- bad": "must be a list of rules",
+	}
+
+	for policy, want := range testCases {
+		t.Run(policy, func(t *testing.T) {
+			t.Parallel()
+
+			statements, err := parseOIDCPolicy(policy)
+			for i := 0; err == nil && i < len(statements); i++ {
+				if err = statements[i].validate(); err != nil {
+					err = fmt.Errorf("rule %d: %w", i+1, err)
+				}
+			}
+			if err == nil || !strings.Contains(err.Error(), want) {
+				t.Errorf("policy %q error = %v, want one containing %q", policy, err, want)
+			}
+		})
+	}
+}
+
+func TestOIDCPolicyAllowsOtherIssuersClaims(t *testing.T) {
+	t.Parallel()
+
+	statements, err := parseOIDCPolicy("- iss: https://token.actions.githubusercontent.com\n  scopes: [read_packages]\n  claims: {repository: my-org/app}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := statements[0].validate(); err != nil {
+		t.Errorf("validate() = %v, want claims of other issuers accepted", err)
+	}
+}
+
+func TestOIDCPolicyAllowsAgentTagClaims(t *testing.T) {
+	t.Parallel()
+
+	statements, err := parseOIDCPolicy("- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims: {\"agent_tag:queue\": deploy}\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := statements[0].validate(); err != nil {
+		t.Errorf("validate() = %v, want agent tag claims accepted", err)
+	}
+}
+
+func TestPlanOIDCPolicyWarnsAboutRawPolicies(t *testing.T) {
+	t.Parallel()
+
+	plan := func(policy types.String, rules types.List) diag.Diagnostics {
+		var schemaResp frameworkresource.SchemaResponse
+		(&registryResource{}).Schema(t.Context(), frameworkresource.SchemaRequest{}, &schemaResp)
+		objectType := schemaResp.Schema.Type().TerraformType(t.Context())
+		req := frameworkresource.ModifyPlanRequest{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+		resp := &frameworkresource.ModifyPlanResponse{Plan: tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(objectType, nil)}}
+		planOIDCPolicy(t.Context(), policy, rules, false, req, resp)
+		return resp.Diagnostics
+	}
+
+	// A raw policy Buildkite may accept, such as one matching no claims, is only a warning
+	claimless := types.StringValue("- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n")
+	if diags := plan(claimless, types.ListNull(types.ObjectType{AttrTypes: oidcPolicyRuleAttrTypes})); diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("raw policy diagnostics = %v, want one warning", diags)
+	}
+
+	rules, diags := oidcPolicyRulesToList(t.Context(), []oidcPolicyStatement{{Issuer: buildkiteOIDCIssuer, Scopes: []string{"read_packages"}, Claims: map[string]map[string][]string{}}})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if diags := plan(types.StringNull(), rules); !diags.HasError() {
+		t.Errorf("rules diagnostics = %v, want an error", diags)
+	}
+}
+
+func TestOIDCPoliciesEqual(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		a, b string
+		want bool
+	}{
+		{
+			a:    "- iss: https://agent.buildkite.com\n  scopes: [read_packages]\n  claims:\n    organization_slug: my-org\n",
+			b:    `[{"iss":"https://agent.buildkite.com","claims":{"organization_slug":{"equals":"my-org"}},"scopes":["read_packages"]}]`,
+			want: true,
+		},
+		{
+			a:    "- iss: a\n  scopes: [s]\n  claims: {x: '1'}\n- iss: b\n  scopes: [s]\n  claims: {y: '2'}\n",
+			b:    "- iss: b\n  scopes: [s]\n  claims: {y: '2'}\n- iss: a\n  scopes: [s]\n  claims: {x: '1'}\n",
+			want: true,
+		},
+		{
+			a:    "- iss: a\n  scopes: [s]\n  claims: {x: {in: [one, two]}}\n",
+			b:    "- iss: a\n  scopes: [s]\n  claims: {x: {in: [two, one, one]}}\n",
+			want: true,
+		},
+		{
+			a:    "- iss: a\n  scopes: [s]\n  claims: {x: {in: [one]}}\n",
+			b:    "- iss: a\n  scopes: [s]\n  claims: {x: {not_in: [one]}}\n",
+			want: false,
+		},
+		{
+			a:    "- iss: a\n  scopes: [read]\n  claims: {x: '1'}\n",
+			b:    "- iss: a\n  scopes: [read, write]\n  claims: {x: '1'}\n",
+			want: false,
+		},
+		{
+			a:    "not: [a, policy",
+			b:    "not: [a, policy\n",
+			want: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := oidcPoliciesEqual(tc.a, tc.b); got != tc.want {
+			t.Errorf("oidcPoliciesEqual(%q, %q) = %t, want %t", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
+
+func TestOIDCPolicyRulesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	policy := "- iss: https://agent.buildkite.com\n  scopes:\n    - read_packages\n    - write_packages\n  claims:\n    build_branch:\n      equals: main\n      matches:\n        - release/*\n    organization_slug: my-org\n"
+
+	statements, err := parseOIDCPolicy(policy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, diags := oidcPolicyRulesToList(ctx, statements)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	roundTripped, known, diags := oidcPolicyRulesFromList(ctx, list, path.Root("oidc_policy_rules"))
+	if diags.HasError() || !known {
+		t.Fatalf("oidcPolicyRulesFromList() = %v, %v", known, diags)
+	}
+	if got := renderOIDCPolicy(roundTripped); got != policy {
+		t.Errorf("round tripped policy =\n%s\nwant\n%s", got, policy)
+	}
+}
+
+func TestUnitBuildkiteRegistryOIDCPolicyRules(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	config := func(rules string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_registry" "packages" {
+				name      = "packages"
+				ecosystem = "java"
+				team_ids  = ["00000000-0000-4000-8000-000000000001"]
+
+				oidc_policy_rules = %s
+			}
+		`, rules)
+	}
+	rules := `[{
+		scopes = ["read_packages"]
+		claims = { organization_slug = "test-org" }
+		conditions = [{ claim = "build_branch", operator = "in", values = ["main", "release"] }]
+	}]`
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config(rules),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_registry.packages", "oidc_policy",
+						"- iss: https://agent.buildkite.com\n  scopes:\n    - read_packages\n  claims:\n    build_branch:\n      in:\n        - main\n        - release\n    organization_slug: test-org\n"),
+					resource.TestCheckResourceAttr("buildkite_registry.packages", "oidc_policy_rules.0.issuer", buildkiteOIDCIssuer),
+				),
+			},
+			{
+				// The API handing back an equivalent policy in its own format is not a change.
+				PreConfig: func() {
+					server.Update(func(st *fakebuildkite.State) {
+						registry, _ := st.Object("/v2/packages/organizations/test-org/registries/packages")
+						registry["oidc_policy"] = `[{"iss":"https://agent.buildkite.com","scopes":["read_packages"],"claims":{"organization_slug":{"equals":"test-org"},"build_branch":{"in":["release","main"]}}}]`
+					})
+				},
+				Config: config(rules),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{plancheck.ExpectEmptyPlan()},
+				},
+			},
+			{
+				Config:      config(`[{ scopes = ["read_packages"], claims = { organisation_slug = "test-org" } }]`),
+				ExpectError: regexp.MustCompile(`unknown claim "organisation_slug"`),
+			},
+		},
+	})
+}
@@ -0,0 +1,151 @@
+package buildkite
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	schema_validator "github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/parser"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// graphQLSchemaSource is the Buildkite GraphQL schema the provider was generated against. `make
+// schema` refreshes it.
+//
+//go:embed schema.graphql
+var graphQLSchemaSource string
+
+// bundledGraphQLSchema parses the bundled schema once, on first use, since most runs never
+// validate a query. The schema is fetched by introspection, so it declares the builtin scalars
+// itself; like genqlient, only fall back to gqlparser's prelude when it does not.
+var bundledGraphQLSchema = sync.OnceValues(func() (*ast.Schema, error) {
+	document, err := parser.ParseSchema(&ast.Source{Name: "schema.graphql", Input: graphQLSchemaSource})
+	if err != nil {
+		return nil, err
+	}
+
+	if document.Definitions.ForName("String") == nil {
+		prelude, err := parser.ParseSchema(validator.Prelude)
+		if err != nil {
+			return nil, err
+		}
+		document.Merge(prelude)
+	}
+
+	return validator.ValidateSchemaDocument(document)
+})
+
+var portalVariableAttrTypes = map[string]attr.Type{
+	"name":     types.StringType,
+	"type":     types.StringType,
+	"required": types.BoolType,
+}
+
+// parsePortalQuery parses a portal query, returning an error if its syntax is invalid.
+func parsePortalQuery(query string) (*ast.QueryDocument, error) {
+	document, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		var gqlErr *gqlerror.Error
+		if errors.As(err, &gqlErr) {
+			return nil, formatPortalQueryErrors(gqlerror.List{gqlErr})
+		}
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// validatePortalQuery validates a parsed portal query against the bundled schema.
+func validatePortalQuery(document *ast.QueryDocument) error {
+	schema, err := bundledGraphQLSchema()
+	if err != nil {
+		return fmt.Errorf("unable to load the bundled GraphQL schema: %w", err)
+	}
+
+	if errs := validator.ValidateWithRules(schema, document, nil); len(errs) > 0 {
+		return formatPortalQueryErrors(errs)
+	}
+
+	return nil
+}
+
+func formatPortalQueryErrors(errs gqlerror.List) error {
+	lines := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if len(err.Locations) > 0 {
+			lines = append(lines, fmt.Sprintf("line %d, column %d: %s", err.Locations[0].Line, err.Locations[0].Column, err.Message))
+		} else {
+			lines = append(lines, err.Message)
+		}
+	}
+
+	return fmt.Errorf("%s", strings.Join(lines, "\n"))
+}
+
+// portalQueryVariables lists the variables a portal query declares, in declaration order. A
+// variable is required when its type is non-null and it has no default.
+func portalQueryVariables(query types.String) types.List {
+	if query.IsUnknown() {
+		return types.ListUnknown(types.ObjectType{AttrTypes: portalVariableAttrTypes})
+	}
+
+	var variables []attr.Value
+	if document, err := parsePortalQuery(query.ValueString()); err == nil {
+		for _, operation := range document.Operations {
+			for _, variable := range operation.VariableDefinitions {
+				variables = append(variables, types.ObjectValueMust(portalVariableAttrTypes, map[string]attr.Value{
+					"name":     types.StringValue(variable.Variable),
+					"type":     types.StringValue(variable.Type.String()),
+					"required": types.BoolValue(variable.Type.NonNull && variable.DefaultValue == nil),
+				}))
+			}
+		}
+	}
+
+	return types.ListValueMust(types.ObjectType{AttrTypes: portalVariableAttrTypes}, variables)
+}
+
+// portalQueryValidator rejects portal queries whose syntax is invalid, and warns about fields,
+// arguments or types that the bundled Buildkite schema does not have. The bundled schema can lag
+// behind the API, so Buildkite has the final say when the portal is saved.
+type portalQueryValidator struct{}
+
+func (v portalQueryValidator) Description(ctx context.Context) string {
+	return "value must be a GraphQL query, and should be valid against the Buildkite schema"
+}
+
+func (v portalQueryValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v portalQueryValidator) ValidateString(ctx context.Context, req schema_validator.StringRequest, resp *schema_validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	document, err := parsePortalQuery(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid portal query",
+			fmt.Sprintf("The query is not valid GraphQL:\n\n%s", err),
+		)
+		return
+	}
+
+	if err := validatePortalQuery(document); err != nil {
+		resp.Diagnostics.AddAttributeWarning(
+			req.Path,
+			"Portal query does not match the bundled schema",
+			fmt.Sprintf("The query is not valid against the Buildkite GraphQL schema bundled with this provider:\n\n%s\n\nBuildkite checks the query when the portal is saved. If the query uses a field Buildkite added recently, upgrading the provider picks up the newer schema.", err),
+		)
+	}
+}
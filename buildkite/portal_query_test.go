@@ -0,0 +1,152 @@
+package buildkite
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	schema_validator "github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+const testPortalQuery = `query pipelineBuilds($slug: ID!, $first: Int = 5, $branch: [String!]) {
+  pipeline(slug: $slug) {
+    name
+    builds(first: $first, branch: $branch) {
+      edges { node { number state } }
+    }
+  }
+}`
+
+func TestParsePortalQuery(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		query           string
+		wantSyntaxError string
+		wantSchemaError string
+	}{
+		"valid query":          {query: "{ viewer { user { name } } }"},
+		"valid with variables": {query: testPortalQuery},
+		"syntax error":         {query: "{ viewer { user { name } }", wantSyntaxError: "line 1, column 27: Expected Name, found <EOF>"},
+		"unknown field":        {query: "{ viewer { user { nmae } } }", wantSchemaError: `line 1, column 19: Cannot query field "nmae" on type "User".`},
+		"unknown argument":     {query: `{ pipeline(slug: "org/deploy", name: "deploy") { name } }`, wantSchemaError: `Unknown argument "name" on field "Query.pipeline".`},
+		"undeclared variable":  {query: "{ pipeline(slug: $slug) { name } }", wantSchemaError: `Variable "$slug" is not defined.`},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			document, err := parsePortalQuery(tc.query)
+			if !matchesError(err, tc.wantSyntaxError) {
+				t.Fatalf("parsePortalQuery() error = %v, want %q", err, tc.wantSyntaxError)
+			}
+			if err != nil {
+				return
+			}
+			if err := validatePortalQuery(document); !matchesError(err, tc.wantSchemaError) {
+				t.Errorf("validatePortalQuery() error = %v, want %q", err, tc.wantSchemaError)
+			}
+		})
+	}
+}
+
+// matchesError reports whether err contains want, or is nil when want is empty.
+func matchesError(err error, want string) bool {
+	if want == "" {
+		return err == nil
+	}
+	return err != nil && strings.Contains(err.Error(), want)
+}
+
+func TestPortalQueryValidator(t *testing.T) {
+	t.Parallel()
+
+	validate := func(query string) diag.Diagnostics {
+		resp := &schema_validator.StringResponse{}
+		portalQueryValidator{}.ValidateString(t.Context(), schema_validator.StringRequest{
+			Path:        path.Root("query"),
+			ConfigValue: types.StringValue(query),
+		}, resp)
+		return resp.Diagnostics
+	}
+
+	if diags := validate(testPortalQuery); len(diags) != 0 {
+		t.Errorf("a valid query = %v, want no diagnostics", diags)
+	}
+	// The bundled schema can be older than the API, so a field it does not have is only a warning
+	if diags := validate("{ viewer { user { nmae } } }"); diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("an unknown field = %v, want one warning", diags)
+	}
+	if diags := validate("{ viewer { user { name } }"); diags.ErrorsCount() != 1 {
+		t.Errorf("a syntax error = %v, want one error", diags)
+	}
+}
+
+func TestPortalQueryVariables(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	for _, value := range portalQueryVariables(types.StringValue(testPortalQuery)).Elements() {
+		attrs := value.(types.Object).Attributes()
+		got = append(got, attrs["name"].(types.String).ValueString()+" "+attrs["type"].(types.String).ValueString()+" "+attrs["required"].String())
+	}
+
+	want := []string{"slug ID! true", "first Int false", "branch [String!] false"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("portalQueryVariables() = %q, want %q", got, want)
+	}
+
+	if variables := portalQueryVariables(types.StringValue("{ viewer { id } }")); variables.IsNull() || len(variables.Elements()) != 0 {
+		t.Errorf("a query without variables = %s, want an empty list", variables)
+	}
+	if !portalQueryVariables(types.StringUnknown()).IsUnknown() {
+		t.Error("an unknown query should have unknown variables")
+	}
+}
+
+func TestUnitBuildkitePortalQueryValidation(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	config := func(query string) string {
+		return fakeProviderConfig(server) + `
+			resource "buildkite_portal" "builds" {
+				slug  = "pipeline-builds"
+				name  = "Pipeline builds"
+				query = <<-EOT
+` + query + `
+				EOT
+			}
+		`
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config(testPortalQuery),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_portal.builds", "variables.#", "3"),
+					resource.TestCheckResourceAttr("buildkite_portal.builds", "variables.0.name", "slug"),
+					resource.TestCheckResourceAttr("buildkite_portal.builds", "variables.0.type", "ID!"),
+					resource.TestCheckResourceAttr("buildkite_portal.builds", "variables.0.required", "true"),
+					resource.TestCheckResourceAttr("buildkite_portal.builds", "variables.1.required", "false"),
+				),
+			},
+			{
+				// A field missing from the bundled schema is a warning, so the portal is still saved
+				Config: config(strings.Replace(testPortalQuery, "state", "status", 1)),
+				Check:  resource.TestCheckResourceAttr("buildkite_portal.builds", "variables.#", "3"),
+			},
+			{
+				Config:      config(strings.TrimSuffix(testPortalQuery, "}")),
+				ExpectError: regexp.MustCompile(`Invalid portal query`),
+			},
+		},
+	})
+}
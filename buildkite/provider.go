@@ -10,16 +10,21 @@ import (
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 const (
 	defaultGraphqlEndpoint = "https://graphql.buildkite.com/v1"
 	defaultRestEndpoint    = "https://api.buildkite.com"
+	defaultPortalEndpoint  = "https://portal.buildkite.com"
 
 	DefaultTimeout               = 180 * time.Second
 	DefaultRetryMaxAttempts      = 10
@@ -33,6 +38,7 @@ const (
 	SchemaKeyAPIToken     = "api_token"
 	SchemaKeyGraphqlURL   = "graphql_url"
 	SchemaKeyRestURL      = "rest_url"
+	SchemaKeyPortalURL    = "portal_url"
 )
 
 type terraformProvider struct {
@@ -43,9 +49,19 @@ type terraformProvider struct {
 type providerModel struct {
 	ApiToken                types.String   `tfsdk:"api_token"`
 	ArchivePipelineOnDelete types.Bool     `tfsdk:"archive_pipeline_on_delete"`
+	CacheDataSourceReads    types.Bool     `tfsdk:"cache_data_source_reads"`
+	CACertFile              types.String   `tfsdk:"ca_cert_file"`
+	CACertPEM               types.String   `tfsdk:"ca_cert_pem"`
+	ClientCertFile          types.String   `tfsdk:"client_cert_file"`
+	ClientCertPEM           types.String   `tfsdk:"client_cert_pem"`
+	ClientKeyFile           types.String   `tfsdk:"client_key_file"`
+	ClientKeyPEM            types.String   `tfsdk:"client_key_pem"`
 	GraphqlUrl              types.String   `tfsdk:"graphql_url"`
+	InsecureSkipVerify      types.Bool     `tfsdk:"insecure_skip_verify"`
 	MaxRetries              types.Int64    `tfsdk:"max_retries"`
 	Organization            types.String   `tfsdk:"organization"`
+	PortalURL               types.String   `tfsdk:"portal_url"`
+	ProxyURL                types.String   `tfsdk:"proxy_url"`
 	RestURL                 types.String   `tfsdk:"rest_url"`
 	Timeouts                timeouts.Value `tfsdk:"timeouts"`
 }
@@ -59,6 +75,7 @@ func (tf *terraformProvider) Configure(ctx context.Context, req provider.Configu
 	graphqlUrl := defaultGraphqlEndpoint
 	organization := getenv("BUILDKITE_ORGANIZATION_SLUG")
 	restURL := defaultRestEndpoint
+	portalURL := defaultPortalEndpoint
 
 	if data.ApiToken.ValueString() != "" {
 		apiToken = data.ApiToken.ValueString()
@@ -76,25 +93,69 @@ func (tf *terraformProvider) Configure(ctx context.Context, req provider.Configu
 	} else if v, ok := os.LookupEnv("BUILDKITE_REST_URL"); ok {
 		restURL = v
 	}
+	if data.PortalURL.ValueString() != "" {
+		portalURL = data.PortalURL.ValueString()
+	} else if v, ok := os.LookupEnv("BUILDKITE_PORTAL_URL"); ok {
+		portalURL = v
+	}
 
 	maxRetries := DefaultRetryMaxAttempts
 	if !data.MaxRetries.IsNull() {
 		maxRetries = int(data.MaxRetries.ValueInt64())
 	}
 
+	transportSettings := transportConfig{
+		caCertFile:         os.Getenv("BUILDKITE_CA_CERT_FILE"),
+		caCertPEM:          data.CACertPEM.ValueString(),
+		clientCertFile:     os.Getenv("BUILDKITE_CLIENT_CERT_FILE"),
+		clientCertPEM:      data.ClientCertPEM.ValueString(),
+		clientKeyFile:      os.Getenv("BUILDKITE_CLIENT_KEY_FILE"),
+		clientKeyPEM:       data.ClientKeyPEM.ValueString(),
+		proxyURL:           data.ProxyURL.ValueString(),
+		insecureSkipVerify: data.InsecureSkipVerify.ValueBool(),
+	}
+	// A PEM given in configuration wins over a file named in the environment, as any explicit
+	// setting does over its environment variable.
+	if data.CACertFile.ValueString() != "" {
+		transportSettings.caCertFile = data.CACertFile.ValueString()
+	} else if transportSettings.caCertPEM != "" {
+		transportSettings.caCertFile = ""
+	}
+	if data.ClientCertFile.ValueString() != "" {
+		transportSettings.clientCertFile = data.ClientCertFile.ValueString()
+	} else if transportSettings.clientCertPEM != "" {
+		transportSettings.clientCertFile = ""
+	}
+	if data.ClientKeyFile.ValueString() != "" {
+		transportSettings.clientKeyFile = data.ClientKeyFile.ValueString()
+	} else if transportSettings.clientKeyPEM != "" {
+		transportSettings.clientKeyFile = ""
+	}
+
 	config := clientConfig{
 		apiToken:   strings.TrimSpace(apiToken),
 		graphqlURL: strings.TrimSpace(graphqlUrl),
 		org:        strings.TrimSpace(organization),
 		restURL:    strings.TrimSpace(restURL),
+		portalURL:  strings.TrimSpace(portalURL),
 		timeouts:   data.Timeouts,
 		userAgent:  userAgent("buildkite", tf.version, req.TerraformVersion),
 		maxRetries: maxRetries,
+		cacheReads: data.CacheDataSourceReads.ValueBool(),
+	}
+	if !transportSettings.isZero() {
+		transport, err := newTransport(transportSettings)
+		if err != nil {
+			resp.Diagnostics.AddError("Unable to configure the HTTP transport", err.Error())
+			return
+		}
+		config.transport = transport
 	}
 	client := NewClient(&config)
 
 	resp.ResourceData = client
-	resp.DataSourceData = client
+	resp.DataSourceData = client.dataSourceClient()
+	resp.EphemeralResourceData = client
 }
 
 func userAgent(providerName, providerVersion, tfVersion string) string {
@@ -121,7 +182,9 @@ func (*terraformProvider) DataSources(context.Context) []func() datasource.DataS
 	return []func() datasource.DataSource{
 		newClusterDatasource,
 		newClusterNetworkRangesDatasource,
+		newClusterSecretsDatasource,
 		newClustersDatasource,
+		newHostedAgentShapesDatasource,
 		newMetaDatasource,
 		newOrganizationDatasource,
 		newOrganizationMemberDatasource,
@@ -130,12 +193,21 @@ func (*terraformProvider) DataSources(context.Context) []func() datasource.DataS
 		newPipelineDatasource,
 		newPipelineTemplateDatasource,
 		newPortalDatasource,
+		newPortalInvocationDatasource,
 		newPortalsDatasource,
 		newRegistryDatasource,
 		newSignedPipelineStepsDataSource,
 		newTeamDatasource,
 		newTeamsDatasource,
 		newTestSuiteDatasource,
+		newTestSuitesDatasource,
+		newTokenScopesDatasource,
+	}
+}
+
+func (*terraformProvider) EphemeralResources(context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		newTestSuiteApiTokenEphemeralResource,
 	}
 }
 
@@ -152,6 +224,7 @@ func (tf *terraformProvider) Resources(context.Context) []func() resource.Resour
 		newClusterQueueResource,
 		newClusterResource,
 		newClusterSecretResource,
+		newClusterSecretsResource,
 		newDefaultQueueClusterResource,
 		newNotificationServiceResource,
 		newOrganizationBannerResource,
@@ -194,10 +267,60 @@ func (*terraformProvider) Schema(ctx context.Context, req provider.SchemaRequest
 				Optional:            true,
 				MarkdownDescription: "Base URL for the REST API to use. If not provided, the value is taken from the `BUILDKITE_REST_URL` environment variable.",
 			},
+			SchemaKeyPortalURL: schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Base URL for invoking portals, used by the `buildkite_portal_invocation` data source. If not provided, the value is taken from the `BUILDKITE_PORTAL_URL` environment variable.",
+			},
 			"archive_pipeline_on_delete": schema.BoolAttribute{
 				Optional:            true,
 				MarkdownDescription: "Enable this to archive pipelines when destroying the resource. This is opposed to completely deleting pipelines.",
 			},
+			"cache_data_source_reads": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Enable this to cache data source reads in memory for the duration of a single plan or apply, so that identical lookups repeated across modules call the API once. Resources always read from the API. Writes made by this provider invalidate the entries they affect; changes made outside Terraform during the run are not seen by cached lookups. Cache hits are logged at the `DEBUG` level.",
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path to a PEM encoded CA bundle to trust in addition to the system roots, for example the CA of a TLS-intercepting egress proxy. If not provided, the value is taken from the `BUILDKITE_CA_CERT_FILE` environment variable.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("ca_cert_pem")),
+				},
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded CA bundle to trust in addition to the system roots. Conflicts with `ca_cert_file`.",
+			},
+			"client_cert_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path to a PEM encoded client certificate presented for mutual TLS. Requires a client key. If not provided, the value is taken from the `BUILDKITE_CLIENT_CERT_FILE` environment variable.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("client_cert_pem")),
+				},
+			},
+			"client_cert_pem": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded client certificate presented for mutual TLS. Requires a client key. Conflicts with `client_cert_file`.",
+			},
+			"client_key_file": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "Path to the PEM encoded private key for the client certificate. If not provided, the value is taken from the `BUILDKITE_CLIENT_KEY_FILE` environment variable.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("client_key_pem")),
+				},
+			},
+			"client_key_pem": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "PEM encoded private key for the client certificate. Conflicts with `client_key_file`.",
+				Sensitive:           true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "URL of an HTTP proxy to send all API requests through, for example `http://proxy.internal:3128`. If not provided, the standard `HTTPS_PROXY` and `NO_PROXY` environment variables apply.",
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Optional:            true,
+				MarkdownDescription: "Skip verification of the API's TLS certificate. Only intended for local stand-ins of the Buildkite API; never enable this against buildkite.com.",
+			},
 			"max_retries": schema.Int64Attribute{
 				Optional:            true,
 				MarkdownDescription: "Maximum number of retry attempts for retryable HTTP requests. Defaults to 10. The waits between attempts count against the applicable `timeouts` value, so raising this alone does not necessarily produce more attempts.",
@@ -207,6 +330,8 @@ func (*terraformProvider) Schema(ctx context.Context, req provider.SchemaRequest
 	}
 }
 
+var _ provider.ProviderWithEphemeralResources = &terraformProvider{}
+
 // New is a helper function to simplify provider server and testing implementation.
 func New(version string) provider.Provider {
 	return &terraformProvider{
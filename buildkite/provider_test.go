@@ -1,13 +1,23 @@
 package buildkite
 
 import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
 	"net/http"
 	"os"
+	"sync"
 	"testing"
 
 	genqlient "github.com/Khan/genqlient/graphql"
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/buildkite/terraform-provider-buildkite/internal/recorder"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/shurcooL/graphql"
 )
 
@@ -15,10 +25,50 @@ var (
 	graphqlClient    *graphql.Client
 	genqlientGraphql genqlient.Client
 	organizationID   string
+
+	// testRecorder records or replays API traffic when BUILDKITE_RECORDER is set; see CONTRIBUTING.md.
+	testRecorder *recorder.Recorder
+	// missingCassette is the cassette a replay was asked for that has not been recorded yet. The
+	// acceptance tests skip rather than reach for the live API.
+	missingCassette string
 )
 
 func init() {
 	rt := http.DefaultTransport
+
+	cassette := os.Getenv("BUILDKITE_RECORDER_CASSETTE")
+	if cassette == "" {
+		cassette = "testdata/cassettes/acceptance.json"
+	}
+	mode := os.Getenv("BUILDKITE_RECORDER")
+	if _, err := os.Stat(cassette); recorder.Mode(mode) == recorder.ModeReplay && errors.Is(err, fs.ErrNotExist) {
+		missingCassette = cassette
+		mode = ""
+	}
+	var err error
+	testRecorder, err = recorder.Load(mode, cassette, rt)
+	if err != nil {
+		log.Fatalf("failed to set up the API recorder: %v", err)
+	}
+	if testRecorder != nil {
+		// Every client shares the one recorder, and with it the cassette. The transport each client
+		// would otherwise use is dropped, which is safe because tests configure no TLS settings.
+		rt = testRecorder
+		wrapTransport = func(http.RoundTripper) http.RoundTripper { return testRecorder }
+
+		switch testRecorder.Mode() {
+		case recorder.ModeRecord:
+			testRecorder.SetOrganization(getenv("BUILDKITE_ORGANIZATION_SLUG"))
+		case recorder.ModeReplay:
+			// Paths embed the organization, so a replay has to use the recorded one. The token is
+			// never sent anywhere, but the provider still expects one.
+			_ = os.Setenv("BUILDKITE_ORGANIZATION_SLUG", testRecorder.Organization())
+			if os.Getenv("BUILDKITE_API_TOKEN") == "" {
+				_ = os.Setenv("BUILDKITE_API_TOKEN", "replay")
+			}
+		}
+	}
+
 	header := make(http.Header)
 	header.Set("Authorization", "Bearer "+os.Getenv("BUILDKITE_API_TOKEN"))
 	header.Set("User-Agent", "testing")
@@ -38,13 +88,89 @@ func init() {
 	organizationID, _ = GetOrganizationID(getenv("BUILDKITE_ORGANIZATION_SLUG"), graphqlClient)
 }
 
+func TestMain(m *testing.M) {
+	code := m.Run()
+
+	if testRecorder != nil {
+		if err := testRecorder.Save(); err != nil {
+			log.Printf("failed to save the API cassette: %v", err)
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+var (
+	randStringMu     sync.Mutex
+	randStringCounts = map[string]int{}
+)
+
+// randString returns a random alphanumeric string of length n for naming test resources. While
+// recording or replaying, it is instead derived from the cassette's seed, the test's name and how
+// many strings that test has already asked for, so that a replay sends exactly the requests that
+// were recorded no matter what order parallel tests run in.
+func randString(t *testing.T, n int) string {
+	t.Helper()
+
+	if testRecorder == nil {
+		return acctest.RandString(n)
+	}
+
+	randStringMu.Lock()
+	count := randStringCounts[t.Name()]
+	randStringCounts[t.Name()]++
+	randStringMu.Unlock()
+
+	result := make([]byte, n)
+	for i := range result {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%d/%d", testRecorder.Seed(), t.Name(), count, i)))
+		result[i] = acctest.CharSetAlphaNum[binary.BigEndian.Uint32(sum[:4])%uint32(len(acctest.CharSetAlphaNum))]
+	}
+
+	return string(result)
+}
+
 func protoV6ProviderFactories() map[string]func() (tfprotov6.ProviderServer, error) {
 	return map[string]func() (tfprotov6.ProviderServer, error){
 		"buildkite": providerserver.NewProtocol6WithError(New("testing")),
 	}
 }
 
+// fakeProviderConfig configures the provider against a fake API started with fakebuildkite.New,
+// for unit tests that run resources without a live organization.
+func fakeProviderConfig(server *fakebuildkite.Server) string {
+	slug, _, _ := server.Organization()
+
+	return fmt.Sprintf(`
+		provider "buildkite" {
+			organization = %q
+			api_token    = "test-token"
+			graphql_url  = %q
+			rest_url     = %q
+			portal_url   = %q
+		}
+	`, slug, server.GraphQLURL(), server.URL(), server.URL())
+}
+
+// newFakeClient returns a client for the fake Buildkite API, for tests that call it directly.
+func newFakeClient(server *fakebuildkite.Server) *Client {
+	slug, _, _ := server.Organization()
+
+	return NewClient(&clientConfig{
+		apiToken:   "test",
+		graphqlURL: server.GraphQLURL(),
+		restURL:    server.URL(),
+		portalURL:  server.URL(),
+		org:        slug,
+		userAgent:  "test",
+	})
+}
+
 func testAccPreCheck(t *testing.T) {
+	if missingCassette != "" {
+		t.Skipf("there is no cassette at %s to replay, record one first as CONTRIBUTING.md describes", missingCassette)
+	}
 	if v := getenv("BUILDKITE_ORGANIZATION_SLUG"); v == "" {
 		t.Fatal("BUILDKITE_ORGANIZATION_SLUG must be set for acceptance tests")
 	}
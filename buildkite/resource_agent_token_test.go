@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -31,7 +30,7 @@ func TestAccBuildkiteAgentToken(t *testing.T) {
 	// Confirm that we can create a new agent token, and then delete it without error
 	t.Run("adds an agent token", func(t *testing.T) {
 		var resourceToken AgentTokenNode
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the token exists in the buildkite API
@@ -66,6 +65,17 @@ func TestAccBuildkiteAgentToken(t *testing.T) {
 					PlanOnly:     true,
 					Check:        checkRefresh,
 				},
+				{
+					// re-import the resource by its UUID and confirm they match; the token is only
+					// returned on creation
+					ResourceName: "buildkite_agent_token.foobar",
+					ImportStateIdFunc: func(s *terraform.State) (string, error) {
+						return s.RootModule().Resources["buildkite_agent_token.foobar"].Primary.Attributes["uuid"], nil
+					},
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"token"},
+				},
 			},
 		})
 	})
@@ -74,8 +84,8 @@ func TestAccBuildkiteAgentToken(t *testing.T) {
 	// Technically tokens can't be updated, so this will actuall do a delete+create
 	t.Run("updates an agent token", func(t *testing.T) {
 		var resourceToken AgentTokenNode
-		randName := acctest.RandString(10)
-		randNameUpdated := acctest.RandString(10)
+		randName := randString(t, 10)
+		randNameUpdated := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the token exists in the buildkite API
@@ -6,6 +6,7 @@ import (
 	"log"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	resource_schema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -192,14 +193,88 @@ func (ct *clusterAgentToken) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	var token *ClusterAgentTokenValues
 	for _, edge := range r.Organization.Cluster.AgentTokens.Edges {
 		if edge.Node.Id == state.Id.ValueString() {
-			log.Printf("Found cluster Token with Description %s in cluster %s", edge.Node.Id, state.ClusterUuid.ValueString())
-			state.Description = types.StringValue(edge.Node.Description)
-			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+			token = &edge.Node.ClusterAgentTokenValues
+			break
+		}
+	}
+
+	// Only a cluster's first 50 tokens are listed, so look up one that isn't before deciding it was revoked
+	if token == nil {
+		var node *getNodeResponse
+		err = retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+			var err error
+			node, err = getNode(ctx, ct.client.genqlient, state.Id.ValueString())
+			return retryContextError(err)
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to read Cluster Agent Token",
+				fmt.Sprintf("Unable to read Cluster Agent Token: %s", err.Error()),
+			)
 			return
 		}
+		if clusterToken, ok := node.GetNode().(*getNodeNodeClusterToken); ok {
+			token = &clusterToken.ClusterAgentTokenValues
+		}
+	}
+
+	if token == nil {
+		// Cluster agent token was revoked - remove from state
+		resp.Diagnostics.AddWarning("Cluster Agent Token not found", "Removing Cluster Agent Token from state")
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	log.Printf("Found cluster Token with Description %s in cluster %s", token.Id, state.ClusterUuid.ValueString())
+	state.Uuid = types.StringValue(token.Uuid)
+	state.Description = types.StringValue(token.Description)
+	state.ClusterId = types.StringValue(token.Cluster.Id)
+	state.ClusterUuid = types.StringValue(token.Cluster.Uuid)
+	// The token is never returned after creation, and allowed IP addresses are only read back
+	// when there are none in state, as after an import
+	if state.AllowedIpAddresses.IsNull() && token.AllowedIpAddresses != "" {
+		allowed, diags := types.ListValueFrom(ctx, types.StringType, strings.Fields(token.AllowedIpAddresses))
+		resp.Diagnostics.Append(diags...)
+		state.AllowedIpAddresses = allowed
 	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (ct *clusterAgentToken) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	// Import format: {cluster}/{token}, each either a GraphQL ID or a UUID
+	parts := strings.Split(req.ID, "/")
+	if len(parts) != 2 {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: cluster_id/token_id. Got: %q", req.ID),
+		)
+		return
+	}
+
+	clusterID, clusterUUID, ok := importNodeID("Cluster", parts[0])
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected the GraphQL ID or UUID of a cluster. Got: %q", parts[0]),
+		)
+		return
+	}
+	id, uuid, ok := importNodeID("ClusterToken", parts[1])
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected the GraphQL ID or UUID of a cluster agent token. Got: %q", parts[1]),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("uuid"), uuid)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_id"), clusterID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_uuid"), clusterUUID)...)
 }
 
 func (ct *clusterAgentToken) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"testing"
 
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -68,8 +72,8 @@ func TestAccBuildkiteClusterAgentTokenResource(t *testing.T) {
 
 	t.Run("creates a cluster agent token", func(t *testing.T) {
 		var ct clusterAgentTokenResourceModel
-		clusterName := acctest.RandString(10)
-		tokenDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		tokenDesc := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the token exists in the buildkite API
@@ -103,8 +107,8 @@ func TestAccBuildkiteClusterAgentTokenResource(t *testing.T) {
 
 	t.Run("creates a cluster agent token with allowed IPs", func(t *testing.T) {
 		var ct clusterAgentTokenResourceModel
-		clusterName := acctest.RandString(10)
-		tokenDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		tokenDesc := randString(t, 10)
 		allowedIps := []string{"10.100.1.0/28"}
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -127,15 +131,27 @@ func TestAccBuildkiteClusterAgentTokenResource(t *testing.T) {
 					Config: configAllowedIPsBasic(clusterName, tokenDesc, allowedIps),
 					Check:  check,
 				},
+				{
+					// re-import the resource by its cluster's GraphQL ID and its UUID and confirm they
+					// match; the token is only returned on creation
+					ResourceName: "buildkite_cluster_agent_token.foobar",
+					ImportStateIdFunc: func(s *terraform.State) (string, error) {
+						token := s.RootModule().Resources["buildkite_cluster_agent_token.foobar"].Primary
+						return fmt.Sprintf("%s/%s", token.Attributes["cluster_id"], token.Attributes["uuid"]), nil
+					},
+					ImportState:             true,
+					ImportStateVerify:       true,
+					ImportStateVerifyIgnore: []string{"token"},
+				},
 			},
 		})
 	})
 
 	t.Run("updates a cluster agent token", func(t *testing.T) {
 		var ct clusterAgentTokenResourceModel
-		clusterName := acctest.RandString(10)
-		tokenDesc := acctest.RandString(10)
-		updatedTokenDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		tokenDesc := randString(t, 10)
+		updatedTokenDesc := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the token exists in the buildkite API
@@ -173,6 +189,119 @@ func TestAccBuildkiteClusterAgentTokenResource(t *testing.T) {
 	})
 }
 
+func TestUnitBuildkiteClusterAgentTokenImport(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	config := fakeProviderConfig(server) + `
+		resource "buildkite_cluster" "cluster" {
+			name = "cluster"
+		}
+
+		resource "buildkite_cluster_agent_token" "token" {
+			cluster_id           = buildkite_cluster.cluster.id
+			description          = "agents"
+			allowed_ip_addresses = ["10.0.0.0/8", "192.168.0.0/16"]
+		}
+	`
+	importID := func(cluster, token string) resource.ImportStateIdFunc {
+		return func(s *terraform.State) (string, error) {
+			attributes := s.RootModule().Resources["buildkite_cluster_agent_token.token"].Primary.Attributes
+			return fmt.Sprintf("%s/%s", attributes[cluster], attributes[token]), nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+			},
+			{
+				ResourceName:            "buildkite_cluster_agent_token.token",
+				ImportStateIdFunc:       importID("cluster_uuid", "id"),
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+			{
+				ResourceName:            "buildkite_cluster_agent_token.token",
+				ImportStateIdFunc:       importID("cluster_id", "uuid"),
+				ImportState:             true,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"token"},
+			},
+			{
+				ResourceName:  "buildkite_cluster_agent_token.token",
+				ImportStateId: "not-a-cluster/not-a-token",
+				ImportState:   true,
+				ExpectError:   regexp.MustCompile(`Expected the GraphQL ID or UUID of a cluster`),
+			},
+		},
+	})
+}
+
+func TestClusterAgentTokenReadBeyondFirstPage(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+	ct := &clusterAgentToken{client: client}
+	ctx := context.Background()
+	_, orgID, _ := server.Organization()
+
+	cluster, err := createCluster(ctx, client.genqlient, orgID, "cluster", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	created, err := createClusterAgentToken(ctx, client.genqlient, orgID, cluster.ClusterCreate.Cluster.Id, "agents", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := created.ClusterAgentTokenCreate.ClusterAgentToken
+
+	// The cluster has more tokens than are listed, and this one isn't among them
+	server.HandleGraphQL("getClusterAgentTokens", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		return fakebuildkite.Object{"organization": fakebuildkite.Object{"cluster": fakebuildkite.Object{"agentTokens": fakebuildkite.Object{"edges": []fakebuildkite.Object{}}}}}, nil
+	})
+
+	var schemaResp frameworkresource.SchemaResponse
+	ct.Schema(ctx, frameworkresource.SchemaRequest{}, &schemaResp)
+	read := func() *frameworkresource.ReadResponse {
+		t.Helper()
+
+		state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+		if diags := state.Set(ctx, &clusterAgentTokenResourceModel{
+			Id:                 types.StringValue(token.Id),
+			Uuid:               types.StringValue(token.Uuid),
+			Description:        types.StringValue("renamed"),
+			Token:              types.StringValue("secret"),
+			ClusterId:          types.StringValue(token.Cluster.Id),
+			ClusterUuid:        types.StringValue(token.Cluster.Uuid),
+			AllowedIpAddresses: types.ListNull(types.StringType),
+		}); diags.HasError() {
+			t.Fatal(diags)
+		}
+		resp := &frameworkresource.ReadResponse{State: state}
+		ct.Read(ctx, frameworkresource.ReadRequest{State: state}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatal(resp.Diagnostics)
+		}
+		return resp
+	}
+
+	var model clusterAgentTokenResourceModel
+	if diags := read().State.Get(ctx, &model); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if model.Description.ValueString() != "agents" || model.Token.ValueString() != "secret" {
+		t.Errorf("state = %+v, want the token read back by ID", model)
+	}
+
+	if _, err := revokeClusterAgentToken(ctx, client.genqlient, orgID, token.Id); err != nil {
+		t.Fatal(err)
+	}
+	if resp := read(); !resp.State.Raw.IsNull() {
+		t.Error("a revoked token stayed in state")
+	}
+}
+
 func testAccCheckClusterAgentTokenExists(resourceName string, ct *clusterAgentTokenResourceModel) resource.TestCheckFunc {
 	return func(s *terraform.State) error {
 		resourceState, ok := s.RootModule().Resources[resourceName]
@@ -9,7 +9,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -38,7 +37,7 @@ func TestAccBuildkiteClusterMaintainerResource(t *testing.T) {
 			`, clusterName, userID)
 		}
 
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 		userID := "8db2920e-3c60-48a7-a3f8-2584be374bac" // Real user UUID from test environment (decoded from GraphQL ID)
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -95,7 +94,7 @@ func TestAccBuildkiteClusterMaintainerResource(t *testing.T) {
 			`, clusterName, clusterName)
 		}
 
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			testAccCheckClusterMaintainerExists("buildkite_cluster_maintainer.test_team"),
@@ -142,7 +141,7 @@ func TestAccBuildkiteClusterMaintainerResource(t *testing.T) {
 			`, clusterName, userID)
 		}
 
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 		userID := "8db2920e-3c60-48a7-a3f8-2584be374bac" // Real user UUID from test environment (decoded from GraphQL ID)
 		resourceName := "buildkite_cluster_maintainer.test_user"
 
@@ -214,7 +213,7 @@ func TestAccBuildkiteClusterMaintainerResource(t *testing.T) {
 			`, clusterName)
 		}
 
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -237,7 +236,7 @@ func TestAccBuildkiteClusterMaintainerResource(t *testing.T) {
 		// This ensures existing resources with cluster_id, user_id, team_id, and actor_id
 		// are automatically migrated to cluster_uuid, user_uuid, team_uuid, and actor_uuid
 
-		clusterName := acctest.RandString(12)
+		clusterName := randString(t, 12)
 		userID := "8db2920e-3c60-48a7-a3f8-2584be374bac" // Real user UUID from test environment
 
 		// Config for old provider version (uses old attribute names)
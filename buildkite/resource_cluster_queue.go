@@ -15,7 +15,6 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	resource_schema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
-	"github.com/hashicorp/terraform-plugin-framework/resource/schema/objectplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
@@ -25,56 +24,11 @@ import (
 )
 
 const (
-	// Available instance shapes
-	MacARM64InstanceM4Medium  string = "MACOS_ARM64_M4_6X28"
-	MacARM64InstanceM4Large   string = "MACOS_ARM64_M4_12X56"
-	LinuxAMD64InstanceSmall   string = "LINUX_AMD64_2X4"
-	LinuxAMD64InstanceMedium  string = "LINUX_AMD64_4X16"
-	LinuxAMD64InstanceLarge   string = "LINUX_AMD64_8X32"
-	LinuxAMD64InstanceXLarge  string = "LINUX_AMD64_16X64"
-	LinuxAMD64InstanceXXLarge string = "LINUX_AMD64_32X128"
-	LinuxAMD64Instance3XLarge string = "LINUX_AMD64_64X256"
-	LinuxARM64InstanceSmall   string = "LINUX_ARM64_2X4"
-	LinuxARM64InstanceMedium  string = "LINUX_ARM64_4X16"
-	LinuxARM64InstanceLarge   string = "LINUX_ARM64_8X32"
-	LinuxARM64InstanceXLarge  string = "LINUX_ARM64_16X64"
-	LinuxARM64InstanceXXLarge string = "LINUX_ARM64_32X128"
-	LinuxARM64Instance3XLarge string = "LINUX_ARM64_64X256"
-
-	// Available macOS versions
-	MacOSSonoma  string = "SONOMA"
-	MacOSSequoia string = "SEQUOIA"
-	MacOSTahoe   string = "TAHOE"
-
 	RetryAgentAffinityPreferWarmest   string = "prefer-warmest"
 	RetryAgentAffinityPreferDifferent string = "prefer-different"
 )
 
-var MacInstanceShapes = []string{
-	MacARM64InstanceM4Medium,
-	MacARM64InstanceM4Large,
-}
-
-var LinuxInstanceShapes = []string{
-	LinuxAMD64InstanceSmall,
-	LinuxAMD64InstanceMedium,
-	LinuxAMD64InstanceLarge,
-	LinuxAMD64InstanceXLarge,
-	LinuxAMD64InstanceXXLarge,
-	LinuxAMD64Instance3XLarge,
-	LinuxARM64InstanceSmall,
-	LinuxARM64InstanceMedium,
-	LinuxARM64InstanceLarge,
-	LinuxARM64InstanceXLarge,
-	LinuxARM64InstanceXXLarge,
-	LinuxARM64Instance3XLarge,
-}
-
-var MacOSVersions = []string{
-	MacOSSonoma,
-	MacOSSequoia,
-	MacOSTahoe,
-}
+var _ resource.ResourceWithModifyPlan = (*clusterQueueResource)(nil)
 
 type clusterQueueResourceModel struct {
 	Id                 types.String              `tfsdk:"id"`
@@ -182,34 +136,19 @@ func (clusterQueueResource) Schema(ctx context.Context, req resource.SchemaReque
 				},
 			},
 			"hosted_agents": resource_schema.SingleNestedAttribute{
-				Optional:            true,
-				MarkdownDescription: "Control the settings for the Buildkite hosted agents.",
+				Optional: true,
+				MarkdownDescription: heredoc.Doc(`
+					Control the settings for the Buildkite hosted agents.
+
+					Adding or removing this block switches the queue between self-hosted and hosted agents.
+					Buildkite cannot convert a queue in place, so the provider deletes the queue and recreates it
+					with the same key during the update, restoring its settings and its place as the cluster's
+					default queue. The queue's ` + "`id`" + ` and ` + "`uuid`" + ` change, and the plan lists what else is affected.
+					If the new queue cannot be created, the queue is recreated with its previous settings and the apply fails.
+				`),
 				Validators: []validator.Object{
 					&hostedAgentValidator{},
 				},
-				PlanModifiers: []planmodifier.Object{
-					objectplanmodifier.RequiresReplaceIf(func(ctx context.Context, or planmodifier.ObjectRequest, rrifr *objectplanmodifier.RequiresReplaceIfFuncResponse) {
-						var data *struct {
-							Mac           types.Object `tfsdk:"mac"`
-							Linux         types.Object `tfsdk:"linux"`
-							InstanceShape types.String `tfsdk:"instance_shape"`
-						}
-
-						rrifr.Diagnostics.Append(or.ConfigValue.As(ctx, &data, basetypes.ObjectAsOptions{})...)
-
-						if rrifr.Diagnostics.HasError() {
-							return
-						}
-
-						// If the hosted_agents attribute is added or removed e.g., change from a Hosted Agent to Self-Hosted Agent Queue
-						if or.StateValue.IsNull() && !or.PlanValue.IsNull() || or.ConfigValue.IsNull() {
-							rrifr.RequiresReplace = true
-							return
-						}
-
-						rrifr.RequiresReplace = false
-					}, "Recreates the resource if the hosted_agents attribute is added or removed.", "Recreates the resource if the hosted_agents attribute is added or removed."),
-				},
 				Attributes: map[string]resource_schema.Attribute{
 					"mac": resource_schema.SingleNestedAttribute{
 						Optional: true,
@@ -221,7 +160,7 @@ func (clusterQueueResource) Schema(ctx context.Context, req resource.SchemaReque
 							"macos_version": resource_schema.StringAttribute{
 								Optional:    true,
 								Computed:    true,
-								Description: "The macOS version available to jobs in this queue. Buildkite selects the current default when this is omitted. This setting is experimental and may not work as expected.",
+								Description: "The macOS version available to jobs in this queue, one of the `macos_versions` listed by the `buildkite_hosted_agent_shapes` data source. Buildkite selects the current default when this is omitted. This setting is experimental and may not work as expected.",
 								PlanModifiers: []planmodifier.String{
 									stringplanmodifier.UseStateForUnknown(),
 								},
@@ -240,16 +179,11 @@ func (clusterQueueResource) Schema(ctx context.Context, req resource.SchemaReque
 					"instance_shape": resource_schema.StringAttribute{
 						Required: true,
 						MarkdownDescription: heredoc.Doc(`
-								The instance shape to use for the Hosted Agent cluster queue. This can be a MacOS instance shape or a Linux instance shape.
-								Valid values are:
-								- ` + strings.Join(MacInstanceShapes, "\n								- ") + `
-								- ` + strings.Join(LinuxInstanceShapes, "\n								- ") + `
-							`),
-						Validators: []validator.String{
-							stringvalidator.OneOf(
-								append(MacInstanceShapes, LinuxInstanceShapes...)...,
-							),
-						},
+							The instance shape to use for the Hosted Agent cluster queue, such as ` + "`LINUX_AMD64_2X4`" + ` or
+							` + "`MACOS_ARM64_M4_6X28`" + `. Shapes starting with ` + "`MACOS_`" + ` require ` + "`mac`" + ` and shapes starting with
+							` + "`LINUX_`" + ` require ` + "`linux`" + `. The shape is checked at plan time against those Buildkite currently
+							offers, which the ` + "`buildkite_hosted_agent_shapes`" + ` data source lists.
+						`),
 					},
 				},
 			},
@@ -257,8 +191,183 @@ func (clusterQueueResource) Schema(ctx context.Context, req resource.SchemaReque
 	}
 }
 
+// ModifyPlan plans the switch between self-hosted and hosted agents that Update carries out, and
+// checks a new or changed hosted agent instance shape and macOS version against those Buildkite
+// currently offers, so one that has been withdrawn fails the plan rather than the apply.
+func (cq *clusterQueueResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var planned types.Object
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("hosted_agents"), &planned)...)
+	if resp.Diagnostics.HasError() || planned.IsUnknown() {
+		return
+	}
+	plan, diags := hostedAgentsAt(ctx, req.Plan.GetAttribute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	var state *hostedAgentResourceModel
+	if !req.State.Raw.IsNull() {
+		state, diags = hostedAgentsAt(ctx, req.State.GetAttribute)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if (plan == nil) != (state == nil) {
+			cq.planMigration(ctx, req, resp, plan != nil)
+			state = nil
+		}
+	}
+	if cq.client == nil || plan == nil {
+		return
+	}
+
+	// Only what this plan changes is checked, so a queue already running on a deprecated shape or
+	// version keeps planning cleanly.
+	shape := plan.InstanceShape
+	if state != nil && shape.Equal(state.InstanceShape) {
+		shape = types.StringNull()
+	}
+	macosVersion := types.StringNull()
+	if plan.Mac != nil {
+		macosVersion = plan.Mac.MacosVersion
+		if state != nil && state.Mac != nil && macosVersion.Equal(state.Mac.MacosVersion) {
+			macosVersion = types.StringNull()
+		}
+	}
+	if (shape.IsNull() || shape.IsUnknown()) && (macosVersion.IsNull() || macosVersion.IsUnknown()) {
+		return
+	}
+
+	catalogue, err := cq.client.HostedAgentCatalogue(ctx)
+	if err != nil {
+		resp.Diagnostics.AddWarning(
+			"Unable to check hosted agent settings",
+			fmt.Sprintf("Unable to read the hosted agent shapes Buildkite offers, so the instance shape and macOS version were not checked: %s", err.Error()),
+		)
+		return
+	}
+	resp.Diagnostics.Append(checkHostedAgentSelection(catalogue, shape, macosVersion)...)
+}
+
+// planMigration marks the queue's id and uuid as changing when hosted_agents is added or removed,
+// and warns about everything the delete and recreate in migrateQueue affects.
+func (cq *clusterQueueResource) planMigration(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse, toHosted bool) {
+	var state clusterQueueResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("id"), types.StringUnknown())...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("uuid"), types.StringUnknown())...)
+
+	key := state.Key.ValueString()
+	direction := "from hosted to self-hosted agents"
+	agents := fmt.Sprintf("Buildkite stops providing hosted agents for the queue. Its jobs wait for self-hosted agents started with the tag queue=%s.", key)
+	if toHosted {
+		direction = "from self-hosted to hosted agents"
+		agents = fmt.Sprintf("The queue's jobs run on Buildkite hosted agents. Self-hosted agents started with the tag queue=%s no longer run them.", key)
+	}
+
+	affected := []string{
+		"The queue's id and uuid change. Resources that reference them, such as buildkite_cluster_default_queue, are updated with the new values in the same apply.",
+		fmt.Sprintf("Pipelines that target the queue by its key, %s, keep working without changes.", key),
+		agents,
+		"dispatch_paused and retry_agent_affinity are applied to the new queue.",
+		"If the new queue cannot be created, the queue is recreated with its current settings and the apply fails.",
+	}
+	if cq.client != nil {
+		isDefault, err := cq.isDefaultQueue(ctx, state.ClusterId.ValueString(), state.Id.ValueString())
+		switch {
+		case err != nil:
+			affected = append(affected, fmt.Sprintf("Whether the queue is its cluster's default queue could not be checked: %s", err.Error()))
+		case isDefault:
+			affected = append(affected, "The queue is its cluster's default queue. It stops being the default while it is recreated and is restored afterwards, so jobs that do not target a queue cannot be dispatched in between.")
+		}
+	}
+
+	resp.Diagnostics.AddWarning(
+		"Cluster Queue will be recreated",
+		fmt.Sprintf("Switching queue %s %s deletes it and recreates it with the same key, because Buildkite cannot convert a queue in place.\n\n- %s", key, direction, strings.Join(affected, "\n- ")),
+	)
+}
+
+// isDefaultQueue reports whether the queue is its cluster's default queue.
+func (cq *clusterQueueResource) isDefaultQueue(ctx context.Context, clusterID, queueID string) (bool, error) {
+	r, err := getNode(ctx, cq.client.genqlient, clusterID)
+	if err != nil {
+		return false, err
+	}
+	cluster, ok := r.GetNode().(*getNodeNodeCluster)
+	if !ok {
+		return false, fmt.Errorf("cluster %s not found", clusterID)
+	}
+
+	return cluster.DefaultQueue != nil && cluster.DefaultQueue.Id == queueID, nil
+}
+
+// hostedAgentsAt reads the hosted_agents block from a plan or state, returning nil when it is
+// null or not yet known.
+func hostedAgentsAt(ctx context.Context, getAttribute func(context.Context, path.Path, interface{}) diag.Diagnostics) (*hostedAgentResourceModel, diag.Diagnostics) {
+	var object types.Object
+	diags := getAttribute(ctx, path.Root("hosted_agents"), &object)
+	if diags.HasError() || object.IsNull() || object.IsUnknown() {
+		return nil, diags
+	}
+
+	var hostedAgents hostedAgentResourceModel
+	diags.Append(object.As(ctx, &hostedAgents, basetypes.ObjectAsOptions{})...)
+	return &hostedAgents, diags
+}
+
+// checkHostedAgentSelection reports an instance shape or macOS version Buildkite does not offer as
+// an error, and a deprecated one as a warning. Null and unknown values are not checked.
+func checkHostedAgentSelection(catalogue *hostedAgentCatalogue, shape, macosVersion types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	if !shape.IsNull() && !shape.IsUnknown() {
+		name := shape.ValueString()
+		if offered, ok := catalogue.shape(name); !ok {
+			diags.AddAttributeError(
+				path.Root("hosted_agents").AtName("instance_shape"),
+				"Unavailable hosted agent instance shape",
+				fmt.Sprintf("Buildkite does not offer the instance shape %s. Available shapes are: %s", name, strings.Join(catalogue.shapeNames(hostedAgentOS(name)), ", ")),
+			)
+		} else if offered.Deprecated {
+			diags.AddAttributeWarning(
+				path.Root("hosted_agents").AtName("instance_shape"),
+				"Deprecated hosted agent instance shape",
+				fmt.Sprintf("The instance shape %s is deprecated. Available shapes are: %s", name, strings.Join(catalogue.shapeNames(offered.OS), ", ")),
+			)
+		}
+	}
+
+	if !macosVersion.IsNull() && !macosVersion.IsUnknown() {
+		name := macosVersion.ValueString()
+		if offered, ok := catalogue.macOSVersion(name); !ok {
+			diags.AddAttributeError(
+				path.Root("hosted_agents").AtName("mac").AtName("macos_version"),
+				"Unavailable macOS version",
+				fmt.Sprintf("Buildkite does not offer the macOS version %s. Available versions are: %s", name, strings.Join(catalogue.macOSVersionNames(), ", ")),
+			)
+		} else if offered.Deprecated {
+			diags.AddAttributeWarning(
+				path.Root("hosted_agents").AtName("mac").AtName("macos_version"),
+				"Deprecated macOS version",
+				fmt.Sprintf("The macOS version %s is deprecated. Available versions are: %s", name, strings.Join(catalogue.macOSVersionNames(), ", ")),
+			)
+		}
+	}
+
+	return diags
+}
+
 func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan, state clusterQueueResourceModel
+	var plan clusterQueueResourceModel
 
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
@@ -274,6 +383,17 @@ func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateR
 		return
 	}
 
+	if state := cq.createQueue(ctx, timeout, plan, &resp.Diagnostics); state != nil {
+		resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+	}
+}
+
+// createQueue creates the queue in plan and applies the settings the create mutation does not
+// take. It returns nil if the queue was not created, and the queue as far as it got if a later
+// step failed.
+func (cq *clusterQueueResource) createQueue(ctx context.Context, timeout time.Duration, plan clusterQueueResourceModel, diags *diag.Diagnostics) *clusterQueueResourceModel {
+	var state clusterQueueResourceModel
+
 	hosted := (*HostedAgentsQueueSettingsCreateInput)(nil)
 	if plan.HostedAgents != nil {
 		hosted = &HostedAgentsQueueSettingsCreateInput{
@@ -302,8 +422,8 @@ func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateR
 
 	org, err := cq.client.GetOrganizationID()
 	if err != nil {
-		resp.Diagnostics.AddError("Unable to get organization ID", fmt.Sprintf("Failed to get organization ID: %s", err.Error()))
-		return
+		diags.AddError("Unable to get organization ID", fmt.Sprintf("Failed to get organization ID: %s", err.Error()))
+		return nil
 	}
 
 	log.Printf("Creating cluster queue with key %s into cluster %s ...", plan.Key.ValueString(), plan.ClusterId.ValueString())
@@ -316,11 +436,11 @@ func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateR
 		hosted,
 	)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Unable to create Cluster Queue",
 			fmt.Sprintf("Unable to create Cluster Queue: %s", err.Error()),
 		)
-		return
+		return nil
 	}
 
 	state.Id = types.StringValue(r.ClusterQueueCreate.ClusterQueue.Id)
@@ -340,12 +460,11 @@ func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateR
 	if desiredAffinity != RetryAgentAffinityPreferWarmest {
 		err := cq.updateClusterQueueViaREST(ctx, state.ClusterUuid.ValueString(), state.Uuid.ValueString(), desiredAffinity)
 		if err != nil {
-			resp.Diagnostics.AddError(
+			diags.AddError(
 				"Unable to set retry_agent_affinity",
 				fmt.Sprintf("Queue %s created but retry_agent_affinity could not be set: %s", state.Key.ValueString(), err.Error()),
 			)
-			resp.State.Set(ctx, &state)
-			return
+			return &state
 		}
 	}
 	state.RetryAgentAffinity = types.StringValue(desiredAffinity)
@@ -354,10 +473,9 @@ func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateR
 	// so Pause Dispatch after creation if required
 	if plan.DispatchPaused.ValueBool() {
 		log.Printf("Pausing dispatch on cluster queue with key %s", plan.Key.ValueString())
-		err = cq.pauseDispatch(ctx, timeout, state, &resp.Diagnostics)
+		err = cq.pauseDispatch(ctx, timeout, state, diags)
 		if err != nil {
-			resp.State.Set(ctx, &state)
-			return
+			return &state
 		}
 		state.DispatchPaused = types.BoolValue(true)
 	}
@@ -385,7 +503,7 @@ func (cq *clusterQueueResource) Create(ctx context.Context, req resource.CreateR
 		}
 	}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	return &state
 }
 
 func (cq *clusterQueueResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -487,6 +605,11 @@ func (cq *clusterQueueResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
+	if (state.HostedAgents == nil) != (plan.HostedAgents == nil) {
+		cq.migrateQueue(ctx, timeout, plan, state, resp)
+		return
+	}
+
 	var r *updateClusterQueueResponse
 	hosted := (*HostedAgentsQueueSettingsUpdateInput)(nil)
 	if state.HostedAgents != nil {
@@ -616,6 +739,104 @@ func (cq *clusterQueueResource) Update(ctx context.Context, req resource.UpdateR
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
+// migrateQueue switches a queue between self-hosted and hosted agents. Buildkite cannot convert a
+// queue, and keys are unique within a cluster and cannot be changed, so the queue is deleted before
+// its replacement is created with the same key. A default queue cannot be deleted, so the cluster's
+// default is removed first and pointed at the replacement afterwards. If the replacement cannot be
+// created, the queue is recreated as it was and the apply fails.
+func (cq *clusterQueueResource) migrateQueue(ctx context.Context, timeout time.Duration, plan, state clusterQueueResourceModel, resp *resource.UpdateResponse) {
+	key := state.Key.ValueString()
+	clusterID := state.ClusterId.ValueString()
+
+	org, err := cq.client.GetOrganizationID()
+	if err != nil {
+		resp.Diagnostics.AddError("Unable to get organization ID", fmt.Sprintf("Failed to get organization ID: %s", err.Error()))
+		return
+	}
+
+	isDefault, err := cq.isDefaultQueue(ctx, clusterID, state.Id.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to recreate Cluster Queue",
+			fmt.Sprintf("Unable to check whether queue %s is its cluster's default queue: %s", key, err.Error()),
+		)
+		return
+	}
+	if isDefault {
+		log.Printf("Removing cluster queue %s as the default queue of cluster %s ...", key, clusterID)
+		if _, err := removeClusterDefaultQueue(ctx, cq.client.genqlient, *org, clusterID); err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to recreate Cluster Queue",
+				fmt.Sprintf("Unable to remove queue %s as its cluster's default queue: %s", key, err.Error()),
+			)
+			return
+		}
+	}
+
+	log.Printf("Deleting cluster queue %s to recreate it ...", state.Id.ValueString())
+	if _, err := deleteClusterQueue(ctx, cq.client.genqlient, *org, state.Id.ValueString()); err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to recreate Cluster Queue",
+			fmt.Sprintf("Unable to delete queue %s: %s", key, err.Error()),
+		)
+		if isDefault {
+			cq.restoreDefaultQueue(ctx, *org, clusterID, state.Id.ValueString(), key, &resp.Diagnostics)
+		}
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
+	created := cq.createQueue(ctx, timeout, plan, &resp.Diagnostics)
+	if created == nil {
+		created = cq.rollbackMigration(ctx, timeout, state, &resp.Diagnostics)
+	}
+	if created == nil {
+		// Keep the deleted queue's ID so the next refresh removes it and plans it for creation.
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+	if isDefault {
+		cq.restoreDefaultQueue(ctx, *org, clusterID, created.Id.ValueString(), key, &resp.Diagnostics)
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, created)...)
+}
+
+// rollbackMigration recreates the queue migrateQueue deleted, with the settings it had, after its
+// replacement could not be created. It returns nil if the queue could not be recreated either.
+func (cq *clusterQueueResource) rollbackMigration(ctx context.Context, timeout time.Duration, state clusterQueueResourceModel, diags *diag.Diagnostics) *clusterQueueResourceModel {
+	key := state.Key.ValueString()
+
+	log.Printf("Recreating cluster queue %s with its previous settings ...", key)
+	var rollbackDiags diag.Diagnostics
+	restored := cq.createQueue(ctx, timeout, state, &rollbackDiags)
+	if restored == nil {
+		diags.Append(rollbackDiags...)
+		diags.AddError(
+			"Unable to recreate Cluster Queue",
+			fmt.Sprintf("Queue %s was deleted and neither its replacement nor the original could be recreated. The next plan will create it.", key),
+		)
+		return nil
+	}
+
+	diags.Append(rollbackDiags...)
+	diags.AddError(
+		"Unable to recreate Cluster Queue",
+		fmt.Sprintf("Queue %s could not be switched to its new agents, so it was recreated with its previous settings. Its id and uuid have changed.", key),
+	)
+	return restored
+}
+
+func (cq *clusterQueueResource) restoreDefaultQueue(ctx context.Context, org, clusterID, queueID, key string, diags *diag.Diagnostics) {
+	log.Printf("Setting cluster queue %s as the default queue of cluster %s ...", key, clusterID)
+	if _, err := setClusterDefaultQueue(ctx, cq.client.genqlient, org, clusterID, queueID); err != nil {
+		diags.AddError(
+			"Unable to restore the default queue",
+			fmt.Sprintf("Queue %s is no longer its cluster's default queue and could not be made the default again: %s", key, err.Error()),
+		)
+	}
+}
+
 func (cq *clusterQueueResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var plan clusterQueueResourceModel
 
@@ -702,40 +923,21 @@ func (v hostedAgentValidator) ValidateObject(ctx context.Context, req validator.
 		return
 	}
 
-	// Validate Mac shapes
-	if hasMac {
-		isValid := false
-		for _, validShape := range MacInstanceShapes {
-			if shape == validShape {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("instance_shape"),
-				"Invalid instance shape for Mac platform",
-				fmt.Sprintf("Instance shape %s is not valid for Mac platform. Valid shapes are: %v", shape, MacInstanceShapes),
-			)
-		}
+	// The shape's name says which platform it is for; whether Buildkite still offers it is checked
+	// against the live catalogue in ModifyPlan.
+	if hasMac && hostedAgentOS(shape) != hostedAgentOSMacOS {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("instance_shape"),
+			"Invalid instance shape for Mac platform",
+			fmt.Sprintf("Instance shape %s is not valid for Mac platform. Mac instance shapes start with MACOS_.", shape),
+		)
 	}
-
-	// Validate Linux shapes
-	if hasLinux {
-		isValid := false
-		for _, validShape := range LinuxInstanceShapes {
-			if shape == validShape {
-				isValid = true
-				break
-			}
-		}
-		if !isValid {
-			resp.Diagnostics.AddAttributeError(
-				path.Root("instance_shape"),
-				"Invalid instance shape for Linux platform",
-				fmt.Sprintf("Instance shape %s is not valid for Linux platform. Valid shapes are: %v", shape, LinuxInstanceShapes),
-			)
-		}
+	if hasLinux && hostedAgentOS(shape) != hostedAgentOSLinux {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("instance_shape"),
+			"Invalid instance shape for Linux platform",
+			fmt.Sprintf("Instance shape %s is not valid for Linux platform. Linux instance shapes start with LINUX_.", shape),
+		)
 	}
 }
 
@@ -7,10 +7,17 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccBuildkiteClusterQueueResource(t *testing.T) {
@@ -244,9 +251,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("creates a cluster queue", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the cluster queue exists in the buildkite API
@@ -283,10 +290,10 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("updates a cluster queue", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
-		updatedQueueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
+		updatedQueueDesc := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the cluster queue exists in the buildkite API
@@ -327,9 +334,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("pause dispatch on a cluster queue", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the cluster queue exists in the buildkite API
 			testAccCheckClusterQueueExists("buildkite_cluster_queue.foobar", &cq),
@@ -369,9 +376,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("imports a cluster queue", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the cluster queue exists in the buildkite API
@@ -403,10 +410,10 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("preserves the API-selected macOS version during unrelated updates", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
-		updatedQueueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
+		updatedQueueDesc := randString(t, 10)
 		var macosVersion string
 
 		checkCreated := resource.ComposeAggregateTestCheckFunc(
@@ -450,9 +457,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("creates a hosted linux queue", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			testAccCheckClusterQueueExists("buildkite_cluster_queue.foobar", &cq),
@@ -474,9 +481,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 	})
 
 	t.Run("fails with invalid mac instance shape", func(t *testing.T) {
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -492,9 +499,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 	})
 
 	t.Run("fails with invalid linux instance shape", func(t *testing.T) {
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -510,9 +517,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 	})
 
 	t.Run("fails with both platforms specified", func(t *testing.T) {
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -529,9 +536,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("creates a cluster queue with retry_agent_affinity", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -551,9 +558,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("defaults retry_agent_affinity to prefer-warmest when omitted", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -573,9 +580,9 @@ func TestAccBuildkiteClusterQueueResource(t *testing.T) {
 
 	t.Run("updates retry_agent_affinity value", func(t *testing.T) {
 		var cq clusterQueueResourceModel
-		clusterName := acctest.RandString(10)
-		queueKey := acctest.RandString(10)
-		queueDesc := acctest.RandString(10)
+		clusterName := randString(t, 10)
+		queueKey := randString(t, 10)
+		queueDesc := randString(t, 10)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -680,3 +687,168 @@ func testAccCheckClusterQueueDestroy(s *terraform.State) error {
 	}
 	return nil
 }
+
+func TestUnitBuildkiteClusterQueueMigration(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	config := func(hostedAgents string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_cluster" "cluster" {
+				name = "cluster"
+			}
+
+			resource "buildkite_cluster_queue" "queue" {
+				cluster_id      = buildkite_cluster.cluster.id
+				key             = "default"
+				dispatch_paused = true
+				%s
+			}
+
+			resource "buildkite_cluster_default_queue" "default" {
+				cluster_id = buildkite_cluster.cluster.id
+				queue_id   = buildkite_cluster_queue.queue.id
+			}
+		`, hostedAgents)
+	}
+	hosted := `
+		hosted_agents = {
+			instance_shape = "LINUX_AMD64_2X4"
+			linux = {
+				agent_image_ref = "ubuntu:24.04"
+			}
+		}
+	`
+
+	var uuids []string
+	checkMigrated := func(s *terraform.State) error {
+		queue := s.RootModule().Resources["buildkite_cluster_queue.queue"].Primary
+		uuids = append(uuids, queue.Attributes["uuid"])
+		if len(uuids) > 1 && uuids[len(uuids)-1] == uuids[len(uuids)-2] {
+			return fmt.Errorf("queue uuid %s did not change", queue.Attributes["uuid"])
+		}
+
+		var defaultQueueID interface{}
+		server.Update(func(state *fakebuildkite.State) {
+			clusters := state.Nodes("Cluster", nil)
+			if defaultQueue, ok := clusters[0]["defaultQueue"].(fakebuildkite.Object); ok {
+				defaultQueueID = defaultQueue["id"]
+			}
+		})
+		if defaultQueueID != queue.ID {
+			return fmt.Errorf("cluster default queue = %v, want %s", defaultQueueID, queue.ID)
+		}
+		return nil
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config(""),
+				Check:  checkMigrated,
+			},
+			{
+				Config: config(hosted),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_cluster_queue.queue", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("buildkite_cluster_queue.queue", tfjsonpath.New("uuid")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					checkMigrated,
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.queue", "key", "default"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.queue", "dispatch_paused", "true"),
+					resource.TestCheckResourceAttr("buildkite_cluster_queue.queue", "hosted_agents.instance_shape", "LINUX_AMD64_2X4"),
+				),
+			},
+			{
+				Config: config(""),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_cluster_queue.queue", plancheck.ResourceActionUpdate),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					checkMigrated,
+					resource.TestCheckNoResourceAttr("buildkite_cluster_queue.queue", "hosted_agents.instance_shape"),
+				),
+			},
+		},
+	})
+}
+
+func TestClusterQueueMigrationRollback(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	client := newFakeClient(server)
+	cq := &clusterQueueResource{client: client}
+	ctx := context.Background()
+	_, orgID, _ := server.Organization()
+
+	cluster, err := createCluster(ctx, client.genqlient, orgID, "cluster", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clusterID := cluster.ClusterCreate.Cluster.Id
+
+	var diags diag.Diagnostics
+	queue := cq.createQueue(ctx, DefaultTimeout, clusterQueueResourceModel{
+		ClusterId:          types.StringValue(clusterID),
+		Key:                types.StringValue("default"),
+		Description:        types.StringValue("Self-hosted"),
+		DispatchPaused:     types.BoolValue(true),
+		RetryAgentAffinity: types.StringValue(RetryAgentAffinityPreferWarmest),
+	}, &diags)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if _, err := setClusterDefaultQueue(ctx, client.genqlient, orgID, clusterID, queue.Id.ValueString()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Buildkite refuses the hosted queue, but still creates self-hosted ones
+	server.HandleGraphQL("createClusterQueue", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		if variables["hostedAgents"] != nil {
+			return nil, fmt.Errorf("Hosted agents are not available for this organization")
+		}
+		id, uuid := st.NewID("ClusterQueue")
+		created := fakebuildkite.Object{
+			"__typename": "ClusterQueue", "id": id, "uuid": uuid, "key": variables["key"], "description": variables["description"],
+			"cluster": fakebuildkite.Object{"id": clusterID, "uuid": cluster.ClusterCreate.Cluster.Uuid}, "hosted": false, "hostedAgents": nil,
+			"dispatchPaused": false, "dispatchPausedAt": nil, "dispatchPausedBy": nil, "dispatchPausedNote": nil,
+		}
+		st.PutNode(created)
+		return fakebuildkite.Object{"clusterQueueCreate": fakebuildkite.Object{"clusterQueue": created}}, nil
+	})
+
+	plan := *queue
+	plan.HostedAgents = &hostedAgentResourceModel{
+		InstanceShape: types.StringValue("LINUX_AMD64_2X4"),
+		Linux:         &linuxConfigModel{ImageAgentRef: types.StringValue("ubuntu:24.04")},
+	}
+	var schemaResp frameworkresource.SchemaResponse
+	cq.Schema(ctx, frameworkresource.SchemaRequest{}, &schemaResp)
+	resp := &frameworkresource.UpdateResponse{State: tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}}
+	cq.migrateQueue(ctx, DefaultTimeout, plan, *queue, resp)
+
+	if !resp.Diagnostics.HasError() {
+		t.Fatal("migrating to a hosted queue Buildkite refused succeeded")
+	}
+	var state clusterQueueResourceModel
+	if diags := resp.State.Get(ctx, &state); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if state.HostedAgents != nil || state.Key.ValueString() != "default" || !state.DispatchPaused.ValueBool() || state.Description.ValueString() != "Self-hosted" {
+		t.Errorf("state = %+v, want the self-hosted queue recreated as it was", state)
+	}
+
+	server.Update(func(st *fakebuildkite.State) {
+		queues := st.Nodes("ClusterQueue", nil)
+		if len(queues) != 1 || queues[0]["id"] != state.Id.ValueString() || queues[0]["dispatchPaused"] != true {
+			t.Errorf("queues = %v, want only the recreated, paused queue", queues)
+		}
+		defaultQueue, _ := st.Nodes("Cluster", nil)[0]["defaultQueue"].(fakebuildkite.Object)
+		if defaultQueue == nil || defaultQueue["id"] != state.Id.ValueString() {
+			t.Errorf("cluster default queue = %v, want the recreated queue", defaultQueue)
+		}
+	})
+}
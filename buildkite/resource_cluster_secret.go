@@ -2,24 +2,37 @@ package buildkite
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
 	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 )
 
+// clusterSecretMinRotationPeriod is the shortest rotation_period accepted, so a typo such as "24m"
+// for "24h" does not rotate a secret on every apply.
+const clusterSecretMinRotationPeriod = time.Hour
+
+// clusterSecretRotationNow is the clock rotations are scheduled from, replaced in tests.
+var clusterSecretRotationNow = time.Now
+
 type clusterSecretResource struct {
 	client *Client
 }
@@ -33,6 +46,10 @@ type clusterSecretResourceModel struct {
 	ValueWOVersion types.String `tfsdk:"value_wo_version"`
 	Description    types.String `tfsdk:"description"`
 	Policy         types.String `tfsdk:"policy"`
+	AccessPolicy   types.List   `tfsdk:"access_policy"`
+	RotationPeriod types.String `tfsdk:"rotation_period"`
+	RandomBytes    types.Int64  `tfsdk:"random_bytes"`
+	LastRotatedAt  types.String `tfsdk:"last_rotated_at"`
 	CreatedAt      types.String `tfsdk:"created_at"`
 	UpdatedAt      types.String `tfsdk:"updated_at"`
 }
@@ -57,10 +74,12 @@ func (r *clusterSecretResource) ConfigValidators(ctx context.Context) []resource
 		resourcevalidator.ExactlyOneOf(
 			path.MatchRoot("value"),
 			path.MatchRoot("value_wo"),
+			path.MatchRoot("random_bytes"),
 		),
-		resourcevalidator.RequiredTogether(
-			path.MatchRoot("value_wo"),
-			path.MatchRoot("value_wo_version"),
+		clusterSecretValueVersionValidator{},
+		resourcevalidator.Conflicting(
+			path.MatchRoot("policy"),
+			path.MatchRoot("access_policy"),
 		),
 	}
 }
@@ -72,9 +91,19 @@ func (r *clusterSecretResource) Schema(ctx context.Context, req resource.SchemaR
 			Secrets are encrypted and can only be accessed by agents that match the access policy.
 
 			**Note:** Secret values are write-only in the Buildkite API and cannot be retrieved after they are set.
-			Exactly one of value or value_wo must be configured. The value attribute is stored in
+			Exactly one of value, value_wo or random_bytes must be configured. The value attribute is stored in
 			Terraform state so Terraform can detect changes. Use value_wo with value_wo_version
 			to pass a secret value without storing it in Terraform plan or state artifacts.
+
+			To rotate a secret on a schedule, set rotation_period and a generator: either random_bytes,
+			which has the provider generate a new random value, or value_wo, typically read from an
+			ephemeral resource. Once the period has elapsed since last_rotated_at, or will within the
+			provider's update timeout, the next plan updates the secret with a freshly generated value,
+			so value_wo_version does not need bumping by hand.
+
+			Configure the access policy with either the typed access_policy rules or a raw YAML policy.
+			Both are checked against the claims Buildkite supports at plan time, and the policy is
+			compared by meaning on refresh, so the API reformatting it does not cause a diff.
 		`),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -109,7 +138,7 @@ func (r *clusterSecretResource) Schema(ctx context.Context, req resource.SchemaR
 			"value": schema.StringAttribute{
 				Optional:            true,
 				Sensitive:           true,
-				MarkdownDescription: "The secret value. Must be less than 8KB. Exactly one of `value` or `value_wo` must be configured. This value is stored in Terraform state; use `value_wo` with `value_wo_version` to avoid storing secret values in state.",
+				MarkdownDescription: "The secret value. Must be less than 8KB. Exactly one of `value`, `value_wo` or `random_bytes` must be configured. This value is stored in Terraform state; use `value_wo` with `value_wo_version` to avoid storing secret values in state.",
 				Validators: []validator.String{
 					stringvalidator.LengthAtMost(8192), // 8KB = 8192 bytes
 				},
@@ -118,14 +147,14 @@ func (r *clusterSecretResource) Schema(ctx context.Context, req resource.SchemaR
 				Optional:            true,
 				Sensitive:           true,
 				WriteOnly:           true,
-				MarkdownDescription: "Write-only secret value. Must be less than 8KB. Exactly one of `value` or `value_wo` must be configured. This value is not stored in Terraform plan or state artifacts. Pair with `value_wo_version` to trigger secret value updates.",
+				MarkdownDescription: "Write-only secret value. Must be less than 8KB. Exactly one of `value`, `value_wo` or `random_bytes` must be configured. This value is not stored in Terraform plan or state artifacts. Pair with `value_wo_version` or `rotation_period` to trigger secret value updates.",
 				Validators: []validator.String{
 					stringvalidator.LengthAtMost(8192), // 8KB = 8192 bytes
 				},
 			},
 			"value_wo_version": schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "Non-empty, non-secret version identifier for `value_wo`. Required when `value_wo` is configured. Change this value when the write-only secret value changes, for example by using an external secret manager version ID.",
+				MarkdownDescription: "Non-empty, non-secret version identifier for `value_wo`. Required when `value_wo` is configured without `rotation_period`. Change this value when the write-only secret value changes, for example by using an external secret manager version ID. With `rotation_period`, changing it rotates the secret straight away.",
 				Validators: []validator.String{
 					stringvalidator.LengthAtLeast(1),
 				},
@@ -136,7 +165,29 @@ func (r *clusterSecretResource) Schema(ctx context.Context, req resource.SchemaR
 			},
 			"policy": schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "YAML access policy defining which pipelines and branches can access this secret.",
+				Computed:            true,
+				MarkdownDescription: "YAML access policy defining which pipelines and branches can access this secret. Conflicts with `access_policy`, which is rendered here when configured.",
+			},
+			"access_policy": clusterSecretAccessPolicySchema("secret"),
+			"rotation_period": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "How often to rotate the secret, as a duration such as `720h`. At least `1h`. The value comes from `random_bytes` or `value_wo`. Conflicts with `value`.",
+				Validators: []validator.String{
+					clusterSecretRotationPeriodValidator{},
+					stringvalidator.ConflictsWith(path.MatchRoot("value")),
+				},
+			},
+			"random_bytes": schema.Int64Attribute{
+				Optional:            true,
+				MarkdownDescription: "Generate the secret value from this many random bytes, encoded as unpadded URL-safe base64, instead of configuring `value_wo`. The generated value is never stored in Terraform plan or state artifacts. Requires `rotation_period`.",
+				Validators: []validator.Int64{
+					int64validator.Between(16, 4096),
+					int64validator.AlsoRequires(path.MatchRoot("rotation_period")),
+				},
+			},
+			"last_rotated_at": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The time the provider last rotated the secret's value, when `rotation_period` is configured. A secret with no recorded rotation, such as one just imported, is rotated on the next apply.",
 			},
 			"created_at": schema.StringAttribute{
 				Computed:            true,
@@ -156,6 +207,121 @@ func (r *clusterSecretResource) Schema(ctx context.Context, req resource.SchemaR
 	}
 }
 
+// clusterSecretAccessPolicySchema is the access_policy attribute of the cluster secret resources.
+func clusterSecretAccessPolicySchema(subject string) schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "The access policy as typed rules. A job can access the " + subject + " when it matches every claim set in at least one rule, and a claim matches any of its values. Conflicts with `policy`.",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"pipeline_slugs": schema.SetAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "The slugs of the pipelines whose jobs can access the " + subject + ".",
+				},
+				"build_branches": schema.SetAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "The branches, or branch patterns such as `release/*`, whose builds can access the " + subject + ".",
+				},
+				"build_creators": schema.SetAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "The UUIDs of the users whose builds can access the " + subject + ".",
+				},
+				"build_creator_teams": schema.SetAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "The UUIDs of the teams whose members' builds can access the " + subject + ".",
+				},
+				"build_sources": schema.SetAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "How the build was created: " + strings.Join(clusterSecretPolicyBuildSources, ", ") + ".",
+				},
+				"cluster_queue_keys": schema.SetAttribute{
+					Optional:            true,
+					ElementType:         types.StringType,
+					MarkdownDescription: "The keys of the queues whose agents can access the " + subject + ".",
+				},
+			},
+		},
+	}
+}
+
+func (r *clusterSecretResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config clusterSecretResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planClusterSecretPolicy(ctx, r.client, config.Policy, config.AccessPolicy, req, resp)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	window := DefaultTimeout
+	if r.client != nil {
+		var diags diag.Diagnostics
+		window, diags = r.client.timeouts.Update(ctx, DefaultTimeout)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_rotated_at"), planClusterSecretRotation(ctx, config, req.State, window, &resp.Diagnostics))...)
+}
+
+// planClusterSecretRotation plans last_rotated_at: unknown when the secret is due a new value, which
+// Create and Update take as the signal to generate one. A secret that falls due within window of now
+// is treated as due already, so the plan Terraform makes again at apply time, moments later, cannot
+// turn a known last_rotated_at unknown and fail the apply.
+func planClusterSecretRotation(ctx context.Context, config clusterSecretResourceModel, rawState tfsdk.State, window time.Duration, diags *diag.Diagnostics) types.String {
+	if config.RotationPeriod.IsNull() {
+		return types.StringNull()
+	}
+	if config.RotationPeriod.IsUnknown() || rawState.Raw.IsNull() {
+		return types.StringUnknown()
+	}
+
+	var state clusterSecretResourceModel
+	diags.Append(rawState.Get(ctx, &state)...)
+	if diags.HasError() {
+		return types.StringUnknown()
+	}
+
+	// The validator has already rejected periods that do not parse.
+	period, _ := time.ParseDuration(config.RotationPeriod.ValueString())
+	if clusterSecretRotationDue(state.LastRotatedAt, period, clusterSecretRotationNow().Add(window)) ||
+		!config.RandomBytes.Equal(state.RandomBytes) ||
+		!config.ValueWOVersion.Equal(state.ValueWOVersion) {
+		return types.StringUnknown()
+	}
+
+	return state.LastRotatedAt
+}
+
+// clusterSecretRotationDue reports whether a secret last rotated at lastRotatedAt needs a new value
+// at now. A secret with no recorded rotation is always due.
+func clusterSecretRotationDue(lastRotatedAt types.String, period time.Duration, now time.Time) bool {
+	if lastRotatedAt.IsNull() || lastRotatedAt.IsUnknown() {
+		return true
+	}
+
+	rotatedAt, err := time.Parse(time.RFC3339, lastRotatedAt.ValueString())
+	if err != nil {
+		return true
+	}
+
+	return !now.Before(rotatedAt.Add(period))
+}
+
 func (r *clusterSecretResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan clusterSecretResourceModel
 	diags := req.Plan.Get(ctx, &plan)
@@ -219,6 +385,11 @@ func (r *clusterSecretResource) Create(ctx context.Context, req resource.CreateR
 	plan.ID = types.StringValue(created.ID)
 	plan.CreatedAt = types.StringValue(created.CreatedAt)
 	plan.UpdatedAt = types.StringValue(created.UpdatedAt)
+	if plan.RotationPeriod.IsNull() {
+		plan.LastRotatedAt = types.StringNull()
+	} else {
+		plan.LastRotatedAt = types.StringValue(clusterSecretRotationNow().UTC().Format(time.RFC3339))
+	}
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
@@ -270,13 +441,14 @@ func (r *clusterSecretResource) Read(ctx context.Context, req resource.ReadReque
 		state.Description = types.StringValue(*secret.Description)
 	}
 
-	// Handle policy - preserve null vs empty string
+	// Handle policy - preserve null vs empty string, and the configured formatting of an
+	// equivalent policy
 	if secret.Policy == nil {
 		state.Policy = types.StringNull()
 	} else if *secret.Policy == "" && state.Policy.IsNull() {
 		state.Policy = types.StringNull()
 	} else {
-		state.Policy = types.StringValue(*secret.Policy)
+		resp.Diagnostics.Append(refreshClusterSecretPolicy(ctx, &state.Policy, &state.AccessPolicy, *secret.Policy)...)
 	}
 
 	state.CreatedAt = types.StringValue(secret.CreatedAt)
@@ -383,12 +555,20 @@ func (r *clusterSecretResource) Update(ctx context.Context, req resource.UpdateR
 		return
 	}
 
+	if shouldUpdateValue && !plan.RotationPeriod.IsNull() {
+		plan.LastRotatedAt = types.StringValue(clusterSecretRotationNow().UTC().Format(time.RFC3339))
+	}
+
 	// Preserve created_at from state, Terraform will call Read to refresh updated_at
 	plan.CreatedAt = state.CreatedAt
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
 func clusterSecretValue(plan clusterSecretResourceModel, config clusterSecretResourceModel) (string, error) {
+	if !plan.RandomBytes.IsNull() {
+		return randomClusterSecretValue(plan.RandomBytes.ValueInt64())
+	}
+
 	if !plan.ValueWOVersion.IsNull() || !config.ValueWO.IsNull() || config.ValueWO.IsUnknown() {
 		if config.ValueWO.IsNull() || config.ValueWO.IsUnknown() {
 			return "", fmt.Errorf("value_wo must be available in configuration when value_wo_version is configured")
@@ -402,7 +582,21 @@ func clusterSecretValue(plan clusterSecretResourceModel, config clusterSecretRes
 	return plan.Value.ValueString(), nil
 }
 
+// randomClusterSecretValue generates a value from n random bytes, encoded so it can be used in URLs
+// and environment variables unquoted.
+func randomClusterSecretValue(n int64) (string, error) {
+	value := make([]byte, n)
+	if _, err := rand.Read(value); err != nil {
+		return "", fmt.Errorf("unable to generate a random secret value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(value), nil
+}
+
 func shouldUpdateClusterSecretValue(plan clusterSecretResourceModel, state clusterSecretResourceModel) bool {
+	// A rotating secret gets a new value exactly when ModifyPlan found it due.
+	if !plan.RotationPeriod.IsNull() {
+		return plan.LastRotatedAt.IsUnknown()
+	}
 	if !plan.ValueWOVersion.IsNull() || !state.ValueWOVersion.IsNull() {
 		return !plan.ValueWOVersion.Equal(state.ValueWOVersion)
 	}
@@ -478,3 +672,73 @@ func (v reservedSecretKeyPrefixValidator) ValidateString(ctx context.Context, re
 		)
 	}
 }
+
+// clusterSecretRotationPeriodValidator checks rotation_period is a Go duration of at least an hour.
+type clusterSecretRotationPeriodValidator struct{}
+
+func (v clusterSecretRotationPeriodValidator) Description(ctx context.Context) string {
+	return fmt.Sprintf("value must be a duration such as 720h, of at least %s", clusterSecretMinRotationPeriod)
+}
+
+func (v clusterSecretRotationPeriodValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v clusterSecretRotationPeriodValidator) ValidateString(ctx context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	period, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid rotation period",
+			fmt.Sprintf("%q is not a duration. Use hours, for example 720h for 30 days.", req.ConfigValue.ValueString()),
+		)
+		return
+	}
+	if period < clusterSecretMinRotationPeriod {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid rotation period",
+			fmt.Sprintf("The rotation period must be at least %s, got %s.", clusterSecretMinRotationPeriod, period),
+		)
+	}
+}
+
+// clusterSecretValueVersionValidator requires value_wo_version alongside value_wo, since without it
+// Terraform cannot tell when the write-only value changes. A rotation_period takes its place, and
+// value_wo_version can also force an early rotation of a random_bytes secret.
+type clusterSecretValueVersionValidator struct{}
+
+func (v clusterSecretValueVersionValidator) Description(ctx context.Context) string {
+	return "value_wo_version must be configured with value_wo unless rotation_period is configured"
+}
+
+func (v clusterSecretValueVersionValidator) MarkdownDescription(ctx context.Context) string {
+	return "`value_wo_version` must be configured with `value_wo` unless `rotation_period` is configured"
+}
+
+func (v clusterSecretValueVersionValidator) ValidateResource(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config clusterSecretResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if config.ValueWOVersion.IsNull() && !config.ValueWO.IsNull() && config.RotationPeriod.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("value_wo_version"),
+			"Invalid Attribute Combination",
+			"value_wo_version must be configured with value_wo, or configure rotation_period to rotate the value on a schedule.",
+		)
+	}
+	if !config.ValueWOVersion.IsNull() && config.ValueWO.IsNull() && config.RandomBytes.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("value_wo_version"),
+			"Invalid Attribute Combination",
+			"value_wo_version can only be configured with value_wo or random_bytes.",
+		)
+	}
+}
@@ -7,18 +7,25 @@ import (
 	"os"
 	"regexp"
 	"testing"
+	"time"
 
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	frameworkresource "github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 func TestAccBuildkiteClusterSecret_basic(t *testing.T) {
-	secretKey := fmt.Sprintf("TEST_SECRET_%s", acctest.RandString(10))
-	secretValue := acctest.RandString(20)
-	clusterName := acctest.RandString(10)
+	secretKey := fmt.Sprintf("TEST_SECRET_%s", randString(t, 10))
+	secretValue := randString(t, 20)
+	clusterName := randString(t, 10)
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -40,10 +47,10 @@ func TestAccBuildkiteClusterSecret_basic(t *testing.T) {
 }
 
 func TestAccBuildkiteClusterSecret_update(t *testing.T) {
-	secretKey := fmt.Sprintf("TEST_SECRET_%s", acctest.RandString(10))
-	secretValue1 := acctest.RandString(20)
-	secretValue2 := acctest.RandString(20)
-	clusterName := acctest.RandString(10)
+	secretKey := fmt.Sprintf("TEST_SECRET_%s", randString(t, 10))
+	secretValue1 := randString(t, 20)
+	secretValue2 := randString(t, 20)
+	clusterName := randString(t, 10)
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -71,10 +78,10 @@ func TestAccBuildkiteClusterSecret_update(t *testing.T) {
 }
 
 func TestAccBuildkiteClusterSecret_writeOnlyValue(t *testing.T) {
-	secretKey := fmt.Sprintf("TEST_SECRET_%s", acctest.RandString(10))
-	secretValue1 := acctest.RandString(20)
-	secretValue2 := acctest.RandString(20)
-	clusterName := acctest.RandString(10)
+	secretKey := fmt.Sprintf("TEST_SECRET_%s", randString(t, 10))
+	secretValue1 := randString(t, 20)
+	secretValue2 := randString(t, 20)
+	clusterName := randString(t, 10)
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -106,10 +113,10 @@ func TestAccBuildkiteClusterSecret_writeOnlyValue(t *testing.T) {
 }
 
 func TestAccBuildkiteClusterSecret_migrateValueToWriteOnlyValue(t *testing.T) {
-	secretKey := fmt.Sprintf("TEST_SECRET_%s", acctest.RandString(10))
-	secretValue1 := acctest.RandString(20)
-	secretValue2 := acctest.RandString(20)
-	clusterName := acctest.RandString(10)
+	secretKey := fmt.Sprintf("TEST_SECRET_%s", randString(t, 10))
+	secretValue1 := randString(t, 20)
+	secretValue2 := randString(t, 20)
+	clusterName := randString(t, 10)
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -139,8 +146,8 @@ func TestAccBuildkiteClusterSecret_migrateValueToWriteOnlyValue(t *testing.T) {
 }
 
 func TestAccBuildkiteClusterSecret_valueValidation(t *testing.T) {
-	secretKey := fmt.Sprintf("TEST_SECRET_%s", acctest.RandString(10))
-	clusterName := acctest.RandString(10)
+	secretKey := fmt.Sprintf("TEST_SECRET_%s", randString(t, 10))
+	clusterName := randString(t, 10)
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -167,9 +174,9 @@ func TestAccBuildkiteClusterSecret_valueValidation(t *testing.T) {
 }
 
 func TestAccBuildkiteClusterSecret_withPolicy(t *testing.T) {
-	secretKey := fmt.Sprintf("TEST_SECRET_%s", acctest.RandString(10))
-	secretValue := acctest.RandString(20)
-	clusterName := acctest.RandString(10)
+	secretKey := fmt.Sprintf("TEST_SECRET_%s", randString(t, 10))
+	secretValue := randString(t, 20)
+	clusterName := randString(t, 10)
 
 	resource.ParallelTest(t, resource.TestCase{
 		PreCheck:                 func() { testAccPreCheck(t) },
@@ -543,6 +550,30 @@ func TestShouldUpdateClusterSecretValue(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "rotation not due",
+			plan: clusterSecretResourceModel{
+				RotationPeriod: types.StringValue("24h"),
+				LastRotatedAt:  types.StringValue("2024-01-01T00:00:00Z"),
+			},
+			state: clusterSecretResourceModel{
+				RotationPeriod: types.StringValue("24h"),
+				LastRotatedAt:  types.StringValue("2024-01-01T00:00:00Z"),
+			},
+			want: false,
+		},
+		{
+			name: "rotation due",
+			plan: clusterSecretResourceModel{
+				RotationPeriod: types.StringValue("24h"),
+				LastRotatedAt:  types.StringUnknown(),
+			},
+			state: clusterSecretResourceModel{
+				RotationPeriod: types.StringValue("24h"),
+				LastRotatedAt:  types.StringValue("2024-01-01T00:00:00Z"),
+			},
+			want: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -557,6 +588,206 @@ func TestShouldUpdateClusterSecretValue(t *testing.T) {
 	}
 }
 
+func TestClusterSecretRotationDue(t *testing.T) {
+	t.Parallel()
+
+	rotatedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		lastRotatedAt types.String
+		now           time.Time
+		want          bool
+	}{
+		{name: "within the period", lastRotatedAt: types.StringValue("2024-01-01T00:00:00Z"), now: rotatedAt.Add(23 * time.Hour), want: false},
+		{name: "period elapsed", lastRotatedAt: types.StringValue("2024-01-01T00:00:00Z"), now: rotatedAt.Add(24 * time.Hour), want: true},
+		{name: "never rotated", lastRotatedAt: types.StringNull(), now: rotatedAt, want: true},
+		{name: "unreadable timestamp", lastRotatedAt: types.StringValue("yesterday"), now: rotatedAt, want: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := clusterSecretRotationDue(tc.lastRotatedAt, 24*time.Hour, tc.now); got != tc.want {
+				t.Errorf("clusterSecretRotationDue(%s) = %t, want %t", tc.lastRotatedAt, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPlanClusterSecretRotation(t *testing.T) {
+	ctx := context.Background()
+	var schemaResp frameworkresource.SchemaResponse
+	(&clusterSecretResource{}).Schema(ctx, frameworkresource.SchemaRequest{}, &schemaResp)
+
+	rotatedAt := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	model := clusterSecretResourceModel{
+		ID: types.StringValue("secret"), ClusterID: types.StringValue("cluster"), Key: types.StringValue("SIGNING_KEY"),
+		AccessPolicy:   types.ListNull(types.ObjectType{AttrTypes: clusterSecretPolicyRuleAttrTypes}),
+		RotationPeriod: types.StringValue("24h"), RandomBytes: types.Int64Value(32),
+		LastRotatedAt: types.StringValue("2024-01-01T00:00:00Z"),
+	}
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+	if diags := state.Set(ctx, &model); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want types.String
+	}{
+		{name: "not due", now: rotatedAt.Add(23 * time.Hour), want: model.LastRotatedAt},
+		// Planned as due already, so the plan made again at apply time cannot turn it unknown.
+		{name: "due within the window", now: rotatedAt.Add(24*time.Hour - time.Minute), want: types.StringUnknown()},
+		{name: "due", now: rotatedAt.Add(24 * time.Hour), want: types.StringUnknown()},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clusterSecretRotationNow = func() time.Time { return tc.now }
+			t.Cleanup(func() { clusterSecretRotationNow = time.Now })
+
+			var diags diag.Diagnostics
+			got := planClusterSecretRotation(ctx, model, state, DefaultTimeout, &diags)
+			if diags.HasError() {
+				t.Fatal(diags)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("planned last_rotated_at %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestClusterSecretValueGeneratesRandomBytes(t *testing.T) {
+	t.Parallel()
+
+	plan := clusterSecretResourceModel{RandomBytes: types.Int64Value(32)}
+	first, err := clusterSecretValue(plan, clusterSecretResourceModel{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := clusterSecretValue(plan, clusterSecretResourceModel{})
+
+	if len(first) != 43 || first == second {
+		t.Errorf("generated %q then %q, want distinct 43 character values", first, second)
+	}
+}
+
+func TestClusterSecretRotationPeriodValidator(t *testing.T) {
+	t.Parallel()
+
+	for value, wantError := range map[string]bool{
+		"720h":  false,
+		"1h":    false,
+		"24m":   true,
+		"30d":   true,
+		"never": true,
+	} {
+		resp := &validator.StringResponse{}
+		clusterSecretRotationPeriodValidator{}.ValidateString(context.Background(), validator.StringRequest{ConfigValue: types.StringValue(value)}, resp)
+		if resp.Diagnostics.HasError() != wantError {
+			t.Errorf("ValidateString(%q) diagnostics = %v, want error %t", value, resp.Diagnostics, wantError)
+		}
+	}
+}
+
+func TestUnitBuildkiteClusterSecretRotation(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	clusterSecretRotationNow = func() time.Time { return now }
+	t.Cleanup(func() { clusterSecretRotationNow = time.Now })
+
+	server := fakebuildkite.New(t, "test-org")
+	config := fakeProviderConfig(server) + `
+		resource "buildkite_cluster" "cluster" {
+			name = "cluster"
+		}
+
+		resource "buildkite_cluster_secret" "signing_key" {
+			cluster_id      = buildkite_cluster.cluster.uuid
+			key             = "SIGNING_KEY"
+			random_bytes    = 32
+			rotation_period = "24h"
+		}
+	`
+
+	var values []string
+	recordValue := func(*terraform.State) error {
+		server.Update(func(st *fakebuildkite.State) {
+			cluster := st.Nodes("Cluster", nil)[0]
+			collection := fmt.Sprintf("/v2/organizations/test-org/clusters/%s/secrets", cluster["uuid"])
+			for _, secret := range st.Objects(collection) {
+				value, _ := st.SecretValue(collection + "/" + secret["id"].(string))
+				values = append(values, value)
+			}
+		})
+		if len(values[len(values)-1]) != 43 {
+			return fmt.Errorf("secret value %q is not 32 random bytes", values[len(values)-1])
+		}
+		return nil
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_secret.signing_key", "last_rotated_at", "2024-01-01T00:00:00Z"),
+					resource.TestCheckNoResourceAttr("buildkite_cluster_secret.signing_key", "value"),
+					recordValue,
+				),
+			},
+			{
+				PreConfig: func() { now = now.Add(23 * time.Hour) },
+				Config:    config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_cluster_secret.signing_key", plancheck.ResourceActionNoop),
+					},
+				},
+			},
+			{
+				PreConfig: func() { now = now.Add(time.Hour) },
+				Config:    config,
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_cluster_secret.signing_key", plancheck.ResourceActionUpdate),
+						plancheck.ExpectUnknownValue("buildkite_cluster_secret.signing_key", tfjsonpath.New("last_rotated_at")),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_secret.signing_key", "last_rotated_at", "2024-01-02T00:00:00Z"),
+					recordValue,
+					func(*terraform.State) error {
+						if values[0] == values[1] {
+							return fmt.Errorf("the secret was not given a new value")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: fakeProviderConfig(server) + `
+					resource "buildkite_cluster" "cluster" {
+						name = "cluster"
+					}
+
+					resource "buildkite_cluster_secret" "signing_key" {
+						cluster_id      = buildkite_cluster.cluster.uuid
+						key             = "SIGNING_KEY"
+						random_bytes    = 32
+						rotation_period = "24m"
+					}
+				`,
+				ExpectError: regexp.MustCompile("The rotation period must be at least 1h0m0s"),
+			},
+		},
+	})
+}
+
 // Unit tests for reservedSecretKeyPrefixValidator — no API access required.
 
 func TestReservedSecretKeyPrefixValidator(t *testing.T) {
@@ -0,0 +1,580 @@
+package buildkite
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework-validators/mapvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+// clusterSecretsConcurrency bounds the secrets created, updated or deleted at once. Requests that
+// still hit the rate limit are retried by the client once it resets.
+const clusterSecretsConcurrency = 8
+
+var clusterSecretsEntryAttrTypes = map[string]attr.Type{
+	"value_wo":         types.StringType,
+	"value_wo_version": types.StringType,
+	"id":               types.StringType,
+}
+
+type clusterSecretsResource struct {
+	client *Client
+}
+
+type clusterSecretsResourceModel struct {
+	ID           types.String `tfsdk:"id"`
+	ClusterID    types.String `tfsdk:"cluster_id"`
+	Description  types.String `tfsdk:"description"`
+	Policy       types.String `tfsdk:"policy"`
+	AccessPolicy types.List   `tfsdk:"access_policy"`
+	Secrets      types.Map    `tfsdk:"secrets"`
+}
+
+type clusterSecretsEntryModel struct {
+	ValueWO        types.String `tfsdk:"value_wo"`
+	ValueWOVersion types.String `tfsdk:"value_wo_version"`
+	ID             types.String `tfsdk:"id"`
+}
+
+func newClusterSecretsResource() resource.Resource {
+	return &clusterSecretsResource{}
+}
+
+func (r *clusterSecretsResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_cluster_secrets"
+}
+
+func (r *clusterSecretsResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	r.client = req.ProviderData.(*Client)
+}
+
+func (r *clusterSecretsResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(
+			path.MatchRoot("policy"),
+			path.MatchRoot("access_policy"),
+		),
+	}
+}
+
+func (r *clusterSecretsResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		MarkdownDescription: heredoc.Doc(`
+			Manages a set of Cluster Secrets that share a description and access policy, from a map of
+			secret key to write-only value. Use it to manage many secrets without a resource block, and a
+			sequential API call, for each one.
+
+			Secrets are created, updated and deleted concurrently. A secret that fails does not stop the
+			others: the failures are reported per key, and the secrets that succeeded are saved in state so
+			the next apply only retries the failed ones. When secrets fail while the resource is first
+			created, Terraform marks it as tainted, and the next apply deletes and recreates all of them.
+			Secrets in the cluster that are not in the map are left alone.
+
+			Secret values are write-only and are never stored in Terraform plan or state artifacts. Change a
+			secret's value_wo_version to send its value again.
+		`),
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The UUID of the cluster the secrets belong to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"cluster_id": schema.StringAttribute{
+				Required:            true,
+				MarkdownDescription: "The UUID of the cluster the secrets belong to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "A description shared by every secret in the set.",
+			},
+			"policy": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "YAML access policy shared by every secret in the set. Conflicts with `access_policy`, which is rendered here when configured.",
+			},
+			"access_policy": clusterSecretAccessPolicySchema("secrets"),
+			"secrets": schema.MapNestedAttribute{
+				Required:            true,
+				MarkdownDescription: "The secrets to manage, keyed by secret key. Keys follow the same rules as `buildkite_cluster_secret`'s `key`.",
+				Validators: []validator.Map{
+					mapvalidator.KeysAre(
+						stringvalidator.LengthAtMost(255),
+						stringvalidator.RegexMatches(
+							regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]*$`),
+							"must start with a letter and only contain letters, numbers, and underscores",
+						),
+						reservedSecretKeyPrefixValidator{},
+					),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"value_wo": schema.StringAttribute{
+							Required:            true,
+							Sensitive:           true,
+							WriteOnly:           true,
+							MarkdownDescription: "Write-only secret value. Must be less than 8KB. This value is not stored in Terraform plan or state artifacts.",
+							Validators: []validator.String{
+								stringvalidator.LengthAtMost(8192), // 8KB = 8192 bytes
+							},
+						},
+						"value_wo_version": schema.StringAttribute{
+							Required:            true,
+							MarkdownDescription: "Non-empty, non-secret version identifier for `value_wo`. Change it when the secret value changes.",
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"id": schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The UUID of the secret.",
+							PlanModifiers: []planmodifier.String{
+								stringplanmodifier.UseStateForUnknown(),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *clusterSecretsResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config clusterSecretsResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planClusterSecretPolicy(ctx, r.client, config.Policy, config.AccessPolicy, req, resp)
+}
+
+func (r *clusterSecretsResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan, config clusterSecretsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	// Write-only attributes are only available from config, not plan or state.
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := r.client.timeouts.Create(ctx, DefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planned, values, diags := clusterSecretsEntries(ctx, plan, config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	keys := sortedKeys(planned)
+	created, errs := forEachClusterSecret(ctx, keys, func(key string) (*ClusterSecret, error) {
+		return r.createSecret(ctx, timeout, plan, key, values[key])
+	})
+
+	state := map[string]clusterSecretsEntryModel{}
+	for key, secret := range created {
+		state[key] = clusterSecretsEntryModel{
+			ValueWO:        types.StringNull(),
+			ValueWOVersion: planned[key].ValueWOVersion,
+			ID:             types.StringValue(secret.ID),
+		}
+	}
+
+	addClusterSecretsErrors(&resp.Diagnostics, "Unable to create cluster secret", errs)
+	if len(created) == 0 {
+		return
+	}
+
+	// Keep the secrets that were created in state, so they are deleted rather than left behind
+	// when Terraform replaces the tainted resource.
+	plan.ID = plan.ClusterID
+	plan.Secrets, diags = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: clusterSecretsEntryAttrTypes}, state)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *clusterSecretsResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state clusterSecretsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := r.client.timeouts.Read(ctx, DefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var secrets []ClusterSecret
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		var err error
+		secrets, err = r.client.ListClusterSecrets(ctx, r.client.organization, state.ClusterID.ValueString())
+		return retryContextError(err)
+	})
+	if err != nil {
+		if isAPIStatus(err, http.StatusNotFound) {
+			resp.State.RemoveResource(ctx)
+			return
+		}
+		resp.Diagnostics.AddError(
+			"Unable to read cluster secrets",
+			fmt.Sprintf("Unable to read cluster secrets: %s", err.Error()),
+		)
+		return
+	}
+
+	byKey := make(map[string]ClusterSecret, len(secrets))
+	for _, secret := range secrets {
+		byKey[secret.Key] = secret
+	}
+
+	// An imported set adopts every secret in the cluster; otherwise only the secrets in state are
+	// managed, and those deleted outside Terraform are removed so the next apply recreates them.
+	entries := map[string]clusterSecretsEntryModel{}
+	imported := state.Secrets.IsNull()
+	if !imported {
+		resp.Diagnostics.Append(state.Secrets.ElementsAs(ctx, &entries, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	refreshed := map[string]clusterSecretsEntryModel{}
+	for _, key := range sortedKeys(byKey) {
+		entry, managed := entries[key]
+		if !managed && !imported {
+			continue
+		}
+		secret := byKey[key]
+		if managed && entry.ID.ValueString() != secret.ID {
+			// The secret was deleted and recreated outside Terraform, so its value is not ours.
+			continue
+		}
+		if !managed {
+			entry.ValueWOVersion = types.StringNull()
+		}
+		entry.ValueWO = types.StringNull()
+		entry.ID = types.StringValue(secret.ID)
+		refreshed[key] = entry
+
+		// The secrets share one description and policy, so a secret that differs is drift for the
+		// whole set and the next apply brings every secret back in line.
+		description := types.StringNull()
+		if secret.Description != nil && *secret.Description != "" {
+			description = types.StringValue(*secret.Description)
+		}
+		if imported && len(refreshed) == 1 || !description.Equal(state.Description) {
+			state.Description = description
+		}
+		switch {
+		case secret.Policy == nil || *secret.Policy == "":
+			if imported && len(refreshed) == 1 || !state.Policy.IsNull() {
+				state.Policy = types.StringNull()
+			}
+		default:
+			resp.Diagnostics.Append(refreshClusterSecretPolicy(ctx, &state.Policy, &state.AccessPolicy, *secret.Policy)...)
+		}
+	}
+
+	state.ID = state.ClusterID
+	state.Secrets, diags = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: clusterSecretsEntryAttrTypes}, refreshed)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *clusterSecretsResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, config, state clusterSecretsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	// Write-only attributes are only available from config, not plan or state.
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := r.client.timeouts.Update(ctx, DefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planned, values, diags := clusterSecretsEntries(ctx, plan, config)
+	resp.Diagnostics.Append(diags...)
+	current := map[string]clusterSecretsEntryModel{}
+	resp.Diagnostics.Append(state.Secrets.ElementsAs(ctx, &current, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	metadataChanged := !plan.Description.Equal(state.Description) || !plan.Policy.Equal(state.Policy)
+	var keys []string
+	for _, key := range sortedKeys(planned) {
+		existing, ok := current[key]
+		if !ok || metadataChanged || !planned[key].ValueWOVersion.Equal(existing.ValueWOVersion) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range sortedKeys(current) {
+		if _, ok := planned[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	results, errs := forEachClusterSecret(ctx, keys, func(key string) (*ClusterSecret, error) {
+		existing, exists := current[key]
+		entry, wanted := planned[key]
+		switch {
+		case !wanted:
+			return nil, r.deleteSecret(ctx, timeout, plan.ClusterID.ValueString(), existing.ID.ValueString())
+		case !exists:
+			return r.createSecret(ctx, timeout, plan, key, values[key])
+		default:
+			return r.updateSecret(ctx, timeout, plan, state, existing.ID.ValueString(), !entry.ValueWOVersion.Equal(existing.ValueWOVersion), values[key])
+		}
+	})
+
+	// Start from what was there before and record each change that succeeded, so a failed secret
+	// keeps its previous state and the next apply retries it.
+	for _, key := range keys {
+		if _, failed := errs[key]; failed {
+			continue
+		}
+		entry, wanted := planned[key]
+		if !wanted {
+			delete(current, key)
+			continue
+		}
+		id := current[key].ID
+		if secret := results[key]; secret != nil && secret.ID != "" {
+			id = types.StringValue(secret.ID)
+		}
+		current[key] = clusterSecretsEntryModel{
+			ValueWO:        types.StringNull(),
+			ValueWOVersion: entry.ValueWOVersion,
+			ID:             id,
+		}
+	}
+	addClusterSecretsErrors(&resp.Diagnostics, "Unable to update cluster secret", errs)
+
+	plan.ID = plan.ClusterID
+	plan.Secrets, diags = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: clusterSecretsEntryAttrTypes}, current)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *clusterSecretsResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state clusterSecretsResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	timeout, diags := r.client.timeouts.Delete(ctx, DefaultTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	current := map[string]clusterSecretsEntryModel{}
+	resp.Diagnostics.Append(state.Secrets.ElementsAs(ctx, &current, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, errs := forEachClusterSecret(ctx, sortedKeys(current), func(key string) (*ClusterSecret, error) {
+		return nil, r.deleteSecret(ctx, timeout, state.ClusterID.ValueString(), current[key].ID.ValueString())
+	})
+	if len(errs) == 0 {
+		return
+	}
+
+	// Keep the secrets that could not be deleted so destroying again retries them.
+	for key := range current {
+		if _, failed := errs[key]; !failed {
+			delete(current, key)
+		}
+	}
+	addClusterSecretsErrors(&resp.Diagnostics, "Unable to delete cluster secret", errs)
+	state.Secrets, diags = types.MapValueFrom(ctx, types.ObjectType{AttrTypes: clusterSecretsEntryAttrTypes}, current)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *clusterSecretsResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("cluster_id"), req.ID)...)
+}
+
+func (r *clusterSecretsResource) createSecret(ctx context.Context, timeout time.Duration, plan clusterSecretsResourceModel, key, value string) (*ClusterSecret, error) {
+	secret := &ClusterSecret{
+		Key:         key,
+		Value:       value,
+		Description: plan.Description.ValueStringPointer(),
+		Policy:      plan.Policy.ValueStringPointer(),
+	}
+
+	var created *ClusterSecret
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		var err error
+		created, err = r.client.CreateClusterSecret(ctx, r.client.organization, plan.ClusterID.ValueString(), secret)
+		return retryContextError(err)
+	})
+	return created, err
+}
+
+func (r *clusterSecretsResource) updateSecret(ctx context.Context, timeout time.Duration, plan, state clusterSecretsResourceModel, id string, updateValue bool, value string) (*ClusterSecret, error) {
+	updates := map[string]string{}
+	if !plan.Description.Equal(state.Description) {
+		updates["description"] = plan.Description.ValueString()
+	}
+	if !plan.Policy.Equal(state.Policy) {
+		updates["policy"] = plan.Policy.ValueString()
+	}
+
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		if updateValue {
+			if _, err := r.client.UpdateClusterSecretValue(ctx, r.client.organization, plan.ClusterID.ValueString(), id, value); err != nil {
+				return retryContextError(err)
+			}
+		}
+		if len(updates) > 0 {
+			if _, err := r.client.UpdateClusterSecret(ctx, r.client.organization, plan.ClusterID.ValueString(), id, updates); err != nil {
+				return retryContextError(err)
+			}
+		}
+		return nil
+	})
+	return nil, err
+}
+
+func (r *clusterSecretsResource) deleteSecret(ctx context.Context, timeout time.Duration, clusterID, id string) error {
+	return retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		err := r.client.DeleteClusterSecret(ctx, r.client.organization, clusterID, id)
+		if isAPIStatus(err, http.StatusNotFound) {
+			return nil
+		}
+		return retryContextError(err)
+	})
+}
+
+// clusterSecretsEntries returns the planned secrets and their write-only values from config.
+func clusterSecretsEntries(ctx context.Context, plan, config clusterSecretsResourceModel) (map[string]clusterSecretsEntryModel, map[string]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	planned := map[string]clusterSecretsEntryModel{}
+	configured := map[string]clusterSecretsEntryModel{}
+	diags.Append(plan.Secrets.ElementsAs(ctx, &planned, false)...)
+	diags.Append(config.Secrets.ElementsAs(ctx, &configured, false)...)
+
+	values := make(map[string]string, len(configured))
+	for key, entry := range configured {
+		if entry.ValueWO.IsNull() || entry.ValueWO.IsUnknown() {
+			diags.AddAttributeError(
+				path.Root("secrets").AtMapKey(key).AtName("value_wo"),
+				"Unable to determine cluster secret value",
+				"value_wo must be available in configuration.",
+			)
+			continue
+		}
+		values[key] = entry.ValueWO.ValueString()
+	}
+	return planned, values, diags
+}
+
+// forEachClusterSecret calls fn for each key, with at most clusterSecretsConcurrency calls running
+// at once, and returns the secrets and errors they returned by key. Once ctx is done no more calls
+// are started, and the keys left over fail with its error.
+func forEachClusterSecret(ctx context.Context, keys []string, fn func(key string) (*ClusterSecret, error)) (map[string]*ClusterSecret, map[string]error) {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := map[string]*ClusterSecret{}
+	errs := map[string]error{}
+	slots := make(chan struct{}, clusterSecretsConcurrency)
+
+	for _, key := range keys {
+		if ctx.Err() == nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			mu.Lock()
+			errs[key] = err
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			secret, err := fn(key)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[key] = err
+				return
+			}
+			if secret != nil {
+				results[key] = secret
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results, errs
+}
+
+func addClusterSecretsErrors(diags *diag.Diagnostics, summary string, errs map[string]error) {
+	for _, key := range sortedKeys(errs) {
+		diags.AddAttributeError(
+			path.Root("secrets").AtMapKey(key),
+			summary,
+			fmt.Sprintf("%s: %s", key, errs[key]),
+		)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,252 @@
+package buildkite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+)
+
+func TestForEachClusterSecret(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	keys := make([]string, 3*clusterSecretsConcurrency)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("SECRET_%d", i)
+	}
+
+	results, errs := forEachClusterSecret(t.Context(), keys, func(key string) (*ClusterSecret, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			seen := peak.Load()
+			if now <= seen || peak.CompareAndSwap(seen, now) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		if key == "SECRET_3" {
+			return nil, fmt.Errorf("rejected")
+		}
+		return &ClusterSecret{ID: "id-" + key, Key: key}, nil
+	})
+
+	if got := peak.Load(); got > clusterSecretsConcurrency {
+		t.Errorf("ran %d calls at once, want at most %d", got, clusterSecretsConcurrency)
+	}
+	if len(errs) != 1 || errs["SECRET_3"] == nil {
+		t.Errorf("errs = %v, want only SECRET_3 to fail", errs)
+	}
+	if len(results) != len(keys)-1 || results["SECRET_0"].ID != "id-SECRET_0" {
+		t.Errorf("got %d results, want %d", len(results), len(keys)-1)
+	}
+}
+
+func TestForEachClusterSecretStopsWhenCancelled(t *testing.T) {
+	t.Parallel()
+
+	keys := make([]string, 3*clusterSecretsConcurrency)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("SECRET_%d", i)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	var calls atomic.Int32
+	results, errs := forEachClusterSecret(ctx, keys, func(key string) (*ClusterSecret, error) {
+		calls.Add(1)
+		cancel()
+		return &ClusterSecret{ID: "id-" + key, Key: key}, nil
+	})
+
+	if got := int(calls.Load()); got > clusterSecretsConcurrency {
+		t.Errorf("made %d calls after the context was cancelled, want at most %d", got, clusterSecretsConcurrency)
+	}
+	if len(results)+len(errs) != len(keys) {
+		t.Errorf("got %d results and %d errors, want one for each of %d keys", len(results), len(errs), len(keys))
+	}
+	for key, err := range errs {
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("errs[%s] = %v, want context.Canceled", key, err)
+		}
+	}
+}
+
+func TestUnitBuildkiteClusterSecrets(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	config := func(description string, secrets map[string]string) string {
+		entries := ""
+		for key, version := range secrets {
+			entries += fmt.Sprintf("%s = { value_wo = \"value-%s-%s\", value_wo_version = %q }\n", key, key, version, version)
+		}
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_cluster" "cluster" {
+				name = "cluster"
+			}
+
+			resource "buildkite_cluster_secrets" "vault" {
+				cluster_id  = buildkite_cluster.cluster.uuid
+				description = %q
+				access_policy = [{ build_branches = ["main"] }]
+				secrets = {
+					%s
+				}
+			}
+		`, description, entries)
+	}
+
+	secretValue := func(key string) (value, description, policy string) {
+		server.Update(func(st *fakebuildkite.State) {
+			cluster := st.Nodes("Cluster", nil)[0]
+			collection := fmt.Sprintf("/v2/organizations/test-org/clusters/%s/secrets", cluster["uuid"])
+			for _, secret := range st.Objects(collection) {
+				if secret["key"] == key {
+					value, _ = st.SecretValue(collection + "/" + secret["id"].(string))
+					description, _ = secret["description"].(string)
+					policy, _ = secret["policy"].(string)
+				}
+			}
+		})
+		return value, description, policy
+	}
+	checkSecret := func(key, wantValue, wantDescription string) resource.TestCheckFunc {
+		return func(*terraform.State) error {
+			value, description, policy := secretValue(key)
+			if value != wantValue || description != wantDescription || policy != "- build_branch: main\n" {
+				return fmt.Errorf("secret %s = %q, %q, %q, want %q, %q with the shared policy", key, value, description, policy, wantValue, wantDescription)
+			}
+			return nil
+		}
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config("from vault", map[string]string{"API_TOKEN": "1", "DB_PASSWORD": "1", "SIGNING_KEY": "1"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_secrets.vault", "secrets.%", "3"),
+					resource.TestCheckResourceAttrSet("buildkite_cluster_secrets.vault", "secrets.API_TOKEN.id"),
+					resource.TestCheckNoResourceAttr("buildkite_cluster_secrets.vault", "secrets.API_TOKEN.value_wo"),
+					checkSecret("API_TOKEN", "value-API_TOKEN-1", "from vault"),
+					checkSecret("SIGNING_KEY", "value-SIGNING_KEY-1", "from vault"),
+				),
+			},
+			{
+				// Rotate one secret, drop another, add a third and change the shared description.
+				Config: config("rotated from vault", map[string]string{"API_TOKEN": "2", "DB_PASSWORD": "1", "NPM_TOKEN": "1"}),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_secrets.vault", "secrets.%", "3"),
+					resource.TestCheckNoResourceAttr("buildkite_cluster_secrets.vault", "secrets.SIGNING_KEY.id"),
+					checkSecret("API_TOKEN", "value-API_TOKEN-2", "rotated from vault"),
+					checkSecret("DB_PASSWORD", "value-DB_PASSWORD-1", "rotated from vault"),
+					checkSecret("NPM_TOKEN", "value-NPM_TOKEN-1", "rotated from vault"),
+					func(*terraform.State) error {
+						if value, _, _ := secretValue("SIGNING_KEY"); value != "" {
+							return fmt.Errorf("SIGNING_KEY was not deleted")
+						}
+						return nil
+					},
+				),
+			},
+			{
+				// A secret the API rejects is reported without holding back the others.
+				PreConfig: func() {
+					server.HandleREST(http.MethodPut, "/v2/organizations/{org}/clusters/{cluster}/secrets/{id}/value", func(st *fakebuildkite.State, req *fakebuildkite.RESTRequest) (int, interface{}) {
+						if req.Body["value"] == "value-DB_PASSWORD-2" {
+							return http.StatusUnprocessableEntity, fakebuildkite.Object{"message": "Value is too long"}
+						}
+						secretPath := req.Path[:len(req.Path)-len("/value")]
+						secret, _ := st.Object(secretPath)
+						return http.StatusOK, secret
+					})
+				},
+				Config:      config("rotated from vault", map[string]string{"API_TOKEN": "3", "DB_PASSWORD": "2", "NPM_TOKEN": "1"}),
+				ExpectError: regexp.MustCompile(`(?s)Unable to update cluster secret.*DB_PASSWORD: .*Value is too long`),
+			},
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster_secrets.vault", "secrets.API_TOKEN.value_wo_version", "3"),
+					resource.TestCheckResourceAttr("buildkite_cluster_secrets.vault", "secrets.DB_PASSWORD.value_wo_version", "1"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
+
+func TestUnitBuildkiteClusterSecretsPartialCreate(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	cluster := fakeProviderConfig(server) + `
+		resource "buildkite_cluster" "cluster" {
+			name = "cluster"
+		}
+	`
+	secrets := cluster + `
+		resource "buildkite_cluster_secrets" "vault" {
+			cluster_id = buildkite_cluster.cluster.uuid
+			secrets = {
+				API_TOKEN   = { value_wo = "token", value_wo_version = "1" }
+				DB_PASSWORD = { value_wo = "password", value_wo_version = "1" }
+			}
+		}
+	`
+	secretKeys := func() []string {
+		var keys []string
+		server.Update(func(st *fakebuildkite.State) {
+			collection := fmt.Sprintf("/v2/organizations/test-org/clusters/%s/secrets", st.Nodes("Cluster", nil)[0]["uuid"])
+			for _, secret := range st.Objects(collection) {
+				keys = append(keys, secret["key"].(string))
+			}
+		})
+		slices.Sort(keys)
+		return keys
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if keys := secretKeys(); !slices.Equal(keys, []string{"DB_PASSWORD"}) {
+				return fmt.Errorf("secrets left after destroy = %v, want only the one Terraform did not create", keys)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: cluster,
+			},
+			{
+				// A secret outside Terraform already has one of the keys, so only the other is created
+				PreConfig: func() {
+					server.Update(func(st *fakebuildkite.State) {
+						collection := fmt.Sprintf("/v2/organizations/test-org/clusters/%s/secrets", st.Nodes("Cluster", nil)[0]["uuid"])
+						st.PutObject(collection+"/existing", fakebuildkite.Object{"id": "existing", "key": "DB_PASSWORD"})
+					})
+				},
+				Config:      secrets,
+				ExpectError: regexp.MustCompile(`(?s)Unable to create cluster secret.*DB_PASSWORD: .*Key has already been taken`),
+			},
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttrSet("buildkite_cluster_secrets.vault", "secrets.API_TOKEN.id"),
+					resource.TestCheckNoResourceAttr("buildkite_cluster_secrets.vault", "secrets.DB_PASSWORD.id"),
+				),
+				ExpectNonEmptyPlan: true,
+			},
+		},
+	})
+}
@@ -5,7 +5,7 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -50,7 +50,7 @@ func TestAccBuildkiteClusterResource(t *testing.T) {
 
 	t.Run("Creates a Cluster with basic settings", func(t *testing.T) {
 		var c clusterResourceModel
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 		check := resource.ComposeAggregateTestCheckFunc(
 			testAccCheckClusterExists("buildkite_cluster.foo", &c),
 			testAccCheckClusterRemoteValues(&c, fmt.Sprintf("%s_test_cluster", randName)),
@@ -74,7 +74,7 @@ func TestAccBuildkiteClusterResource(t *testing.T) {
 
 	t.Run("Creates a Cluster with complex settings", func(t *testing.T) {
 		var c clusterResourceModel
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 		check := resource.ComposeAggregateTestCheckFunc(
 			testAccCheckClusterExists("buildkite_cluster.foo", &c),
 			testAccCheckClusterRemoteValues(&c, fmt.Sprintf("%s_test_cluster", randName)),
@@ -98,8 +98,8 @@ func TestAccBuildkiteClusterResource(t *testing.T) {
 
 	t.Run("Updates a Cluster using complex settings", func(t *testing.T) {
 		var c clusterResourceModel
-		randName := acctest.RandString(5)
-		randNameUpdated := acctest.RandString(5)
+		randName := randString(t, 5)
+		randNameUpdated := randString(t, 5)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -136,7 +136,7 @@ func TestAccBuildkiteClusterResource(t *testing.T) {
 
 	t.Run("Imports a Cluster", func(t *testing.T) {
 		var c clusterResourceModel
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 		check := resource.ComposeAggregateTestCheckFunc(
 			testAccCheckClusterExists("buildkite_cluster.foo", &c),
 			resource.TestCheckResourceAttr("buildkite_cluster.foo", "name", fmt.Sprintf("%s_test_cluster", randName)),
@@ -204,3 +204,64 @@ func testAccCheckClusterDestroy(s *terraform.State) error {
 	}
 	return nil
 }
+
+func TestUnitBuildkiteClusterResource(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	config := func(description string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_cluster" "foo" {
+				name        = "cluster"
+				description = %q
+			}
+		`, description)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			var remaining int
+			server.Update(func(state *fakebuildkite.State) {
+				remaining = len(state.Nodes("Cluster", nil))
+			})
+			if remaining != 0 {
+				return fmt.Errorf("%d clusters were not deleted", remaining)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config("first"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_cluster.foo", "description", "first"),
+					resource.TestCheckResourceAttrSet("buildkite_cluster.foo", "uuid"),
+				),
+			},
+			{
+				Config: config("second"),
+				Check:  resource.TestCheckResourceAttr("buildkite_cluster.foo", "description", "second"),
+			},
+			{
+				ResourceName:      "buildkite_cluster.foo",
+				ImportState:       true,
+				ImportStateVerify: true,
+			},
+			{
+				// A change made outside Terraform shows up as drift.
+				PreConfig: func() {
+					server.Update(func(state *fakebuildkite.State) {
+						for _, cluster := range state.Nodes("Cluster", nil) {
+							cluster["description"] = "changed in the UI"
+						}
+					})
+				},
+				Config:             config("second"),
+				PlanOnly:           true,
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				Config: config("second"),
+				Check:  resource.TestCheckResourceAttr("buildkite_cluster.foo", "description", "second"),
+			},
+		},
+	})
+}
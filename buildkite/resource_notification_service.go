@@ -32,8 +32,17 @@ const (
 	notificationServiceProviderOpenTelemetryTracing      = "open_telemetry_tracing"
 	notificationServiceProviderLinear                    = "linear"
 	notificationServiceProviderSlackWorkspace            = "slack_workspace"
+	notificationServiceProviderSlack                     = "slack"
 )
 
+// notificationServiceDeliverySucceeded is the last_delivery_status of a service whose most recent
+// delivery was accepted by its destination.
+const notificationServiceDeliverySucceeded = "success"
+
+// notificationServiceBuildStates are the build states a legacy Slack service can notify about. The
+// API takes them as build_<state> flags.
+var notificationServiceBuildStates = []string{"scheduled", "running", "passed", "failed", "blocked", "canceled"}
+
 var notificationServiceUUIDRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
 type notificationServiceResource struct {
@@ -53,6 +62,10 @@ type notificationServiceResourceModel struct {
 	AWSEventBridge            *notificationServiceAWSEventBridgeModel            `tfsdk:"aws_event_bridge"`
 	DatadogPipelineVisibility *notificationServiceDatadogPipelineVisibilityModel `tfsdk:"datadog_pipeline_visibility"`
 	OpenTelemetryTracing      *notificationServiceOpenTelemetryTracingModel      `tfsdk:"open_telemetry_tracing"`
+	Slack                     *notificationServiceSlackModel                     `tfsdk:"slack"`
+	TestDelivery              types.Bool                                         `tfsdk:"test_delivery"`
+	LastDeliveryStatus        types.String                                       `tfsdk:"last_delivery_status"`
+	LastError                 types.String                                       `tfsdk:"last_error"`
 	CreatedAt                 types.String                                       `tfsdk:"created_at"`
 }
 
@@ -85,6 +98,13 @@ type notificationServiceOpenTelemetryTracingModel struct {
 	ResourceAttributes types.Map    `tfsdk:"resource_attributes"`
 }
 
+type notificationServiceSlackModel struct {
+	WebhookURL      types.String `tfsdk:"webhook_url"`
+	Channel         types.String `tfsdk:"channel"`
+	MessageTemplate types.String `tfsdk:"message_template"`
+	BuildStates     types.Set    `tfsdk:"build_states"`
+}
+
 type notificationServiceAPIResponse struct {
 	ID        string  `json:"id"`
 	GraphQLID *string `json:"graphql_id"`
@@ -97,6 +117,9 @@ type notificationServiceAPIResponse struct {
 	ScopeUUIDs          []string        `json:"scope_uuids"`
 	BranchConfiguration string          `json:"branch_configuration"`
 	Settings            json.RawMessage `json:"settings"`
+	BuildStates         map[string]bool `json:"build_states"`
+	LastDeliveryStatus  *string         `json:"last_delivery_status"`
+	LastError           *string         `json:"last_error"`
 	CreatedAt           string          `json:"created_at"`
 }
 
@@ -127,6 +150,12 @@ type notificationServiceOpenTelemetryTracingAPISettings struct {
 	ResourceAttributes map[string]string `json:"resource_attributes"`
 }
 
+type notificationServiceSlackAPISettings struct {
+	WebhookURL      *string `json:"webhook_url"`
+	Channel         *string `json:"channel"`
+	MessageTemplate *string `json:"message_template"`
+}
+
 var (
 	_ resource.Resource                = (*notificationServiceResource)(nil)
 	_ resource.ResourceWithConfigure   = (*notificationServiceResource)(nil)
@@ -159,7 +188,7 @@ func (r *notificationServiceResource) Schema(ctx context.Context, req resource.S
 			REST API scopes. Use provider_type rather than provider, which is a reserved
 			Terraform meta-argument.
 
-			Webhook and Slack Workspace notification services are available on every plan.
+			Webhook, Slack Workspace, and legacy Slack notification services are available on every plan.
 			New Amazon EventBridge, Datadog Pipeline Visibility, OpenTelemetry control-plane
 			tracing, and Linear notification services require Enterprise entitlements. Existing
 			organizations may retain access based on their entitlements. Terraform cannot check
@@ -169,8 +198,15 @@ func (r *notificationServiceResource) Schema(ctx context.Context, req resource.S
 			Secret settings that the Buildkite API masks or omits are preserved from Terraform
 			state during refresh. Changes made to those values outside Terraform cannot be detected.
 
-			This resource does not manage build state selection. Every supported provider ignores
-			those API fields; only legacy Slack services, which this resource does not support, use them.
+			Build state selection is only managed for legacy Slack services, through
+			slack.build_states. Every other provider ignores those API fields.
+
+			Set test_delivery to send a test delivery after every create and update and fail the
+			apply when it is not delivered. last_delivery_status and last_error report the most
+			recent delivery, so check blocks can alert on broken integrations.
+
+			Buildkite has no PagerDuty or email notification services. Send PagerDuty change
+			events and emails with the notify attribute of a pipeline's steps instead.
 		`),
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
@@ -186,7 +222,7 @@ func (r *notificationServiceResource) Schema(ctx context.Context, req resource.S
 			},
 			"provider_type": schema.StringAttribute{
 				Required:            true,
-				MarkdownDescription: "The notification provider type. OAuth-managed `linear` and `slack_workspace` services can be imported but cannot be created with this resource. Legacy `slack` services are not supported.",
+				MarkdownDescription: "The notification provider type. OAuth-managed `linear` and `slack_workspace` services can be imported but cannot be created with this resource. Legacy `slack` services post to a Slack incoming webhook.",
 				Validators: []validator.String{
 					stringvalidator.OneOf(
 						notificationServiceProviderWebhook,
@@ -195,6 +231,7 @@ func (r *notificationServiceResource) Schema(ctx context.Context, req resource.S
 						notificationServiceProviderOpenTelemetryTracing,
 						notificationServiceProviderLinear,
 						notificationServiceProviderSlackWorkspace,
+						notificationServiceProviderSlack,
 					),
 				},
 				PlanModifiers: []planmodifier.String{
@@ -238,6 +275,27 @@ func (r *notificationServiceResource) Schema(ctx context.Context, req resource.S
 			"aws_event_bridge":            notificationServiceAWSEventBridgeSchema(),
 			"datadog_pipeline_visibility": notificationServiceDatadogPipelineVisibilitySchema(),
 			"open_telemetry_tracing":      notificationServiceOpenTelemetryTracingSchema(),
+			"slack":                       notificationServiceSlackSchema(),
+			"test_delivery": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "Whether to send a test delivery after the service is created or updated, failing the apply if it is not delivered. Requires an enabled service. Defaults to false.",
+				Default:             booldefault.StaticBool(false),
+			},
+			"last_delivery_status": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The result of the service's most recent delivery, such as `success` or `failure`. Null until the service has delivered a notification.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"last_error": schema.StringAttribute{
+				Computed:            true,
+				MarkdownDescription: "The error returned by the service's most recent failed delivery. Null when the most recent delivery succeeded.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
 			"created_at": schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "The time when the notification service was created.",
@@ -387,6 +445,46 @@ func notificationServiceOpenTelemetryTracingSchema() schema.SingleNestedAttribut
 	}
 }
 
+func notificationServiceSlackSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:            true,
+		MarkdownDescription: "Settings for the legacy `slack` provider. Create one service per channel and use `scope` and `scope_uuids` to route pipelines to it.",
+		Attributes: map[string]schema.Attribute{
+			"webhook_url": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				Sensitive:           true,
+				MarkdownDescription: "The Slack incoming webhook URL. Required on create and masked by the API.",
+			},
+			"channel": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "The channel to post to, such as `#builds`, overriding the incoming webhook's default channel. The channel set in Buildkite is kept when omitted.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"message_template": schema.StringAttribute{
+				Optional:            true,
+				Computed:            true,
+				MarkdownDescription: "A template for the notification message. The template set in Buildkite, or Buildkite's default message if there is none, is kept when omitted.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"build_states": schema.SetAttribute{
+				Optional:            true,
+				Computed:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "The build states to notify about: " + strings.Join(notificationServiceBuildStates, ", ") + ". Buildkite's default selection is used when omitted.",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(notificationServiceBuildStates...)),
+				},
+			},
+		},
+	}
+}
+
 func (r *notificationServiceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
 	if req.Plan.Raw.IsNull() {
 		return
@@ -479,7 +577,21 @@ func (r *notificationServiceResource) ModifyPlan(ctx context.Context, req resour
 		return
 	}
 
+	if config.TestDelivery.ValueBool() && !config.Enabled.IsNull() && !config.Enabled.IsUnknown() && !config.Enabled.ValueBool() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("test_delivery"),
+			"Test delivery requires an enabled notification service",
+			"A disabled notification service does not deliver notifications. Set enabled to true or remove test_delivery.",
+		)
+		return
+	}
+
 	if !req.State.Raw.IsNull() {
+		// Update sends a test delivery, whose outcome replaces the delivery status kept from state
+		if config.TestDelivery.ValueBool() && !resp.Plan.Raw.Equal(req.State.Raw) {
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_delivery_status"), types.StringUnknown())...)
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_error"), types.StringUnknown())...)
+		}
 		return
 	}
 
@@ -501,6 +613,8 @@ func (r *notificationServiceResource) ModifyPlan(ctx context.Context, req resour
 		addMissingNotificationServiceSetting(&resp.Diagnostics, config.DatadogPipelineVisibility.APIKey, "datadog_pipeline_visibility.api_key")
 	case notificationServiceProviderOpenTelemetryTracing:
 		addMissingNotificationServiceSetting(&resp.Diagnostics, config.OpenTelemetryTracing.Endpoint, "open_telemetry_tracing.endpoint")
+	case notificationServiceProviderSlack:
+		addMissingNotificationServiceSetting(&resp.Diagnostics, config.Slack.WebhookURL, "slack.webhook_url")
 	}
 }
 
@@ -518,6 +632,9 @@ func (m *notificationServiceResourceModel) configuredSettings() []string {
 	if m.OpenTelemetryTracing != nil {
 		configured = append(configured, notificationServiceProviderOpenTelemetryTracing)
 	}
+	if m.Slack != nil {
+		configured = append(configured, notificationServiceProviderSlack)
+	}
 	return configured
 }
 
@@ -566,6 +683,10 @@ func (r *notificationServiceResource) Create(ctx context.Context, req resource.C
 	}
 
 	if plan.Enabled.ValueBool() {
+		if plan.TestDelivery.ValueBool() {
+			resp.Diagnostics.Append(r.sendTestDelivery(requestCtx, &state)...)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		}
 		return
 	}
 
@@ -605,7 +726,8 @@ func (r *notificationServiceResource) Read(ctx context.Context, req resource.Rea
 		notificationServiceProviderDatadogPipelineVisibility,
 		notificationServiceProviderOpenTelemetryTracing,
 		notificationServiceProviderLinear,
-		notificationServiceProviderSlackWorkspace:
+		notificationServiceProviderSlackWorkspace,
+		notificationServiceProviderSlack:
 	default:
 		resp.Diagnostics.AddError(
 			"Unsupported notification service provider",
@@ -683,8 +805,46 @@ func (r *notificationServiceResource) Update(ctx context.Context, req resource.U
 		}
 	}
 
+	// Deliveries made since the plan are left for the next refresh, so the apply matches the plan
+	lastDeliveryStatus, lastError := plan.LastDeliveryStatus, plan.LastError
 	resp.Diagnostics.Append(plan.applyAPIResponse(ctx, result, state)...)
+	if !lastDeliveryStatus.IsUnknown() {
+		plan.LastDeliveryStatus, plan.LastError = lastDeliveryStatus, lastError
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || !plan.TestDelivery.ValueBool() || !plan.Enabled.ValueBool() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.sendTestDelivery(requestCtx, &plan)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// sendTestDelivery sends a test delivery through a saved service and records its outcome in
+// state. A delivery that does not succeed is an error, so the apply fails.
+func (r *notificationServiceResource) sendTestDelivery(ctx context.Context, state *notificationServiceResourceModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	result, err := r.test(ctx, state.ID.ValueString())
+	if err != nil {
+		diags.AddError(
+			"Unable to send notification service test delivery",
+			fmt.Sprintf("The notification service was saved in Terraform state, but sending a test delivery failed: %s", err),
+		)
+		return diags
+	}
+
+	diags.Append(state.applyAPIResponse(ctx, result, *state)...)
+	if status := state.LastDeliveryStatus.ValueString(); status != notificationServiceDeliverySucceeded {
+		detail := fmt.Sprintf("Buildkite reported the test delivery as %q", status)
+		if !state.LastError.IsNull() {
+			detail += ": " + state.LastError.ValueString()
+		}
+		diags.AddError(
+			"Notification service test delivery failed",
+			detail+". The notification service was saved in Terraform state; fix its settings or destination and apply again.",
+		)
+	}
+	return diags
 }
 
 func (r *notificationServiceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -740,6 +900,11 @@ func (m notificationServiceResourceModel) createPayload(ctx context.Context) (ma
 	settings, settingsDiags := m.settingsCreatePayload(ctx)
 	diags.Append(settingsDiags...)
 	payload["settings"] = settings
+	if m.Slack != nil && !m.Slack.BuildStates.IsNull() && !m.Slack.BuildStates.IsUnknown() {
+		buildStates, buildStateDiags := notificationServiceBuildStatesPayload(ctx, m.Slack.BuildStates)
+		diags.Append(buildStateDiags...)
+		payload["build_states"] = buildStates
+	}
 	return payload, diags
 }
 
@@ -771,6 +936,23 @@ func (m notificationServiceResourceModel) updatePayload(ctx context.Context, sta
 	if len(settings) > 0 {
 		payload["settings"] = settings
 	}
+	if m.Slack != nil && !m.Slack.BuildStates.IsNull() && !m.Slack.BuildStates.IsUnknown() &&
+		(state.Slack == nil || !m.Slack.BuildStates.Equal(state.Slack.BuildStates)) {
+		buildStates, buildStateDiags := notificationServiceBuildStatesPayload(ctx, m.Slack.BuildStates)
+		diags.Append(buildStateDiags...)
+		payload["build_states"] = buildStates
+	}
+	return payload, diags
+}
+
+// notificationServiceBuildStatesPayload sets a build_<state> flag for every build state, so states
+// removed from the set are turned off.
+func notificationServiceBuildStatesPayload(ctx context.Context, value types.Set) (map[string]bool, diag.Diagnostics) {
+	selected, diags := notificationServiceStringSet(ctx, value)
+	payload := make(map[string]bool, len(notificationServiceBuildStates))
+	for _, state := range notificationServiceBuildStates {
+		payload["build_"+state] = slices.Contains(selected, state)
+	}
 	return payload, diags
 }
 
@@ -806,6 +988,10 @@ func (m notificationServiceResourceModel) settingsCreatePayload(ctx context.Cont
 		addNotificationServiceString(settings, "service_name", m.OpenTelemetryTracing.ServiceName)
 		addNotificationServiceMap(ctx, settings, "headers", m.OpenTelemetryTracing.Headers, &diags)
 		addNotificationServiceMap(ctx, settings, "resource_attributes", m.OpenTelemetryTracing.ResourceAttributes, &diags)
+	case notificationServiceProviderSlack:
+		addNotificationServiceString(settings, "webhook_url", m.Slack.WebhookURL)
+		addNotificationServiceString(settings, "channel", m.Slack.Channel)
+		addNotificationServiceString(settings, "message_template", m.Slack.MessageTemplate)
 	}
 
 	return settings, diags
@@ -894,6 +1080,16 @@ func (m notificationServiceResourceModel) settingsUpdatePayload(ctx context.Cont
 		addChangedNotificationServiceString(settings, "service_name", m.OpenTelemetryTracing.ServiceName, state.OpenTelemetryTracing.ServiceName)
 		addChangedNotificationServiceMap(ctx, settings, "headers", m.OpenTelemetryTracing.Headers, state.OpenTelemetryTracing.Headers, &diags)
 		addChangedNotificationServiceMap(ctx, settings, "resource_attributes", m.OpenTelemetryTracing.ResourceAttributes, state.OpenTelemetryTracing.ResourceAttributes, &diags)
+	case notificationServiceProviderSlack:
+		if m.Slack == nil {
+			break
+		}
+		if state.Slack == nil {
+			return m.settingsCreatePayload(ctx)
+		}
+		addChangedNotificationServiceString(settings, "webhook_url", m.Slack.WebhookURL, state.Slack.WebhookURL)
+		addChangedNotificationServiceString(settings, "channel", m.Slack.Channel, state.Slack.Channel)
+		addChangedNotificationServiceString(settings, "message_template", m.Slack.MessageTemplate, state.Slack.MessageTemplate)
 	}
 
 	return settings, diags
@@ -1006,7 +1202,12 @@ func (m *notificationServiceResourceModel) applyAPIResponse(ctx context.Context,
 	}
 	m.Enabled = types.BoolValue(result.Enabled)
 	m.Scope = types.StringValue(result.Scope)
+	m.LastDeliveryStatus = types.StringPointerValue(result.LastDeliveryStatus)
+	m.LastError = types.StringPointerValue(result.LastError)
 	m.CreatedAt = types.StringValue(result.CreatedAt)
+	if m.TestDelivery.IsNull() {
+		m.TestDelivery = types.BoolValue(false)
+	}
 
 	var diags diag.Diagnostics
 	if len(result.ScopeUUIDs) == 0 && m.ScopeUUIDs.IsNull() {
@@ -1030,6 +1231,8 @@ func (m *notificationServiceResourceModel) applyAPIResponse(ctx context.Context,
 		diags.Append(m.applyDatadogPipelineVisibilityAPISettings(result.Settings, previous)...)
 	case notificationServiceProviderOpenTelemetryTracing:
 		diags.Append(m.applyOpenTelemetryTracingAPISettings(ctx, result.Settings, previous)...)
+	case notificationServiceProviderSlack:
+		diags.Append(m.applySlackAPISettings(ctx, result, previous)...)
 	}
 
 	return diags
@@ -1045,6 +1248,8 @@ func (m *notificationServiceResourceModel) initializeImportedSettings(providerTy
 		m.DatadogPipelineVisibility = &notificationServiceDatadogPipelineVisibilityModel{APIKey: types.StringNull()}
 	case notificationServiceProviderOpenTelemetryTracing:
 		m.OpenTelemetryTracing = &notificationServiceOpenTelemetryTracingModel{Headers: types.MapNull(types.StringType)}
+	case notificationServiceProviderSlack:
+		m.Slack = &notificationServiceSlackModel{WebhookURL: types.StringNull()}
 	}
 }
 
@@ -1147,6 +1352,36 @@ func (m *notificationServiceResourceModel) applyOpenTelemetryTracingAPISettings(
 	return diags
 }
 
+func (m *notificationServiceResourceModel) applySlackAPISettings(ctx context.Context, result *notificationServiceAPIResponse, previous notificationServiceResourceModel) diag.Diagnostics {
+	if m.Slack == nil {
+		return nil
+	}
+	var settings notificationServiceSlackAPISettings
+	if err := json.Unmarshal(result.Settings, &settings); err != nil {
+		return notificationServiceSettingsDiagnostic(notificationServiceProviderSlack, err)
+	}
+
+	var selected []string
+	for _, state := range notificationServiceBuildStates {
+		if result.BuildStates["build_"+state] {
+			selected = append(selected, state)
+		}
+	}
+	buildStates, diags := types.SetValueFrom(ctx, types.StringType, selected)
+
+	previousWebhookURL := types.StringNull()
+	if previous.Slack != nil {
+		previousWebhookURL = previous.Slack.WebhookURL
+	}
+	m.Slack = &notificationServiceSlackModel{
+		WebhookURL:      preserveNotificationServiceString(m.Slack.WebhookURL, previousWebhookURL),
+		Channel:         types.StringPointerValue(settings.Channel),
+		MessageTemplate: types.StringPointerValue(settings.MessageTemplate),
+		BuildStates:     buildStates,
+	}
+	return diags
+}
+
 func preserveNotificationServiceString(configured, previous types.String) types.String {
 	if !configured.IsNull() && !configured.IsUnknown() {
 		return configured
@@ -1216,6 +1451,17 @@ func (r *notificationServiceResource) setEnabled(ctx context.Context, id string,
 	return &result, nil
 }
 
+// test sends a test delivery through the service and returns the service with that delivery's
+// outcome as its last delivery.
+func (r *notificationServiceResource) test(ctx context.Context, id string) (*notificationServiceAPIResponse, error) {
+	path := fmt.Sprintf("/v2/organizations/%s/services/%s/test", r.client.organization, id)
+	var result notificationServiceAPIResponse
+	if err := r.client.makeRequest(ctx, http.MethodPost, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 func (r *notificationServiceResource) delete(ctx context.Context, id string) error {
 	path := fmt.Sprintf("/v2/organizations/%s/services/%s", r.client.organization, id)
 	return r.client.makeRequest(ctx, http.MethodDelete, path, nil, nil)
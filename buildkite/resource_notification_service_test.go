@@ -14,10 +14,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
 )
 
 const notificationServiceTestID = "123e4567-e89b-42d3-a456-426614174000"
@@ -61,6 +62,75 @@ func TestNotificationServiceUpdatePayloadOnlyIncludesChanges(t *testing.T) {
 	}
 }
 
+func TestNotificationServiceSlackBuildStatePayloads(t *testing.T) {
+	t.Parallel()
+
+	buildStates := types.SetValueMust(types.StringType, []attr.Value{
+		types.StringValue("failed"),
+		types.StringValue("passed"),
+	})
+	plan := notificationServiceResourceModel{
+		Description:         types.StringNull(),
+		BranchConfiguration: types.StringNull(),
+		Enabled:             types.BoolValue(true),
+		Scope:               types.StringValue("all"),
+		ScopeUUIDs:          types.SetNull(types.StringType),
+		ProviderType:        types.StringValue(notificationServiceProviderSlack),
+		Slack: &notificationServiceSlackModel{
+			WebhookURL:      types.StringValue("https://hooks.slack.test/services/T0/B0/secret"),
+			Channel:         types.StringValue("#builds"),
+			MessageTemplate: types.StringNull(),
+			BuildStates:     buildStates,
+		},
+	}
+
+	got, diags := plan.createPayload(t.Context())
+	if diags.HasError() {
+		t.Fatalf("createPayload() diagnostics = %v", diags)
+	}
+	wantBuildStates := map[string]bool{
+		"build_scheduled": false,
+		"build_running":   false,
+		"build_passed":    true,
+		"build_failed":    true,
+		"build_blocked":   false,
+		"build_canceled":  false,
+	}
+	if diff := cmp.Diff(got["build_states"], wantBuildStates); diff != "" {
+		t.Errorf("createPayload() build_states mismatch (-got +want):\n%s", diff)
+	}
+	if diff := cmp.Diff(got["settings"], map[string]any{
+		"webhook_url": "https://hooks.slack.test/services/T0/B0/secret",
+		"channel":     "#builds",
+	}); diff != "" {
+		t.Errorf("createPayload() settings mismatch (-got +want):\n%s", diff)
+	}
+
+	state := plan
+	got, diags = plan.updatePayload(t.Context(), state)
+	if diags.HasError() {
+		t.Fatalf("updatePayload() diagnostics = %v", diags)
+	}
+	if len(got) != 0 {
+		t.Errorf("updatePayload() = %v, want no changes", got)
+	}
+
+	plan.Slack = &notificationServiceSlackModel{
+		WebhookURL:      state.Slack.WebhookURL,
+		Channel:         state.Slack.Channel,
+		MessageTemplate: state.Slack.MessageTemplate,
+		BuildStates:     types.SetValueMust(types.StringType, []attr.Value{types.StringValue("failed")}),
+	}
+	got, diags = plan.updatePayload(t.Context(), state)
+	if diags.HasError() {
+		t.Fatalf("updatePayload() diagnostics = %v", diags)
+	}
+	wantBuildStates["build_passed"] = false
+	if diff := cmp.Diff(got, map[string]any{"build_states": wantBuildStates}); diff != "" {
+		t.Errorf("updatePayload() mismatch (-got +want):\n%s", diff)
+	}
+}
+
 func TestNotificationServiceScopeUUIDPayloadsUseLowercase(t *testing.T) {
 	t.Parallel()
 
@@ -187,6 +257,22 @@ func TestNotificationServiceApplyAPIResponsePreservesWriteOnlySettings(t *testin
 	if !state.OpenTelemetryTracing.Headers.IsNull() {
 		t.Errorf("OpenTelemetry headers = %v, want null", state.OpenTelemetryTracing.Headers)
 	}
+
+	response = notificationServiceTestResponse(notificationServiceProviderSlack)
+	state = notificationServiceResourceModel{
+		Slack: &notificationServiceSlackModel{WebhookURL: types.StringValue("https://hooks.slack.test/services/T0/B0/secret")},
+	}
+	previous = state
+	if diags := state.applyAPIResponse(t.Context(), &response, previous); diags.HasError() {
+		t.Fatalf("applyAPIResponse() diagnostics = %v", diags)
+	}
+	if got, want := state.Slack.WebhookURL.ValueString(), "https://hooks.slack.test/services/T0/B0/secret"; got != want {
+		t.Errorf("Slack webhook URL = %q, want %q", got, want)
+	}
+	wantBuildStates := types.SetValueMust(types.StringType, []attr.Value{types.StringValue("failed")})
+	if !state.Slack.BuildStates.Equal(wantBuildStates) {
+		t.Errorf("Slack build states = %v, want %v", state.Slack.BuildStates, wantBuildStates)
+	}
 }
 
 func TestNotificationServiceApplyAPIResponseValidatesImportedAWSAccountID(t *testing.T) {
@@ -543,6 +629,66 @@ func TestNotificationServiceDatadogLifecycle(t *testing.T) {
 	})
 }
 
+func TestNotificationServiceSlackLifecycle(t *testing.T) {
+	api := newNotificationServiceTestAPI(t)
+	api.setProvider(notificationServiceProviderSlack)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: notificationServiceSlackUnitTestConfig(api.server.URL, `channel = "#builds"`, `["failed"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "slack.webhook_url", "https://hooks.slack.test/services/T0/B0/secret"),
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "slack.channel", "#builds"),
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "slack.build_states.#", "1"),
+				),
+			},
+			{
+				Config: notificationServiceSlackUnitTestConfig(api.server.URL, `channel = "#builds"`, `["failed", "blocked"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectKnownValue("buildkite_notification_service.test", tfjsonpath.New("last_delivery_status"), knownvalue.Null()),
+						plancheck.ExpectKnownValue("buildkite_notification_service.test", tfjsonpath.New("last_error"), knownvalue.Null()),
+					},
+				},
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "slack.build_states.#", "2"),
+					func(*terraform.State) error {
+						return api.verifyPatch(map[string]any{
+							"build_states": map[string]any{
+								"build_scheduled": false,
+								"build_running":   false,
+								"build_passed":    false,
+								"build_failed":    true,
+								"build_blocked":   true,
+								"build_canceled":  false,
+							},
+						})
+					},
+				),
+			},
+			{
+				// The channel and message template set in Buildkite are kept when they are not configured
+				Config: notificationServiceSlackUnitTestConfig(api.server.URL, "", `["failed", "blocked"]`),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_notification_service.test", plancheck.ResourceActionNoop),
+					},
+				},
+				Check: resource.TestCheckResourceAttr("buildkite_notification_service.test", "slack.channel", "#builds"),
+			},
+			{
+				ResourceName:            "buildkite_notification_service.test",
+				ImportState:             true,
+				ImportStateId:           notificationServiceTestID,
+				ImportStateVerify:       true,
+				ImportStateVerifyIgnore: []string{"slack.webhook_url"},
+			},
+		},
+	})
+}
+
 func TestNotificationServiceOpenTelemetryDefaults(t *testing.T) {
 	api := newNotificationServiceTestAPI(t)
 	api.setProvider(notificationServiceProviderOpenTelemetryTracing)
@@ -565,6 +711,61 @@ func TestNotificationServiceOpenTelemetryDefaults(t *testing.T) {
 	})
 }
 
+func TestNotificationServiceTestDelivery(t *testing.T) {
+	api := newNotificationServiceTestAPI(t)
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: notificationServiceTestDeliveryUnitTestConfig(api.server.URL, "initial"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "last_delivery_status", "success"),
+					resource.TestCheckNoResourceAttr("buildkite_notification_service.test", "last_error"),
+					func(*terraform.State) error {
+						if got := api.testDeliveries(); got != 1 {
+							return fmt.Errorf("test deliveries = %d, want 1", got)
+						}
+						return nil
+					},
+				),
+			},
+			{
+				Config: notificationServiceTestDeliveryUnitTestConfig(api.server.URL, "initial"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectResourceAction("buildkite_notification_service.test", plancheck.ResourceActionNoop),
+					},
+				},
+			},
+			{
+				PreConfig: func() {
+					api.setDeliveryError("connection refused")
+				},
+				Config: notificationServiceTestDeliveryUnitTestConfig(api.server.URL, "updated"),
+				ConfigPlanChecks: resource.ConfigPlanChecks{
+					PreApply: []plancheck.PlanCheck{
+						plancheck.ExpectUnknownValue("buildkite_notification_service.test", tfjsonpath.New("last_delivery_status")),
+						plancheck.ExpectUnknownValue("buildkite_notification_service.test", tfjsonpath.New("last_error")),
+					},
+				},
+				ExpectError: regexp.MustCompile(`(?s)Notification service test delivery failed.*"failure": connection\s+refused`),
+			},
+			{
+				PreConfig: func() {
+					api.setDeliveryError("")
+				},
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "description", "updated"),
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "last_delivery_status", "failure"),
+					resource.TestCheckResourceAttr("buildkite_notification_service.test", "last_error", "connection refused"),
+				),
+			},
+		},
+	})
+}
+
 func TestNotificationServiceCreateTracksResourceBeforeDisableError(t *testing.T) {
 	api := newNotificationServiceTestAPI(t)
 	api.failDisable = true
@@ -690,7 +891,7 @@ func TestNotificationServiceOAuthImportRejectsNotificationFilters(t *testing.T)
 
 func TestNotificationServiceImportRejectsUnsupportedProvider(t *testing.T) {
 	api := newNotificationServiceTestAPI(t)
-	api.setProvider("slack")
+	api.setProvider("pagerduty")
 
 	resource.UnitTest(t, resource.TestCase{
 		ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -699,7 +900,7 @@ func TestNotificationServiceImportRejectsUnsupportedProvider(t *testing.T) {
 			ResourceName:  "buildkite_notification_service.test",
 			ImportState:   true,
 			ImportStateId: notificationServiceTestID,
-			ExpectError:   regexp.MustCompile(`provider_type "slack".*not supported`),
+			ExpectError:   regexp.MustCompile(`provider_type "pagerduty".*not supported`),
 		}},
 	})
 }
@@ -775,8 +976,8 @@ func TestNotificationServiceConfigurationValidation(t *testing.T) {
 			`, notificationServiceTestID, strings.ToUpper(notificationServiceTestID)),
 			wantError: "Duplicate notification service scope UUID",
 		},
-		// Supported providers ignore build-state flags. Keep this rejection pinned so
-		// the field is not accidentally exposed without a provider that can use it.
+		// Only legacy Slack services use build-state flags, through slack.build_states.
+		// Keep this rejection pinned so they are not exposed for providers that ignore them.
 		"build states": {
 			resourceConfig: `
 				provider_type = "webhook"
@@ -793,8 +994,34 @@ func TestNotificationServiceConfigurationValidation(t *testing.T) {
 			resourceConfig: `provider_type = "slack_workspace"`,
 			wantError:      "OAuth-managed notification service cannot be created",
 		},
-		"legacy Slack": {
-			resourceConfig: `provider_type = "slack"`,
+		"legacy Slack without a webhook URL": {
+			resourceConfig: `
+				provider_type = "slack"
+				slack = { channel = "#builds" }
+			`,
+			wantError: "slack.webhook_url is required",
+		},
+		"legacy Slack build state": {
+			resourceConfig: `
+				provider_type = "slack"
+				slack = {
+					webhook_url  = "https://hooks.slack.test/services/T0/B0/secret"
+					build_states = ["finished"]
+				}
+			`,
+			wantError: "value must be one of",
+		},
+		"test delivery for a disabled service": {
+			resourceConfig: `
+				provider_type = "webhook"
+				enabled = false
+				test_delivery = true
+				webhook = { url = "https://example.test/hook" }
+			`,
+			wantError: "Test delivery requires an enabled notification service",
+		},
+		"PagerDuty": {
+			resourceConfig: `provider_type = "pagerduty"`,
 			wantError:      "Attribute provider_type value must be one of",
 		},
 	}
@@ -883,7 +1110,7 @@ func TestNotificationServiceAllowsComputedScopeUUID(t *testing.T) {
 }
 
 func TestAccBuildkiteNotificationService(t *testing.T) {
-	random := acctest.RandString(10)
+	random := randString(t, 10)
 	config := func(description string, enabled bool) string {
 		return fmt.Sprintf(`
 			resource "buildkite_notification_service" "test" {
@@ -976,6 +1203,28 @@ func notificationServiceUnitTestConfig(restURL, description string, enabled bool
 	`, restURL, description, enabled)
 }
 
+func notificationServiceTestDeliveryUnitTestConfig(restURL, description string) string {
+	return fmt.Sprintf(`
+		provider "buildkite" {
+			organization = "test"
+			api_token = "test"
+			rest_url = %q
+			max_retries = 0
+		}
+
+		resource "buildkite_notification_service" "test" {
+			provider_type = "webhook"
+			description = %q
+			test_delivery = true
+
+			webhook = {
+				url = "https://example.test/hook"
+				token = "terraform-secret"
+			}
+		}
+	`, restURL, description)
+}
+
 func notificationServiceOAuthUnitTestConfig(restURL, providerType, description, extra string) string {
 	return fmt.Sprintf(`
 		provider "buildkite" {
@@ -1012,6 +1261,27 @@ func notificationServiceDatadogUnitTestConfig(restURL, apiKey string) string {
 	`, restURL, apiKey)
 }
 
+func notificationServiceSlackUnitTestConfig(restURL, channel, buildStates string) string {
+	return fmt.Sprintf(`
+		provider "buildkite" {
+			organization = "test"
+			api_token = "test"
+			rest_url = %q
+			max_retries = 0
+		}
+
+		resource "buildkite_notification_service" "test" {
+			provider_type = "slack"
+
+			slack = {
+				webhook_url  = "https://hooks.slack.test/services/T0/B0/secret"
+				build_states = %s
+				%s
+			}
+		}
+	`, restURL, buildStates, channel)
+}
+
 func notificationServiceOpenTelemetryUnitTestConfig(restURL string) string {
 	return fmt.Sprintf(`
 		provider "buildkite" {
@@ -1073,6 +1343,11 @@ type notificationServiceTestAPI struct {
 	awsRegion            string
 	awsAccountID         string
 	includeBuildMetadata *string
+	slackChannel         *string
+	buildStates          map[string]bool
+	deliveryError        string
+	lastDeliveryStatus   *string
+	lastError            *string
 	requests             []notificationServiceTestRequest
 }
 
@@ -1086,6 +1361,7 @@ func newNotificationServiceTestAPI(t *testing.T) *notificationServiceTestAPI {
 		scopeUUIDs:   []string{},
 		awsRegion:    "us-east-1",
 		awsAccountID: "123456789012",
+		buildStates:  map[string]bool{"build_failed": true},
 	}
 	api.server = httptest.NewServer(http.HandlerFunc(api.handle))
 	t.Cleanup(api.server.Close)
@@ -1135,6 +1411,14 @@ func (api *notificationServiceTestAPI) handle(w http.ResponseWriter, req *http.R
 		}
 		api.enabled = false
 		api.writeResponse(w, http.StatusOK)
+	case req.Method == http.MethodPost && req.URL.Path == resourcePath+"/test":
+		status := notificationServiceDeliverySucceeded
+		api.lastDeliveryStatus, api.lastError = &status, nil
+		if api.deliveryError != "" {
+			status, deliveryError := "failure", api.deliveryError
+			api.lastDeliveryStatus, api.lastError = &status, &deliveryError
+		}
+		api.writeResponse(w, http.StatusOK)
 	case req.Method == http.MethodPut && req.URL.Path == resourcePath+"/enable":
 		api.enabled = true
 		api.writeResponse(w, http.StatusOK)
@@ -1161,7 +1445,15 @@ func (api *notificationServiceTestAPI) applyRequestBody(body map[string]any) {
 			}
 		}
 	}
+	if buildStates, ok := body["build_states"].(map[string]any); ok {
+		for state, enabled := range buildStates {
+			api.buildStates[state], _ = enabled.(bool)
+		}
+	}
 	if settings, ok := body["settings"].(map[string]any); ok {
+		if channel, ok := settings["channel"].(string); ok {
+			api.slackChannel = &channel
+		}
 		if region, ok := settings["aws_region"].(string); ok {
 			api.awsRegion = region
 		}
@@ -1196,10 +1488,26 @@ func (api *notificationServiceTestAPI) writeResponse(w http.ResponseWriter, stat
 		}
 		response.Settings = settings
 	}
+	if api.providerType == notificationServiceProviderSlack {
+		webhookURL := "XXXXXXXXcret"
+		settings, err := json.Marshal(notificationServiceSlackAPISettings{
+			WebhookURL: &webhookURL,
+			Channel:    api.slackChannel,
+		})
+		if err != nil {
+			api.t.Errorf("encode Slack settings: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		response.Settings = settings
+		response.BuildStates = api.buildStates
+	}
 	if api.description != "" {
 		response.Description = &api.description
 	}
 	response.Enabled = api.enabled
+	response.LastDeliveryStatus = api.lastDeliveryStatus
+	response.LastError = api.lastError
 	response.Scope = api.scope
 	response.ScopeUUIDs = api.scopeUUIDs
 	w.Header().Set("Content-Type", "application/json")
@@ -1270,6 +1578,24 @@ func (api *notificationServiceTestAPI) setFailDisable(fail bool) {
 	api.failDisable = fail
 }
 
+func (api *notificationServiceTestAPI) setDeliveryError(deliveryError string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.deliveryError = deliveryError
+}
+
+func (api *notificationServiceTestAPI) testDeliveries() int {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	deliveries := 0
+	for _, request := range api.requests {
+		if request.Method == http.MethodPost && strings.HasSuffix(request.Path, "/test") {
+			deliveries++
+		}
+	}
+	return deliveries
+}
+
 func (api *notificationServiceTestAPI) isDeleted() bool {
 	api.mu.Lock()
 	defer api.mu.Unlock()
@@ -1317,6 +1643,13 @@ func notificationServiceTestResponse(providerType string) notificationServiceAPI
 			"resource_attributes":{},
 			"tracestate":{}
 		}`)
+	case notificationServiceProviderSlack:
+		response.Settings = json.RawMessage(`{
+			"webhook_url":"XXXXXXXXcret",
+			"channel":"#builds",
+			"message_template":null
+		}`)
+		response.BuildStates = map[string]bool{"build_failed": true, "build_passed": false}
 	}
 	return response
 }
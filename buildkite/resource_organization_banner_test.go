@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -29,7 +28,7 @@ func TestAccBuildkiteOrganizationBannerResource(t *testing.T) {
 	}
 
 	t.Run("creates an organization banner", func(t *testing.T) {
-		message := acctest.RandString(12)
+		message := randString(t, 12)
 		var obr organizationBannerResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -57,8 +56,8 @@ func TestAccBuildkiteOrganizationBannerResource(t *testing.T) {
 	})
 
 	t.Run("updates an organization banner", func(t *testing.T) {
-		message := acctest.RandString(12)
-		updatedMessage := acctest.RandString(12)
+		message := randString(t, 12)
+		updatedMessage := randString(t, 12)
 		var obr organizationBannerResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -99,7 +98,7 @@ func TestAccBuildkiteOrganizationBannerResource(t *testing.T) {
 	})
 
 	t.Run("imports an organization banner", func(t *testing.T) {
-		message := acctest.RandString(12)
+		message := randString(t, 12)
 		var obr organizationBannerResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -7,7 +7,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -1013,8 +1012,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		resourceName := fmt.Sprintf("buildkite_organization_rule.%s_rule", action)
 
 		t.Run(fmt.Sprintf("creates a pipeline.%s.pipeline organization rule with required attributes", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1051,8 +1050,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("creates a pipeline.%s.pipeline organization rule with all attributes", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1091,9 +1090,9 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by adding a description", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
-			description := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
+			description := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1145,10 +1144,10 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by editing its current description", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
-			description := acctest.RandString(12)
-			updatedDescription := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
+			description := randString(t, 12)
+			updatedDescription := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1199,9 +1198,9 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by removing its description", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
-			description := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
+			description := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1251,9 +1250,9 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by changing its source_pipeline", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
-			randNameThree := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
+			randNameThree := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1301,9 +1300,9 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by changing its target_pipeline", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
-			randNameThree := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
+			randNameThree := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1351,8 +1350,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by changing both source_pipeline and target_pipeline", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1402,8 +1401,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by adding conditions", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			conditions := `
@@ -1455,8 +1454,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by inserting additional conditions", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			initConditions := `
@@ -1514,8 +1513,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by removing some conditions", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			initConditions := `
@@ -1573,8 +1572,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by removing all conditions", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			existingConditions := `
@@ -1627,8 +1626,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("updates a pipeline.%s.pipeline organization rule by editing its current conditions", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			initConditions := `
@@ -1687,8 +1686,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("imports a pipeline.%s.pipeline organization rule with required attributes", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1726,8 +1725,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		})
 
 		t.Run(fmt.Sprintf("imports a pipeline.%s.pipeline organization rule with all attributes", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
@@ -1767,8 +1766,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	}
 
 	t.Run("replaces an orgnanization rule when updating its type", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -1825,8 +1824,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is created with an unknown action", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -1841,8 +1840,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is created with an invalid conditional", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -1857,7 +1856,7 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is created with a missing source_pipeline in its value", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -1872,7 +1871,7 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is created with a missing target_pipeline in its value", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -1887,7 +1886,7 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is created with an invalid source_pipeline slug", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -1902,7 +1901,7 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is created with an invalid target_pipeline slug", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
 			ProtoV6ProviderFactories: protoV6ProviderFactories(),
@@ -1917,8 +1916,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is updated with a malformed source_pipeline key", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -1955,8 +1954,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is updated with a malformed target_pipeline key", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -1993,8 +1992,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is updated with an invalid source_pipeline UUID", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -2031,8 +2030,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is updated with an invalid target_pipeline UUID", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -2070,8 +2069,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 
 	t.Run("errors when an organization rule is updated with no source_pipeline UUID", func(t *testing.T) {
 		ruleType := "trigger_build"
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -2108,8 +2107,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is updated with no target_pipeline UUID", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -2146,8 +2145,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 	})
 
 	t.Run("errors when an organization rule is updated with invalid conditions", func(t *testing.T) {
-		randNameOne := acctest.RandString(12)
-		randNameTwo := acctest.RandString(12)
+		randNameOne := randString(t, 12)
+		randNameTwo := randString(t, 12)
 		var orr organizationRuleResourceModel
 
 		initConditions := `
@@ -2249,8 +2248,8 @@ func TestAccBuildkiteOrganizationRuleResource(t *testing.T) {
 		resourceName := fmt.Sprintf("buildkite_organization_rule.%s_rule", action)
 
 		t.Run(fmt.Sprintf("creates a pipeline.%s.pipeline organization rule using pipeline slugs", action), func(t *testing.T) {
-			randNameOne := acctest.RandString(12)
-			randNameTwo := acctest.RandString(12)
+			randNameOne := randString(t, 12)
+			randNameTwo := randString(t, 12)
 			var orr organizationRuleResourceModel
 
 			check := resource.ComposeAggregateTestCheckFunc(
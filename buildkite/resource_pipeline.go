@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 	"unsafe"
@@ -73,6 +74,11 @@ type PipelineTag struct {
 	Label graphql.String
 }
 
+const (
+	pipelineOnDeleteFail         = "fail"
+	pipelineOnDeleteCancelBuilds = "cancel_builds"
+)
+
 type pipelineResourceModel struct {
 	AllowRebuilds                        types.Bool   `tfsdk:"allow_rebuilds"`
 	Archived                             types.Bool   `tfsdk:"archived"`
@@ -93,6 +99,7 @@ type pipelineResourceModel struct {
 	Id                                 types.String           `tfsdk:"id"`
 	MaximumTimeoutInMinutes            types.Int64            `tfsdk:"maximum_timeout_in_minutes"`
 	Name                               types.String           `tfsdk:"name"`
+	OnDelete                           types.String           `tfsdk:"on_delete"`
 	PipelineTemplateId                 types.String           `tfsdk:"pipeline_template_id"`
 	ProviderSettings                   *providerSettingsModel `tfsdk:"provider_settings"`
 	Repository                         types.String           `tfsdk:"repository"`
@@ -302,6 +309,7 @@ func (p *pipelineResource) Create(ctx context.Context, req resource.CreateReques
 
 	setPipelineModel(&state, &response.PipelineCreate.Pipeline)
 	state.DefaultTeamId = plan.DefaultTeamId
+	state.OnDelete = plan.OnDelete
 
 	useSlugValue := response.PipelineCreate.Pipeline.Slug
 	resp.Diagnostics.Append(resp.Private.SetKey(ctx, "slugSource", []byte(`{"source": "api"}`))...)
@@ -366,6 +374,23 @@ func (p *pipelineResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
+	if state.OnDelete.ValueString() == pipelineOnDeleteCancelBuilds {
+		cancelled, err := cancelPipelineBuilds(ctx, p.client, state.Id.ValueString(), timeout)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Could not cancel pipeline builds",
+				fmt.Sprintf("Could not cancel the unfinished builds of pipeline %s: %s", state.Name.ValueString(), err.Error()),
+			)
+			return
+		}
+		if len(cancelled) > 0 {
+			resp.Diagnostics.AddWarning(
+				"Cancelled pipeline builds",
+				fmt.Sprintf("Cancelled %d unfinished builds of pipeline %s before destroying it:\n%s", len(cancelled), state.Name.ValueString(), strings.Join(cancelled, "\n")),
+			)
+		}
+	}
+
 	if *p.archiveOnDelete {
 		log.Printf("Pipeline %s set to archive on delete. Archiving...", state.Name.ValueString())
 
@@ -407,6 +432,79 @@ func (p *pipelineResource) Delete(ctx context.Context, req resource.DeleteReques
 	}
 }
 
+// cancelPipelineBuilds cancels a pipeline's unfinished builds and waits until none are left, so the
+// pipeline can be deleted. It returns the URLs of the builds it cancelled.
+func cancelPipelineBuilds(ctx context.Context, client *Client, pipelineID string, timeout time.Duration) ([]string, error) {
+	cancelled := map[string]string{}
+	cancelErrors := map[string]error{}
+
+	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+		builds, err := listPipelineUnfinishedBuilds(ctx, client, pipelineID)
+		if err != nil {
+			return retryContextError(err)
+		}
+
+		for _, build := range builds {
+			if _, ok := cancelled[build.Id]; ok || build.State == BuildStatesCanceling {
+				continue
+			}
+
+			log.Printf("Cancelling build %s ...", build.Url)
+			if _, err := cancelBuild(ctx, client.genqlient, build.Id); err != nil {
+				// The build may have finished since it was listed, so only give up when it is
+				// still unfinished after a second attempt.
+				if previous, ok := cancelErrors[build.Id]; ok && !isTransientError(err) {
+					return retry.NonRetryableError(fmt.Errorf("unable to cancel %s: %w", build.Url, previous))
+				}
+				cancelErrors[build.Id] = err
+				continue
+			}
+			cancelled[build.Id] = build.Url
+		}
+
+		if len(builds) > 0 {
+			return retry.RetryableError(fmt.Errorf("waiting for %d builds to finish", len(builds)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(cancelled))
+	for _, url := range cancelled {
+		urls = append(urls, url)
+	}
+	slices.Sort(urls)
+
+	return urls, nil
+}
+
+// listPipelineUnfinishedBuilds lists the builds blocking a pipeline's deletion, following pagination.
+func listPipelineUnfinishedBuilds(ctx context.Context, client *Client, pipelineID string) ([]getPipelineUnfinishedBuildsNodePipelineBuildsBuildConnectionEdgesBuildEdgeNodeBuild, error) {
+	var builds []getPipelineUnfinishedBuildsNodePipelineBuildsBuildConnectionEdgesBuildEdgeNodeBuild
+	cursor := ""
+	for {
+		resp, err := getPipelineUnfinishedBuilds(ctx, client.genqlient, pipelineID, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		pipeline, ok := resp.Node.(*getPipelineUnfinishedBuildsNodePipeline)
+		if !ok {
+			return nil, fmt.Errorf("pipeline %s not found", pipelineID)
+		}
+		for _, edge := range pipeline.Builds.Edges {
+			builds = append(builds, edge.Node)
+		}
+
+		if !pipeline.Builds.PageInfo.HasNextPage {
+			return builds, nil
+		}
+		cursor = pipeline.Builds.PageInfo.EndCursor
+	}
+}
+
 func (*pipelineResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_pipeline"
 }
@@ -720,6 +818,13 @@ func (*pipelineResource) Schema(ctx context.Context, req resource.SchemaRequest,
 				Required:            true,
 				MarkdownDescription: "Name to give the pipeline.",
 			},
+			"on_delete": schema.StringAttribute{
+				Optional:            true,
+				MarkdownDescription: "What to do with builds that have not finished when the pipeline is destroyed. With `fail`, the default, deleting waits for them until the delete timeout and then fails. With `cancel_builds`, running and scheduled builds are cancelled and waited on before the pipeline is deleted, or archived when the provider's `archive_pipeline_on_delete` is set. The cancelled builds are reported as a warning.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(pipelineOnDeleteFail, pipelineOnDeleteCancelBuilds),
+				},
+			},
 			"pipeline_template_id": schema.StringAttribute{
 				Optional:            true,
 				MarkdownDescription: "The GraphQL ID of the pipeline template applied to this pipeline.",
@@ -1383,6 +1488,7 @@ func (p *pipelineResource) Update(ctx context.Context, req resource.UpdateReques
 	// The updatePipeline response predates any archive/unarchive mutation above, so its archived
 	// field reflects the old state. Sync to plan to avoid a provider inconsistency error.
 	state.Archived = plan.Archived
+	state.OnDelete = plan.OnDelete
 
 	if plan.DefaultTeamId.IsNull() && !state.DefaultTeamId.IsNull() {
 		// if the plan is empty but was previously set, just remove the team
@@ -5,8 +5,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	resource_schema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
@@ -14,14 +17,23 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 )
 
+// pipelineScheduleNextRunsCount is how many upcoming runs next_runs lists.
+const pipelineScheduleNextRunsCount = 5
+
+// pipelineScheduleNow is the clock next runs are computed from, replaced in tests.
+var pipelineScheduleNow = time.Now
+
 type pipelineSchedule struct {
 	client *Client
 }
 
+var _ resource.ResourceWithModifyPlan = (*pipelineSchedule)(nil)
+
 type pipelineScheduleResourceModel struct {
 	Id         types.String `tfsdk:"id"`
 	Uuid       types.String `tfsdk:"uuid"`
@@ -33,6 +45,8 @@ type pipelineScheduleResourceModel struct {
 	Env        types.Map    `tfsdk:"env"`
 	Enabled    types.Bool   `tfsdk:"enabled"`
 	PipelineId types.String `tfsdk:"pipeline_id"`
+	NextRunAt  types.String `tfsdk:"next_run_at"`
+	NextRuns   types.List   `tfsdk:"next_runs"`
 }
 
 func newPipelineScheduleResource() resource.Resource {
@@ -85,8 +99,13 @@ func (ps *pipelineSchedule) Schema(ctx context.Context, req resource.SchemaReque
 			},
 			"cronline": resource_schema.StringAttribute{
 				Required: true,
-				MarkdownDescription: "The cronline that describes when the schedule should run. See" +
-					"[here](https://buildkite.com/docs/pipelines/scheduled-builds#schedule-intervals) for supported syntax.",
+				MarkdownDescription: "The cronline that describes when the schedule should run. See " +
+					"[here](https://buildkite.com/docs/pipelines/scheduled-builds#schedule-intervals) for supported syntax. " +
+					"Accepts five cron fields or a predefined interval such as `@daily`, optionally followed by an IANA timezone, " +
+					"for example `0 2 * * * Australia/Melbourne`. Invalid expressions are rejected at plan time.",
+				Validators: []validator.String{
+					cronlineValidator{},
+				},
 			},
 			"branch": resource_schema.StringAttribute{
 				Required:            true,
@@ -113,10 +132,113 @@ func (ps *pipelineSchedule) Schema(ctx context.Context, req resource.SchemaReque
 				MarkdownDescription: "Whether the schedule is enabled or not.",
 				Default:             booldefault.StaticBool(true),
 			},
+			"next_run_at": resource_schema.StringAttribute{
+				Computed: true,
+				MarkdownDescription: "When the schedule will next create a build, as an RFC 3339 timestamp in the cronline's timezone. " +
+					"Known after apply when the cronline or enabled changes, when the plan shows the upcoming runs as a warning, and read from Buildkite otherwise. " +
+					"Null when the schedule is disabled.",
+			},
+			"next_runs": resource_schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				MarkdownDescription: fmt.Sprintf("The next %d times the schedule will create a build, as RFC 3339 timestamps in the cronline's timezone. "+
+					"Empty when the schedule is disabled.", pipelineScheduleNextRunsCount),
+			},
 		},
 	}
 }
 
+// ModifyPlan leaves next_run_at and next_runs unknown when the cronline or enabled changes, since
+// Terraform plans again at apply time and runs may have passed by then. The upcoming runs are shown
+// as a warning instead, so reviewers see when the schedule will fire. Otherwise it keeps the values
+// the last refresh read, since recomputing them on every plan would show a change each time a run
+// passed.
+func (ps *pipelineSchedule) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan pipelineScheduleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Cronline.IsUnknown() || plan.Enabled.IsUnknown() {
+		return
+	}
+
+	if !req.State.Raw.IsNull() {
+		var state pipelineScheduleResourceModel
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if state.Cronline.Equal(plan.Cronline) && state.Enabled.Equal(plan.Enabled) {
+			plan.NextRunAt = state.NextRunAt
+			plan.NextRuns = state.NextRuns
+			resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+			return
+		}
+	}
+
+	plan.NextRunAt = types.StringUnknown()
+	plan.NextRuns = types.ListUnknown(types.StringType)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+
+	if !plan.Enabled.ValueBool() {
+		return
+	}
+	schedule, err := parseCronline(plan.Cronline.ValueString())
+	if err != nil {
+		return
+	}
+	runs := formatPipelineScheduleRuns(schedule.nextRuns(pipelineScheduleNow(), pipelineScheduleNextRunsCount))
+	resp.Diagnostics.AddAttributeWarning(
+		path.Root("cronline"),
+		"Upcoming pipeline schedule runs",
+		fmt.Sprintf("Applied now, the schedule would next create builds at:\n%s", strings.Join(runs, "\n")),
+	)
+}
+
+func formatPipelineScheduleRuns(runs []time.Time) []string {
+	formatted := make([]string, 0, len(runs))
+	for _, run := range runs {
+		formatted = append(formatted, run.Format(time.RFC3339))
+	}
+	return formatted
+}
+
+// pipelineScheduleRuns computes next_run_at and next_runs. nextBuildAt, when Buildkite reported
+// one, takes precedence for next_run_at. A cronline that cannot be parsed leaves next_runs null
+// rather than failing, since Buildkite has already accepted it.
+func pipelineScheduleRuns(ctx context.Context, value string, enabled bool, nextBuildAt *time.Time) (types.String, types.List, diag.Diagnostics) {
+	if !enabled {
+		return types.StringNull(), types.ListValueMust(types.StringType, []attr.Value{}), nil
+	}
+
+	schedule, err := parseCronline(value)
+	if err != nil {
+		nextRunAt := types.StringNull()
+		if nextBuildAt != nil {
+			nextRunAt = types.StringValue(nextBuildAt.UTC().Format(time.RFC3339))
+		}
+		return nextRunAt, types.ListNull(types.StringType), nil
+	}
+
+	formatted := formatPipelineScheduleRuns(schedule.nextRuns(pipelineScheduleNow(), pipelineScheduleNextRunsCount))
+	nextRuns, diags := types.ListValueFrom(ctx, types.StringType, formatted)
+
+	nextRunAt := types.StringNull()
+	switch {
+	case nextBuildAt != nil:
+		nextRunAt = types.StringValue(nextBuildAt.In(schedule.location).Format(time.RFC3339))
+	case len(formatted) > 0:
+		nextRunAt = types.StringValue(formatted[0])
+	}
+
+	return nextRunAt, nextRuns, diags
+}
+
 func (ps *pipelineSchedule) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan, state pipelineScheduleResourceModel
 
@@ -170,6 +292,9 @@ func (ps *pipelineSchedule) Create(ctx context.Context, req resource.CreateReque
 	state.Message = types.StringPointerValue(apiResponse.PipelineScheduleCreate.PipelineScheduleEdge.Node.Message)
 	state.Enabled = types.BoolValue(apiResponse.PipelineScheduleCreate.PipelineScheduleEdge.Node.Enabled)
 	state.Env = plan.Env
+	node := apiResponse.PipelineScheduleCreate.PipelineScheduleEdge.Node
+	state.NextRunAt, state.NextRuns, diags = pipelineScheduleRuns(ctx, state.Cronline.ValueString(), node.Enabled, node.NextBuildAt)
+	resp.Diagnostics.Append(diags...)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
@@ -218,6 +343,8 @@ func (ps *pipelineSchedule) Read(ctx context.Context, req resource.ReadRequest,
 			return
 		}
 		updatePipelineScheduleNode(ctx, &state, *pipelineScheduleNode)
+		state.NextRunAt, state.NextRuns, diags = pipelineScheduleRuns(ctx, state.Cronline.ValueString(), pipelineScheduleNode.Enabled, pipelineScheduleNode.NextBuildAt)
+		resp.Diagnostics.Append(diags...)
 		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 	} else {
 		resp.Diagnostics.AddWarning(
@@ -259,9 +386,10 @@ func (ps *pipelineSchedule) Update(ctx context.Context, req resource.UpdateReque
 		Enabled:  plan.Enabled.ValueBoolPointer(),
 	}
 
+	var apiResponse *updatePipelineScheduleResponse
 	err := retry.RetryContext(ctx, timeouts, func() *retry.RetryError {
 		var err error
-		_, err = updatePipelineSchedule(ctx,
+		apiResponse, err = updatePipelineSchedule(ctx,
 			ps.client.genqlient,
 			input,
 		)
@@ -273,9 +401,15 @@ func (ps *pipelineSchedule) Update(ctx context.Context, req resource.UpdateReque
 			"Unable to update Pipeline schedule",
 			fmt.Sprintf("Unable to update Pipeline schedule: %s", err.Error()),
 		)
+		return
 	}
 
 	plan.Id = state.Id
+	if plan.NextRuns.IsUnknown() {
+		node := apiResponse.PipelineScheduleUpdate.PipelineSchedule
+		plan.NextRunAt, plan.NextRuns, diags = pipelineScheduleRuns(ctx, plan.Cronline.ValueString(), plan.Enabled.ValueBool(), node.NextBuildAt)
+		resp.Diagnostics.Append(diags...)
+	}
 	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 }
 
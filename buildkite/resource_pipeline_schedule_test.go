@@ -6,7 +6,6 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -68,8 +67,8 @@ func TestAccBuildkitePipelineSchedule(t *testing.T) {
 	t.Run("pipeline schedule can be created", func(t *testing.T) {
 		var pipeline getPipelinePipeline
 		var schedule PipelineScheduleValues
-		pipelineName := acctest.RandString(12)
-		label := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		label := randString(t, 12)
 		cronline := "0 * * * *"
 
 		resource.ParallelTest(t, resource.TestCase{
@@ -104,6 +103,8 @@ func TestAccBuildkitePipelineSchedule(t *testing.T) {
 						resource.TestCheckResourceAttr("buildkite_pipeline_schedule.pipeline", "branch", "main"),
 						resource.TestCheckResourceAttr("buildkite_pipeline_schedule.pipeline", "env.FOO", "BAR=2f"),
 						resource.TestCheckResourceAttr("buildkite_pipeline_schedule.pipeline", "enabled", "true"),
+						resource.TestCheckResourceAttrSet("buildkite_pipeline_schedule.pipeline", "next_run_at"),
+						resource.TestCheckResourceAttr("buildkite_pipeline_schedule.pipeline", "next_runs.#", "5"),
 					),
 				},
 				{
@@ -119,8 +120,8 @@ func TestAccBuildkitePipelineSchedule(t *testing.T) {
 	t.Run("pipeline schedule can be updated", func(t *testing.T) {
 		var pipeline getPipelinePipeline
 		var schedule PipelineScheduleValues
-		pipelineName := acctest.RandString(12)
-		label := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		label := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -186,8 +187,8 @@ func TestAccBuildkitePipelineSchedule(t *testing.T) {
 	})
 
 	t.Run("pipeline schedule env transitions between empty and populated", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
-		label := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		label := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -220,8 +221,8 @@ func TestAccBuildkitePipelineSchedule(t *testing.T) {
 	})
 
 	t.Run("pipeline schedule is recreated if removed", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
-		label := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		label := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -6,7 +6,6 @@ import (
 	"regexp"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -15,7 +14,7 @@ import (
 func TestAccBuildkitePipelineTeam(t *testing.T) {
 	t.Run("pipeline team can be created", func(t *testing.T) {
 		var tp pipelineTeamResourceModel
-		teamName := acctest.RandString(12)
+		teamName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -53,8 +52,8 @@ func TestAccBuildkitePipelineTeam(t *testing.T) {
 
 	t.Run("pipeline team can be updated", func(t *testing.T) {
 		var tp pipelineTeamResourceModel
-		teamName := acctest.RandString(12)
-		teamNameNew := acctest.RandString(12)
+		teamName := randString(t, 12)
+		teamNameNew := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -104,7 +103,7 @@ func TestAccBuildkitePipelineTeam(t *testing.T) {
 	})
 
 	t.Run("duplicate pipeline team returns helpful error", func(t *testing.T) {
-		teamName := acctest.RandString(12)
+		teamName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -119,7 +118,7 @@ func TestAccBuildkitePipelineTeam(t *testing.T) {
 	})
 
 	t.Run("pipeline team is recreated if removed", func(t *testing.T) {
-		teamName := acctest.RandString(12)
+		teamName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -6,7 +6,6 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -51,7 +50,7 @@ func TestAccBuildkitePipelineTemplateResource(t *testing.T) {
 	}
 
 	t.Run("creates a pipeline template with required attributes", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		var ptr pipelineTemplateResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -80,7 +79,7 @@ func TestAccBuildkitePipelineTemplateResource(t *testing.T) {
 	})
 
 	t.Run("creates a pipeline template with all attributes", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		var ptr pipelineTemplateResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -111,7 +110,7 @@ func TestAccBuildkitePipelineTemplateResource(t *testing.T) {
 	})
 
 	t.Run("updates a pipeline template", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		var ptr pipelineTemplateResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -155,7 +154,7 @@ func TestAccBuildkitePipelineTemplateResource(t *testing.T) {
 	})
 
 	t.Run("imports a pipeline template", func(t *testing.T) {
-		randName := acctest.RandString(12)
+		randName := randString(t, 12)
 		var ptr pipelineTemplateResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -8,10 +8,12 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -283,7 +285,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("create pipeline with only required attributes", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -347,7 +349,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("update pipeline with only required attributes", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -397,8 +399,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("create pipeline with user defined slug", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
-		slugName := strings.ToLower(acctest.RandString(12))
+		pipelineName := randString(t, 12)
+		slugName := strings.ToLower(randString(t, 12))
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -458,9 +460,9 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("update pipeline with user defined slug", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
-		slugName := strings.ToLower(acctest.RandString(12))
-		updatedSlugName := strings.ToLower(acctest.RandString(12))
+		pipelineName := randString(t, 12)
+		slugName := strings.ToLower(randString(t, 12))
+		updatedSlugName := strings.ToLower(randString(t, 12))
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -514,8 +516,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("set user defined slug for existing pipeline", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
-		slugName := strings.ToLower(acctest.RandString(12))
+		pipelineName := randString(t, 12)
+		slugName := strings.ToLower(randString(t, 12))
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -567,9 +569,9 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("remove user defined slug from existing pipeline", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineId := acctest.RandString(12)
+		pipelineId := randString(t, 12)
 		pipelineName := fmt.Sprintf("TesT --- PipeLine - %s", pipelineId)
-		slugName := strings.ToLower(acctest.RandString(12))
+		slugName := strings.ToLower(randString(t, 12))
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -624,8 +626,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("create pipeline with a pipeline template", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
-		templateName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		templateName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline_template" "template_foo" {
 				name = "Template %s"
@@ -695,7 +697,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("create pipeline with empty attributes", func(t *testing.T) {
 		var pipeline *getPipelinePipeline
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -743,8 +745,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("create pipeline setting all attributes", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
-		clusterName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		clusterName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_cluster" "cluster" {
 				name = "%s"
@@ -905,8 +907,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 
 	t.Run("update pipeline setting all attributes", func(t *testing.T) {
 		var pipeline getPipelinePipeline
-		pipelineName := acctest.RandString(12)
-		clusterName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		clusterName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1036,9 +1038,9 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("changing cluster_id updates cluster_name without inconsistency error", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
-		clusterNameA := acctest.RandString(12)
-		clusterNameB := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		clusterNameA := randString(t, 12)
+		clusterNameB := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1085,7 +1087,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("pipeline is recreated if removed", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1119,7 +1121,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("pipeline can be deleted", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1139,8 +1141,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("pipeline with cluster can be deleted", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
-		clusterName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		clusterName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1164,7 +1166,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("empty provider_settings updated from v0 to v1", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
@@ -1218,7 +1220,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("filled provider_settings updated from v0 to v1", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
@@ -1328,8 +1330,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("provider_settings attributes can be removed without state change", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
-		clusterName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
+		clusterName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1379,7 +1381,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("provider_settings produces empty plan on re-apply", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1409,8 +1411,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("create in template mode and change template configuration afterwards", func(t *testing.T) {
-		templateName := acctest.RandString(12)
-		pipelineName := acctest.RandString(12)
+		templateName := randString(t, 12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1461,8 +1463,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("create in template mode and change to explicit steps mode", func(t *testing.T) {
-		templateName := acctest.RandString(12)
-		pipelineName := acctest.RandString(12)
+		templateName := randString(t, 12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1507,8 +1509,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("create in template mode and change to implicit steps mode", func(t *testing.T) {
-		templateName := acctest.RandString(12)
-		pipelineName := acctest.RandString(12)
+		templateName := randString(t, 12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1552,8 +1554,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("create in implicit steps mode and change to template mode", func(t *testing.T) {
-		templateName := acctest.RandString(12)
-		pipelineName := acctest.RandString(12)
+		templateName := randString(t, 12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1595,8 +1597,8 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("create in explicit steps mode and change to template mode", func(t *testing.T) {
-		templateName := acctest.RandString(12)
-		pipelineName := acctest.RandString(12)
+		templateName := randString(t, 12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1639,7 +1641,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("reject conditional expressions in branch filter fields", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1724,7 +1726,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("validate regex patterns in filter_condition", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -1785,7 +1787,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("creates pipeline with PUBLIC visibility", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1821,7 +1823,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("creates pipeline with PRIVATE visibility", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1857,7 +1859,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("creates pipeline with default visibility when not specified", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1892,7 +1894,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("updates pipeline visibility from PRIVATE to PUBLIC", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		configPrivate := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1946,7 +1948,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("rejects invalid visibility values", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -1968,7 +1970,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("creates pipeline with archived = true", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -2004,7 +2006,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("updates pipeline archived state", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		configUnarchived := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -2083,7 +2085,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 		// Regression test: archiving must happen after the provider_settings REST
 		// PATCH, otherwise the API rejects it with "Cannot update an archived
 		// pipeline".
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		configFor := func(archived bool) string {
 			return fmt.Sprintf(`
 				resource "buildkite_pipeline" "pipeline" {
@@ -2137,7 +2139,7 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 	})
 
 	t.Run("creates archived pipeline with provider_settings", func(t *testing.T) {
-		pipelineName := acctest.RandString(12)
+		pipelineName := randString(t, 12)
 		config := fmt.Sprintf(`
 			resource "buildkite_pipeline" "pipeline" {
 				name = "%s"
@@ -2176,3 +2178,142 @@ func TestAccBuildkitePipelineResource(t *testing.T) {
 		})
 	})
 }
+
+// fakePipelineBuilds serves the builds of one pipeline to cancelPipelineBuilds, two to a page.
+// Cancelling a build moves it to CANCELING, and a CANCELING build has finished by the next listing.
+func fakePipelineBuilds(server *fakebuildkite.Server, builds []fakebuildkite.Object, cancel func(build fakebuildkite.Object) error) {
+	server.HandleGraphQL("getPipelineUnfinishedBuilds", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		var unfinished []fakebuildkite.Object
+		for _, build := range builds {
+			switch build["state"] {
+			case "CANCELING":
+				unfinished = append(unfinished, fakebuildkite.Object{"id": build["id"], "number": build["number"], "state": "CANCELING", "url": build["url"]})
+				build["state"] = "CANCELED"
+			case "SCHEDULED", "RUNNING":
+				unfinished = append(unfinished, build)
+			}
+		}
+
+		start := 0
+		if cursor, _ := variables["cursor"].(string); cursor != "" {
+			fmt.Sscan(cursor, &start)
+		}
+		end := min(start+2, len(unfinished))
+		edges := []fakebuildkite.Object{}
+		for _, build := range unfinished[start:end] {
+			edges = append(edges, fakebuildkite.Object{"node": build})
+		}
+
+		return fakebuildkite.Object{"node": fakebuildkite.Object{
+			"__typename": "Pipeline",
+			"builds": fakebuildkite.Object{
+				"pageInfo": fakebuildkite.Object{"endCursor": fmt.Sprint(end), "hasNextPage": end < len(unfinished)},
+				"edges":    edges,
+			},
+		}}, nil
+	})
+	server.HandleGraphQL("cancelBuild", func(st *fakebuildkite.State, variables map[string]interface{}) (interface{}, error) {
+		for _, build := range builds {
+			if build["id"] != variables["id"] {
+				continue
+			}
+			if err := cancel(build); err != nil {
+				return nil, err
+			}
+			build["state"] = "CANCELING"
+			return fakebuildkite.Object{"buildCancel": fakebuildkite.Object{"build": fakebuildkite.Object{"id": build["id"], "state": "CANCELING"}}}, nil
+		}
+		return nil, fmt.Errorf("build not found")
+	})
+}
+
+func TestCancelPipelineBuilds(t *testing.T) {
+	build := func(number int, state string) fakebuildkite.Object {
+		return fakebuildkite.Object{
+			"id":     fmt.Sprintf("QnVpbGQtLS0%d", number),
+			"number": number,
+			"state":  state,
+			"url":    fmt.Sprintf("https://buildkite.com/test-org/preview/builds/%d", number),
+		}
+	}
+
+	t.Run("cancels unfinished builds and waits for them", func(t *testing.T) {
+		server := fakebuildkite.New(t, "test-org")
+		builds := []fakebuildkite.Object{build(1, "PASSED"), build(2, "RUNNING"), build(3, "CANCELING"), build(4, "SCHEDULED"), build(5, "RUNNING")}
+		fakePipelineBuilds(server, builds, func(build fakebuildkite.Object) error {
+			// Build 5 finishes between being listed and being cancelled.
+			if build["number"] == 5 {
+				build["state"] = "PASSED"
+				return fmt.Errorf("Build can't be canceled because it's already finished")
+			}
+			return nil
+		})
+
+		cancelled, err := cancelPipelineBuilds(context.Background(), newFakeClient(server), "UGlwZWxpbmUtLS0x", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []string{"https://buildkite.com/test-org/preview/builds/2", "https://buildkite.com/test-org/preview/builds/4"}
+		if !slices.Equal(cancelled, want) {
+			t.Errorf("cancelled %v, want %v", cancelled, want)
+		}
+		for _, b := range builds {
+			if state := b["state"]; state != "PASSED" && state != "CANCELED" {
+				t.Errorf("build %v is still %s", b["number"], state)
+			}
+		}
+	})
+
+	t.Run("gives up on a build that cannot be cancelled", func(t *testing.T) {
+		server := fakebuildkite.New(t, "test-org")
+		fakePipelineBuilds(server, []fakebuildkite.Object{build(1, "RUNNING")}, func(fakebuildkite.Object) error {
+			return fmt.Errorf("You don't have permission to cancel this build")
+		})
+
+		_, err := cancelPipelineBuilds(context.Background(), newFakeClient(server), "UGlwZWxpbmUtLS0x", time.Minute)
+		if err == nil || !strings.Contains(err.Error(), "unable to cancel https://buildkite.com/test-org/preview/builds/1") {
+			t.Errorf("error = %v, want the build that could not be cancelled", err)
+		}
+	})
+}
+
+func TestUnitBuildkitePipelineOnDelete(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+	builds := []fakebuildkite.Object{{"id": "QnVpbGQtLS0x", "number": 1, "state": "RUNNING", "url": "https://buildkite.com/test-org/preview/builds/1"}}
+	fakePipelineBuilds(server, builds, func(fakebuildkite.Object) error { return nil })
+
+	config := func(onDelete string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_pipeline" "pipeline" {
+				name       = "preview"
+				repository = "https://github.com/buildkite/terraform-provider-buildkite.git"
+				on_delete  = %q
+			}
+		`, onDelete)
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy: func(*terraform.State) error {
+			if state := builds[0]["state"]; state != "CANCELED" {
+				return fmt.Errorf("build is %s after destroy, want CANCELED", state)
+			}
+			return nil
+		},
+		Steps: []resource.TestStep{
+			{
+				Config: config(pipelineOnDeleteCancelBuilds),
+				Check:  resource.TestCheckResourceAttr("buildkite_pipeline.pipeline", "on_delete", pipelineOnDeleteCancelBuilds),
+			},
+			{
+				Config: config(pipelineOnDeleteFail),
+				Check:  resource.TestCheckResourceAttr("buildkite_pipeline.pipeline", "on_delete", pipelineOnDeleteFail),
+			},
+			{
+				Config: config(pipelineOnDeleteCancelBuilds),
+				Check:  resource.TestCheckResourceAttr("buildkite_pipeline.pipeline", "on_delete", pipelineOnDeleteCancelBuilds),
+			},
+		},
+	})
+}
@@ -12,20 +12,23 @@ import (
 	resource_schema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 type portalResourceModel struct {
-	UUID               types.String `tfsdk:"uuid"`
-	Slug               types.String `tfsdk:"slug"`
-	Name               types.String `tfsdk:"name"`
-	Description        types.String `tfsdk:"description"`
-	Query              types.String `tfsdk:"query"`
-	AllowedIPAddresses types.String `tfsdk:"allowed_ip_addresses"`
-	UserInvokable      types.Bool   `tfsdk:"user_invokable"`
-	Token              types.String `tfsdk:"token"`
-	CreatedAt          types.String `tfsdk:"created_at"`
-	CreatedBy          types.Object `tfsdk:"created_by"`
+	UUID                  types.String `tfsdk:"uuid"`
+	Slug                  types.String `tfsdk:"slug"`
+	Name                  types.String `tfsdk:"name"`
+	Description           types.String `tfsdk:"description"`
+	Query                 types.String `tfsdk:"query"`
+	Variables             types.List   `tfsdk:"variables"`
+	AllowedIPAddresses    types.String `tfsdk:"allowed_ip_addresses"`
+	UserInvokable         types.Bool   `tfsdk:"user_invokable"`
+	Token                 types.String `tfsdk:"token"`
+	TokenRotationTriggers types.Map    `tfsdk:"token_rotation_triggers"`
+	CreatedAt             types.String `tfsdk:"created_at"`
+	CreatedBy             types.Object `tfsdk:"created_by"`
 }
 
 type portalAPIResponse struct {
@@ -107,7 +110,30 @@ func (p *portalResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			},
 			"query": resource_schema.StringAttribute{
 				Required:            true,
-				MarkdownDescription: "The GraphQL query that the portal executes.",
+				MarkdownDescription: "The GraphQL query that the portal executes. Invalid GraphQL fails the plan, and fields, arguments or types missing from the Buildkite GraphQL schema bundled with the provider are reported as warnings.",
+				Validators: []validator.String{
+					portalQueryValidator{},
+				},
+			},
+			"variables": resource_schema.ListNestedAttribute{
+				Computed:            true,
+				MarkdownDescription: "The variables the query declares, which callers pass when invoking the portal.",
+				NestedObject: resource_schema.NestedAttributeObject{
+					Attributes: map[string]resource_schema.Attribute{
+						"name": resource_schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The name of the variable, without the leading `$`.",
+						},
+						"type": resource_schema.StringAttribute{
+							Computed:            true,
+							MarkdownDescription: "The GraphQL type of the variable, for example `ID!`.",
+						},
+						"required": resource_schema.BoolAttribute{
+							Computed:            true,
+							MarkdownDescription: "Whether callers must pass the variable: its type is non-null and it has no default.",
+						},
+					},
+				},
 			},
 			"allowed_ip_addresses": resource_schema.StringAttribute{
 				Optional:            true,
@@ -121,11 +147,16 @@ func (p *portalResource) Schema(ctx context.Context, req resource.SchemaRequest,
 			"token": resource_schema.StringAttribute{
 				Computed:            true,
 				Sensitive:           true,
-				MarkdownDescription: "The token used to invoke the portal. Only returned on creation.",
+				MarkdownDescription: "The token used to invoke the portal. Only returned on creation and when `token_rotation_triggers` changes.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"token_rotation_triggers": resource_schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Arbitrary values that regenerate the portal's token in place whenever they change, for example a date to rotate on a schedule. The portal keeps its UUID and slug; only the old token stops working.",
+			},
 			"created_at": resource_schema.StringAttribute{
 				Computed:            true,
 				MarkdownDescription: "The time when the portal was created.",
@@ -152,6 +183,35 @@ func (p *portalResource) Schema(ctx context.Context, req resource.SchemaRequest,
 	}
 }
 
+func (p *portalResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var query types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("query"), &query)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("variables"), portalQueryVariables(query))...)
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var planTriggers, stateTriggers types.Map
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("token_rotation_triggers"), &planTriggers)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("token_rotation_triggers"), &stateTriggers)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if tokenRotationDue(planTriggers, stateTriggers) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("token"), types.StringUnknown())...)
+	}
+}
+
 func (p *portalResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan portalResourceModel
 
@@ -174,6 +234,7 @@ func (p *portalResource) Create(ctx context.Context, req resource.CreateRequest,
 	plan.Slug = types.StringValue(result.Slug)
 	plan.Name = types.StringValue(result.Name)
 	plan.Query = types.StringValue(result.Query)
+	plan.Variables = portalQueryVariables(plan.Query)
 	plan.UserInvokable = types.BoolValue(result.UserInvokable)
 	plan.CreatedAt = types.StringValue(result.CreatedAt)
 
@@ -247,6 +308,7 @@ func (p *portalResource) Read(ctx context.Context, req resource.ReadRequest, res
 	state.Slug = types.StringValue(result.Slug)
 	state.Name = types.StringValue(result.Name)
 	state.Query = types.StringValue(result.Query)
+	state.Variables = portalQueryVariables(state.Query)
 	state.UserInvokable = types.BoolValue(result.UserInvokable)
 	state.CreatedAt = types.StringValue(result.CreatedAt)
 
@@ -299,6 +361,12 @@ func (p *portalResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	var state portalResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	result, err := p.updatePortal(ctx, &plan)
 	if err != nil {
 		resp.Diagnostics.AddError(
@@ -308,10 +376,29 @@ func (p *portalResource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
+	if tokenRotationDue(plan.TokenRotationTriggers, state.TokenRotationTriggers) {
+		result, err = p.regeneratePortalToken(ctx, plan.Slug.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Unable to rotate portal token",
+				fmt.Sprintf("The portal was updated but its token was not rotated: %s", err.Error()),
+			)
+			return
+		}
+		if result.Token == nil {
+			resp.Diagnostics.AddError(
+				"Unable to rotate portal token",
+				"The portal's token was regenerated, but Buildkite did not return the new token. The next apply regenerates it again.",
+			)
+			return
+		}
+	}
+
 	plan.UUID = types.StringValue(result.UUID)
 	plan.Slug = types.StringValue(result.Slug)
 	plan.Name = types.StringValue(result.Name)
 	plan.Query = types.StringValue(result.Query)
+	plan.Variables = portalQueryVariables(plan.Query)
 	plan.UserInvokable = types.BoolValue(result.UserInvokable)
 	plan.CreatedAt = types.StringValue(result.CreatedAt)
 
@@ -448,6 +535,18 @@ func (p *portalResource) updatePortal(ctx context.Context, plan *portalResourceM
 	return &result, nil
 }
 
+func (p *portalResource) regeneratePortalToken(ctx context.Context, slug string) (*portalAPIResponse, error) {
+	path := fmt.Sprintf("/v2/organizations/%s/portals/%s/regenerate_token", p.client.organization, slug)
+
+	var result portalAPIResponse
+	err := p.client.makeRequest(ctx, http.MethodPost, path, nil, &result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
 func (p *portalResource) deletePortal(ctx context.Context, slug string) error {
 	path := fmt.Sprintf("/v2/organizations/%s/portals/%s", p.client.organization, slug)
 
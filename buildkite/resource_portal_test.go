@@ -4,13 +4,89 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"regexp"
+	"strings"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
+func TestUnitBuildkitePortalTokenRotation(t *testing.T) {
+	server := fakebuildkite.New(t, "test-org")
+
+	config := func(name, rotated string) string {
+		return fakeProviderConfig(server) + fmt.Sprintf(`
+			resource "buildkite_portal" "viewer" {
+				slug  = "viewer"
+				name  = %q
+				query = "{ viewer { user { name } } }"
+
+				token_rotation_triggers = {
+					rotated = %q
+				}
+			}
+		`, name, rotated)
+	}
+
+	var uuid, token string
+	capture := func(s *terraform.State) error {
+		attributes := s.RootModule().Resources["buildkite_portal.viewer"].Primary.Attributes
+		uuid, token = attributes["uuid"], attributes["token"]
+		return nil
+	}
+
+	resource.UnitTest(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: config("Viewer", "2026-01"),
+				Check:  capture,
+			},
+			{
+				// Other changes keep the token.
+				Config: config("Viewer details", "2026-01"),
+				Check: func(s *terraform.State) error {
+					if got := s.RootModule().Resources["buildkite_portal.viewer"].Primary.Attributes["token"]; got != token {
+						return fmt.Errorf("token changed to %q without a rotation", got)
+					}
+					return nil
+				},
+			},
+			{
+				Config: config("Viewer details", "2026-02"),
+				Check: func(s *terraform.State) error {
+					attributes := s.RootModule().Resources["buildkite_portal.viewer"].Primary.Attributes
+					if attributes["uuid"] != uuid {
+						return fmt.Errorf("uuid changed from %q to %q, want the portal rotated in place", uuid, attributes["uuid"])
+					}
+					if attributes["token"] == "" || attributes["token"] == token {
+						return fmt.Errorf("token = %q, want a new token", attributes["token"])
+					}
+					return nil
+				},
+			},
+			{
+				PreConfig: func() {
+					server.HandleREST(http.MethodPost, "/v2/organizations/{org}/portals/{slug}/regenerate_token", func(st *fakebuildkite.State, req *fakebuildkite.RESTRequest) (int, interface{}) {
+						portal, _ := st.Object(strings.TrimSuffix(req.Path, "/regenerate_token"))
+						withoutToken := fakebuildkite.Object{}
+						for key, value := range portal {
+							if key != "token" {
+								withoutToken[key] = value
+							}
+						}
+						return http.StatusOK, withoutToken
+					})
+				},
+				Config:      config("Viewer details", "2026-03"),
+				ExpectError: regexp.MustCompile("Buildkite did not return the new token"),
+			},
+		},
+	})
+}
+
 func TestAccBuildkitePortal(t *testing.T) {
 	basic := func(name string) string {
 		return fmt.Sprintf(`
@@ -34,7 +110,7 @@ func TestAccBuildkitePortal(t *testing.T) {
 	}
 
 	t.Run("creates a portal", func(t *testing.T) {
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			testAccCheckPortalExists("buildkite_portal.test"),
@@ -73,7 +149,7 @@ func TestAccBuildkitePortal(t *testing.T) {
 	})
 
 	t.Run("updates a portal", func(t *testing.T) {
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 		updated := func(name string) string {
 			return fmt.Sprintf(`
 			provider "buildkite" {
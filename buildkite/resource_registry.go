@@ -1,15 +1,15 @@
 package buildkite
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 
 	"github.com/MakeNowJust/heredoc"
 	bkplanmodifier "github.com/buildkite/terraform-provider-buildkite/internal/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -17,8 +17,13 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+)
+
+const (
+	registryTypeSource    = "source"
+	registryTypeComposite = "composite"
 )
 
 type registryResource struct {
@@ -34,6 +39,7 @@ type registryResourceModel struct {
 	Emoji        types.String `tfsdk:"emoji"`
 	Color        types.String `tfsdk:"color"`
 	OIDCPolicy   types.String `tfsdk:"oidc_policy"`
+	OIDCRules    types.List   `tfsdk:"oidc_policy_rules"`
 	Public       types.Bool   `tfsdk:"public"`
 	RegistryType types.String `tfsdk:"registry_type"`
 	Slug         types.String `tfsdk:"slug"`
@@ -56,6 +62,15 @@ func (p *registryResource) Configure(ctx context.Context, req resource.Configure
 	p.client = req.ProviderData.(*Client)
 }
 
+func (p *registryResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(
+			path.MatchRoot("oidc_policy"),
+			path.MatchRoot("oidc_policy_rules"),
+		),
+	}
+}
+
 func (p *registryResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = resource_schema.Schema{
 		MarkdownDescription: heredoc.Doc(`
@@ -113,8 +128,10 @@ func (p *registryResource) Schema(ctx context.Context, req resource.SchemaReques
 			},
 			"oidc_policy": resource_schema.StringAttribute{
 				Optional:            true,
-				MarkdownDescription: "The registry's OIDC policy, in YAML format.",
+				Computed:            true,
+				MarkdownDescription: "The registry's OIDC policy, in YAML format. Conflicts with `oidc_policy_rules`, which is rendered here when configured. Problems found at plan time, such as claims Buildkite agent tokens do not have, are reported as warnings, and the policy is compared by meaning on refresh.",
 			},
+			"oidc_policy_rules": oidcPolicyRulesSchema("registry", "read_packages"),
 			"public": resource_schema.BoolAttribute{
 				Computed:            true,
 				MarkdownDescription: "Whether the registry is publicly accessible.",
@@ -123,8 +140,12 @@ func (p *registryResource) Schema(ctx context.Context, req resource.SchemaReques
 				},
 			},
 			"registry_type": resource_schema.StringAttribute{
+				Optional:            true,
 				Computed:            true,
-				MarkdownDescription: "The type of the registry (e.g. `source`).",
+				MarkdownDescription: "The type of the registry, either `source` or `composite`. Defaults to `source`. The upstreams of a composite registry are managed in Buildkite. This value cannot be changed after creation.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(registryTypeSource, registryTypeComposite),
+				},
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
@@ -149,87 +170,13 @@ func (p *registryResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
-	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
-		url := fmt.Sprintf("%s/v2/packages/organizations/%s/registries", p.client.restURL, p.client.organization)
-
-		reqBody := map[string]interface{}{
-			"name":      state.Name.ValueString(),
-			"ecosystem": state.Ecosystem.ValueString(),
-		}
-
-		// Add optional fields if they're set
-		if !state.Description.IsNull() && !state.Description.IsUnknown() {
-			reqBody["description"] = state.Description.ValueString()
-		}
-		if !state.Emoji.IsNull() && !state.Emoji.IsUnknown() {
-			reqBody["emoji"] = state.Emoji.ValueString()
-		}
-		if !state.Color.IsNull() && !state.Color.IsUnknown() {
-			reqBody["color"] = state.Color.ValueString()
-		}
-		if !state.OIDCPolicy.IsNull() && !state.OIDCPolicy.IsUnknown() {
-			reqBody["oidc_policy"] = state.OIDCPolicy.ValueString()
-		}
-
-		// Get team IDs from the state
-		if !state.TeamIDs.IsNull() && !state.TeamIDs.IsUnknown() {
-			teamIDs := make([]string, 0)
-			teamIDsElements := state.TeamIDs.Elements()
-
-			for _, element := range teamIDsElements {
-				if strVal, ok := element.(types.String); ok {
-					teamIDs = append(teamIDs, strVal.ValueString())
-				}
-			}
-
-			if len(teamIDs) > 0 {
-				reqBody["team_ids"] = teamIDs
-			}
-		}
-
-		// Marshal the request body to JSON
-		jsonBody, err := json.Marshal(reqBody)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error marshaling request body: %w", err))
-		}
-
-		// Create the HTTP request
-		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonBody))
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error creating HTTP request: %w", err))
-		}
-
-		// Set request headers
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", "application/json")
-
-		// Execute the HTTP request
-		resp, err := p.client.http.Do(req)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error making HTTP request: %w", err))
-		}
-		defer resp.Body.Close()
-
-		// Check for successful response
-		if resp.StatusCode >= 400 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return retry.NonRetryableError(fmt.Errorf("error creating registry (status %d): %s", resp.StatusCode, string(bodyBytes)))
-		}
-
-		// Parse the response
-		var result registryAPIResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error decoding response body: %w", err))
-		}
-
-		if result.ID == "" {
-			return retry.NonRetryableError(fmt.Errorf("API response missing required ID field"))
-		}
-
-		mapRegistryResponseToModel(&result, state)
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-		return nil
-	})
+	result, err := p.create(requestCtx, state.createPayload())
+	if err == nil && result.ID == "" {
+		err = fmt.Errorf("API response missing required ID field")
+	}
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error creating registry",
@@ -238,25 +185,17 @@ func (p *registryResource) Create(ctx context.Context, req resource.CreateReques
 		return
 	}
 
+	mapRegistryResponseToModel(result, state)
+
 	// Make sure to save state properly including the UUID
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-// Implementation for Read function with team_ids handling
 func (p *registryResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
-	var registryNotFound bool
 	var state *registryResourceModel
 
 	diags := req.State.Get(ctx, &state)
 	resp.Diagnostics.Append(diags...)
-
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	timeout, diags := p.client.timeouts.Read(ctx, DefaultTimeout)
-	resp.Diagnostics.Append(diags...)
-
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -269,52 +208,16 @@ func (p *registryResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
-	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
-		url := fmt.Sprintf("%s/v2/packages/organizations/%s/registries/%s", p.client.restURL, p.client.organization, state.Slug.ValueString())
-
-		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error creating HTTP request: %w", err))
-		}
-
-		req.Header.Set("Accept", "application/json")
-
-		resp, err := p.client.http.Do(req)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error making HTTP request: %w", err))
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode == http.StatusNotFound {
-			registryNotFound = true
-			return nil
-		}
-
-		if resp.StatusCode >= 400 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return retry.NonRetryableError(fmt.Errorf("error reading registry (status %d): %s", resp.StatusCode, string(bodyBytes)))
-		}
-
-		var result registryAPIResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error decoding response body: %w", err))
-		}
-
-		mapRegistryResponseToModel(&result, state)
-
-		return nil
-	})
-
-	if registryNotFound {
-		resp.Diagnostics.AddWarning(
-			"Registry not found",
-			fmt.Sprintf("Registry %s was not found, removing from state", state.Slug.ValueString()),
-		)
-		resp.State.RemoveResource(ctx)
-		return
-	}
-
+	result, err := p.get(ctx, state.Slug.ValueString())
 	if err != nil {
+		if isAPIStatus(err, http.StatusNotFound) {
+			resp.Diagnostics.AddWarning(
+				"Registry not found",
+				fmt.Sprintf("Registry %s was not found, removing from state", state.Slug.ValueString()),
+			)
+			resp.State.RemoveResource(ctx)
+			return
+		}
 		resp.Diagnostics.AddError(
 			"Error reading registry",
 			fmt.Sprintf("Could not read registry: %s", err),
@@ -322,10 +225,12 @@ func (p *registryResource) Read(ctx context.Context, req resource.ReadRequest, r
 		return
 	}
 
+	mapRegistryResponseToModel(result, state)
+
+	resp.Diagnostics.Append(refreshOIDCPolicyRules(ctx, state.OIDCPolicy, &state.OIDCRules)...)
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
-// Implementation for Update function with team_ids handling
 func (p *registryResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var plan, state *registryResourceModel
 
@@ -354,78 +259,111 @@ func (p *registryResource) Update(ctx context.Context, req resource.UpdateReques
 		return
 	}
 
-	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
-		url := fmt.Sprintf("%s/v2/packages/organizations/%s/registries/%s", p.client.restURL, p.client.organization, state.Slug.ValueString())
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-		reqBody := map[string]interface{}{
-			"name": plan.Name.ValueString(),
+	result, err := p.update(requestCtx, state.Slug.ValueString(), plan.updatePayload(state))
+	if err != nil {
+		detail := fmt.Sprintf("Could not update registry: %s", err)
+		if isAPIStatus(err, http.StatusNotFound) {
+			detail = fmt.Sprintf("Could not update registry: registry %s not found, it may have been deleted outside of Terraform", state.Slug.ValueString())
 		}
+		resp.Diagnostics.AddError("Error updating registry", detail)
+		return
+	}
 
-		if !plan.Description.IsNull() && !plan.Description.IsUnknown() {
-			reqBody["description"] = plan.Description.ValueString()
-		} else if !state.Description.IsNull() {
-			reqBody["description"] = nil
-		}
-		if !plan.Emoji.IsNull() && !plan.Emoji.IsUnknown() {
-			reqBody["emoji"] = plan.Emoji.ValueString()
-		} else if !state.Emoji.IsNull() {
-			reqBody["emoji"] = nil
-		}
-		if !plan.Color.IsNull() && !plan.Color.IsUnknown() {
-			reqBody["color"] = plan.Color.ValueString()
-		} else if !state.Color.IsNull() {
-			reqBody["color"] = nil
-		}
-		if !plan.OIDCPolicy.IsNull() && !plan.OIDCPolicy.IsUnknown() {
-			reqBody["oidc_policy"] = plan.OIDCPolicy.ValueString()
-		} else if !state.OIDCPolicy.IsNull() {
-			reqBody["oidc_policy"] = nil
-		}
+	mapRegistryResponseToModel(result, plan)
 
-		jsonBody, err := json.Marshal(reqBody)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error marshaling request body: %w", err))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+// createPayload builds the body of a create from the planned registry, leaving out what is unset.
+func (m *registryResourceModel) createPayload() map[string]any {
+	payload := map[string]any{
+		"name":      m.Name.ValueString(),
+		"ecosystem": m.Ecosystem.ValueString(),
+	}
+
+	optional := map[string]types.String{
+		"description": m.Description,
+		"emoji":       m.Emoji,
+		"color":       m.Color,
+		"oidc_policy": m.OIDCPolicy,
+		"type":        m.RegistryType,
+	}
+	for key, value := range optional {
+		if !value.IsNull() && !value.IsUnknown() {
+			payload[key] = value.ValueString()
 		}
+	}
 
-		req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewBuffer(jsonBody))
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error creating HTTP request: %w", err))
+	var teamIDs []string
+	for _, element := range m.TeamIDs.Elements() {
+		if id, ok := element.(types.String); ok {
+			teamIDs = append(teamIDs, id.ValueString())
 		}
+	}
+	if len(teamIDs) > 0 {
+		payload["team_ids"] = teamIDs
+	}
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", "application/json")
+	return payload
+}
 
-		resp, err := p.client.http.Do(req)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error making HTTP request: %w", err))
-		}
-		defer resp.Body.Close()
+// updatePayload builds the body of an update from the planned registry. Optional fields removed
+// from the configuration are sent as null so the API clears them.
+func (m *registryResourceModel) updatePayload(state *registryResourceModel) map[string]any {
+	payload := map[string]any{"name": m.Name.ValueString()}
 
-		if resp.StatusCode == http.StatusNotFound {
-			return retry.NonRetryableError(fmt.Errorf("registry %s not found, it may have been deleted outside of Terraform", state.Slug.ValueString()))
-		} else if resp.StatusCode >= 400 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return retry.NonRetryableError(fmt.Errorf("error updating registry (status %d): %s", resp.StatusCode, string(bodyBytes)))
+	optional := map[string][2]types.String{
+		"description": {m.Description, state.Description},
+		"emoji":       {m.Emoji, state.Emoji},
+		"color":       {m.Color, state.Color},
+		"oidc_policy": {m.OIDCPolicy, state.OIDCPolicy},
+	}
+	for key, values := range optional {
+		planned, previous := values[0], values[1]
+		if !planned.IsNull() && !planned.IsUnknown() {
+			payload[key] = planned.ValueString()
+		} else if !previous.IsNull() {
+			payload[key] = nil
 		}
+	}
 
-		var result registryAPIResponse
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error decoding response body: %w", err))
-		}
+	return payload
+}
 
-		mapRegistryResponseToModel(&result, plan)
+func registryPath(organization, slug string) string {
+	return fmt.Sprintf("/v2/packages/organizations/%s/registries/%s", organization, url.PathEscape(slug))
+}
 
-		return nil
-	})
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Error updating registry",
-			fmt.Sprintf("Could not update registry: %s", err),
-		)
-		return
+func (p *registryResource) create(ctx context.Context, payload map[string]any) (*registryAPIResponse, error) {
+	path := fmt.Sprintf("/v2/packages/organizations/%s/registries", p.client.organization)
+	var result registryAPIResponse
+	if err := p.client.makeRequest(ctx, http.MethodPost, path, payload, &result); err != nil {
+		return nil, err
 	}
+	return &result, nil
+}
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+func (p *registryResource) get(ctx context.Context, slug string) (*registryAPIResponse, error) {
+	var result registryAPIResponse
+	if err := p.client.makeRequest(ctx, http.MethodGet, registryPath(p.client.organization, slug), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *registryResource) update(ctx context.Context, slug string, payload map[string]any) (*registryAPIResponse, error) {
+	var result registryAPIResponse
+	if err := p.client.makeRequest(ctx, http.MethodPut, registryPath(p.client.organization, slug), payload, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (p *registryResource) delete(ctx context.Context, slug string) error {
+	return p.client.makeRequest(ctx, http.MethodDelete, registryPath(p.client.organization, slug), nil, nil)
 }
 
 // registryAPIResponse represents the JSON shape returned by the Packages REST API
@@ -455,7 +393,7 @@ func mapRegistryResponseToModel(result *registryAPIResponse, model *registryReso
 	model.Description = optionalStringValue(result.Description)
 	model.Emoji = optionalStringValue(result.Emoji)
 	model.Color = optionalStringValue(result.Color)
-	model.OIDCPolicy = optionalStringValue(result.OIDCPolicy)
+	model.OIDCPolicy = refreshedOIDCPolicy(model.OIDCPolicy, optionalStringValue(result.OIDCPolicy))
 	model.Public = types.BoolValue(result.Public)
 	model.RegistryType = types.StringValue(result.RegistryType)
 	model.TeamIDs = handleTeamIDs(result.TeamIDs, model.TeamIDs)
@@ -518,36 +456,12 @@ func (p *registryResource) Delete(ctx context.Context, req resource.DeleteReques
 		return
 	}
 
-	err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
-		url := fmt.Sprintf("%s/v2/packages/organizations/%s/registries/%s", p.client.restURL, p.client.organization, state.Slug.ValueString())
-
-		// Create the HTTP request
-		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error creating HTTP request: %w", err))
-		}
-
-		// Execute the HTTP request
-		resp, err := p.client.http.Do(req)
-		if err != nil {
-			return retry.NonRetryableError(fmt.Errorf("error making HTTP request: %w", err))
-		}
-		defer resp.Body.Close()
-
-		// If the registry was already deleted, consider the delete successful
-		if resp.StatusCode == http.StatusNotFound {
-			return nil
-		}
+	requestCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-		// Check for successful response
-		if resp.StatusCode >= 400 {
-			bodyBytes, _ := io.ReadAll(resp.Body)
-			return retry.NonRetryableError(fmt.Errorf("error deleting registry (status %d): %s", resp.StatusCode, string(bodyBytes)))
-		}
-
-		return nil
-	})
-	if err != nil {
+	// If the registry was already deleted, consider the delete successful
+	err := p.delete(requestCtx, state.Slug.ValueString())
+	if err != nil && !isAPIStatus(err, http.StatusNotFound) {
 		resp.Diagnostics.AddError(
 			"Error deleting registry",
 			fmt.Sprintf("Could not delete registry: %s", err),
@@ -574,6 +488,13 @@ func (r *registryResource) ModifyPlan(ctx context.Context, req resource.ModifyPl
 		return
 	}
 
+	var config registryResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	planOIDCPolicy(ctx, config.OIDCPolicy, config.OIDCRules, false, req, resp)
+
 	// Get the current state
 	if req.State.Raw.IsNull() {
 		// No state means this is a create, nothing to do
@@ -601,6 +522,13 @@ func (r *registryResource) ModifyPlan(ctx context.Context, req resource.ModifyPl
 		)
 	}
 
+	if !plan.RegistryType.IsUnknown() && !plan.RegistryType.Equal(state.RegistryType) {
+		resp.Diagnostics.AddError(
+			"Registry type change detected",
+			"The registry_type attribute cannot be changed after registry creation. This restriction is enforced by the Buildkite API.",
+		)
+	}
+
 	if !plan.TeamIDs.Equal(state.TeamIDs) {
 		resp.Diagnostics.AddError(
 			"Team IDs change detected",
@@ -1,6 +1,7 @@
 package buildkite
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -8,13 +9,60 @@ import (
 	"testing"
 	"time"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
+	"github.com/buildkite/terraform-provider-buildkite/internal/fakebuildkite"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
 
 const testRegistryTeamID = "31529c8a-7cfa-42e8-bb85-4c844a983ea0"
 
+func TestRegistryRequests(t *testing.T) {
+	t.Parallel()
+
+	server := fakebuildkite.New(t, "test-org")
+	registries := &registryResource{client: newFakeClient(server)}
+	ctx := context.Background()
+
+	model := &registryResourceModel{Name: types.StringValue("packages"), Ecosystem: types.StringValue("ruby"), Emoji: types.StringValue(":ruby:")}
+	created, err := registries.create(ctx, model.createPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.Slug != "packages" || created.RegistryType != registryTypeSource {
+		t.Errorf("created registry = %+v, want a source registry with slug packages", created)
+	}
+
+	// Removing an optional field sends null so that the API clears it.
+	updated, err := registries.update(ctx, created.Slug, (&registryResourceModel{Name: model.Name, Emoji: types.StringNull()}).updatePayload(model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Emoji != nil {
+		t.Errorf("emoji after removing it = %q, want it cleared", *updated.Emoji)
+	}
+
+	if err := registries.delete(ctx, created.Slug); err != nil {
+		t.Fatal(err)
+	}
+	_, err = registries.get(ctx, created.Slug)
+	if !isAPIStatus(err, http.StatusNotFound) {
+		t.Errorf("reading a deleted registry = %v, want a 404", err)
+	}
+	if err := registries.delete(ctx, created.Slug); !isAPIStatus(err, http.StatusNotFound) {
+		t.Errorf("deleting a deleted registry = %v, want a 404", err)
+	}
+
+	composite := &registryResourceModel{Name: types.StringValue("proxy"), Ecosystem: types.StringValue("npm"), RegistryType: types.StringValue(registryTypeComposite)}
+	created, err = registries.create(ctx, composite.createPayload())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.RegistryType != registryTypeComposite {
+		t.Errorf("created registry type = %q, want %q", created.RegistryType, registryTypeComposite)
+	}
+}
+
 func TestAccResourceRegistry(t *testing.T) {
 	config := func(name, ecosystem, emoji string) string {
 		return fmt.Sprintf(`
@@ -43,7 +91,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	}
 
 	t.Run("create and destroy", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 		ecosystem := "java"
 
 		resource.ParallelTest(t, resource.TestCase{
@@ -68,7 +116,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	})
 
 	t.Run("create with all fields", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -92,7 +140,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	})
 
 	t.Run("update", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 		ecosystem := "java"
 
 		resource.ParallelTest(t, resource.TestCase{
@@ -119,7 +167,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	})
 
 	t.Run("update description and color", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -145,7 +193,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	})
 
 	t.Run("reject ecosystem change", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -164,7 +212,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	})
 
 	t.Run("reject team_ids change", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 
 		configWithTeams := func(name, teamID string) string {
 			return fmt.Sprintf(`
@@ -194,7 +242,7 @@ func TestAccResourceRegistry(t *testing.T) {
 	})
 
 	t.Run("create with oidc policy", func(t *testing.T) {
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 
 		resource.ParallelTest(t, resource.TestCase{
 			PreCheck:                 func() { testAccPreCheck(t) },
@@ -228,7 +276,7 @@ func TestAccResourceRegistry(t *testing.T) {
 
 	t.Run("import", func(t *testing.T) {
 		var r registryResourceModel
-		randName := acctest.RandString(5)
+		randName := randString(t, 5)
 		ecosystem := "java"
 
 		resource.ParallelTest(t, resource.TestCase{
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -42,7 +41,7 @@ func TestAccBuildkiteTeamMember(t *testing.T) {
 
 	t.Run("adds a team member", func(t *testing.T) {
 		var tm teamMemberResourceModel
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the team member exists in the buildkite API
@@ -68,7 +67,7 @@ func TestAccBuildkiteTeamMember(t *testing.T) {
 
 	t.Run("adds a team member as a maintainer", func(t *testing.T) {
 		var tm teamMemberResourceModel
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the team member exists in the buildkite API
@@ -94,7 +93,7 @@ func TestAccBuildkiteTeamMember(t *testing.T) {
 
 	t.Run("updates a team member from member to maintainer", func(t *testing.T) {
 		var tm teamMemberResourceModel
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		checkMember := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the team member exists in the buildkite API
@@ -129,7 +128,7 @@ func TestAccBuildkiteTeamMember(t *testing.T) {
 
 	t.Run("imports a team member", func(t *testing.T) {
 		var tm teamMemberResourceModel
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			// Confirm the team member exists in the buildkite API
@@ -158,7 +157,7 @@ func TestAccBuildkiteTeamMember(t *testing.T) {
 	})
 
 	t.Run("team member is recreated if removed", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 
 		check := func(s *terraform.State) error {
 			teamMember := s.RootModule().Resources["buildkite_team_member.test"]
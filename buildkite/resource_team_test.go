@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -142,7 +141,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	}
 
 	t.Run("creates a team", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 		var tr teamResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -175,8 +174,8 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("updates a team", func(t *testing.T) {
-		resName := acctest.RandString(12)
-		resNameNew := acctest.RandString(12)
+		resName := randString(t, 12)
+		resNameNew := randString(t, 12)
 		var tr teamResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -220,7 +219,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("imports a team", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 		var tr teamResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -247,7 +246,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("team is recreated if removed", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 
 		check := func(s *terraform.State) error {
 			team := s.RootModule().Resources["buildkite_team.acc_tests"]
@@ -275,7 +274,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("creates a team with all permissions enabled", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 		var tr teamResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -302,7 +301,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("creates a team with no permissions", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 		var tr teamResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -329,7 +328,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("updates team permissions", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 		var tr teamResourceModel
 
 		checkNoPermissions := resource.ComposeAggregateTestCheckFunc(
@@ -388,7 +387,7 @@ func TestAccBuildkiteTeam(t *testing.T) {
 	})
 
 	t.Run("creates a team with default permissions when not specified", func(t *testing.T) {
-		resName := acctest.RandString(12)
+		resName := randString(t, 12)
 		var tr teamResourceModel
 
 		check := resource.ComposeAggregateTestCheckFunc(
@@ -6,9 +6,11 @@ import (
 	"net/http"
 
 	custom_modifier "github.com/buildkite/terraform-provider-buildkite/internal/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework-validators/resourcevalidator"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -16,17 +18,20 @@ import (
 )
 
 type testSuiteModel struct {
-	ApiToken        types.String `tfsdk:"api_token"`
-	ApplicationName types.String `tfsdk:"application_name"`
-	Color           types.String `tfsdk:"color"`
-	DefaultBranch   types.String `tfsdk:"default_branch"`
-	Emoji           types.String `tfsdk:"emoji"`
-	ID              types.String `tfsdk:"id"`
-	UUID            types.String `tfsdk:"uuid"`
-	OidcPolicy      types.String `tfsdk:"oidc_policy"`
-	TeamOwnerId     types.String `tfsdk:"team_owner_id"`
-	Name            types.String `tfsdk:"name"`
-	Slug            types.String `tfsdk:"slug"`
+	ApiToken                 types.String `tfsdk:"api_token"`
+	ApiTokenRotationTriggers types.Map    `tfsdk:"api_token_rotation_triggers"`
+	StoreApiToken            types.Bool   `tfsdk:"store_api_token"`
+	ApplicationName          types.String `tfsdk:"application_name"`
+	Color                    types.String `tfsdk:"color"`
+	DefaultBranch            types.String `tfsdk:"default_branch"`
+	Emoji                    types.String `tfsdk:"emoji"`
+	ID                       types.String `tfsdk:"id"`
+	UUID                     types.String `tfsdk:"uuid"`
+	OidcPolicy               types.String `tfsdk:"oidc_policy"`
+	OidcPolicyRules          types.List   `tfsdk:"oidc_policy_rules"`
+	TeamOwnerId              types.String `tfsdk:"team_owner_id"`
+	Name                     types.String `tfsdk:"name"`
+	Slug                     types.String `tfsdk:"slug"`
 }
 
 type testSuiteResponse struct {
@@ -165,7 +170,9 @@ func (ts *testSuiteResource) Create(ctx context.Context, req resource.CreateRequ
 		return
 	}
 
-	state.ApiToken = types.StringValue(response.ApiToken)
+	state.ApiToken = storedApiToken(plan.StoreApiToken, response.ApiToken)
+	state.ApiTokenRotationTriggers = plan.ApiTokenRotationTriggers
+	state.StoreApiToken = plan.StoreApiToken
 	state.ApplicationName = stateStringValue(plan.ApplicationName, response.ApplicationName)
 	state.Color = stateStringValue(plan.Color, response.Color)
 	state.DefaultBranch = types.StringValue(response.DefaultBranch)
@@ -174,6 +181,7 @@ func (ts *testSuiteResource) Create(ctx context.Context, req resource.CreateRequ
 	state.UUID = types.StringValue(response.UUID)
 	state.Name = types.StringValue(response.Name)
 	state.OidcPolicy = stateStringValue(plan.OidcPolicy, response.OidcPolicy)
+	state.OidcPolicyRules = plan.OidcPolicyRules
 	state.Slug = types.StringValue(response.Slug)
 	state.TeamOwnerId = plan.TeamOwnerId
 
@@ -283,11 +291,19 @@ func (ts *testSuiteResource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
+	// Imported suites keep their API token in state, as new ones do by default.
+	if state.StoreApiToken.IsNull() {
+		state.StoreApiToken = types.BoolValue(true)
+	}
+
 	// API Token and OIDC policy only available from REST API
 	var response testSuiteResponse
 
-	// Construct URL to call to the REST API to get the API Token
-	url := fmt.Sprintf("/v2/analytics/organizations/%s/suites/%s?show_api_token=true", ts.client.organization, state.Slug.ValueString())
+	// Construct URL to call to the REST API, asking for the API Token only when it is kept in State
+	url := fmt.Sprintf("/v2/analytics/organizations/%s/suites/%s", ts.client.organization, state.Slug.ValueString())
+	if state.StoreApiToken.ValueBool() {
+		url += "?show_api_token=true"
+	}
 	err = retry.RetryContext(ctx, timeout, func() *retry.RetryError {
 		err := ts.client.makeRequest(ctx, "GET", url, nil, &response)
 		return retryContextError(err)
@@ -301,7 +317,9 @@ func (ts *testSuiteResource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	// Update API Token in State if it has changed or if it is null from importing into State
-	if response.ApiToken != state.ApiToken.ValueString() || state.ApiToken.IsNull() {
+	if !state.StoreApiToken.ValueBool() {
+		state.ApiToken = types.StringNull()
+	} else if response.ApiToken != state.ApiToken.ValueString() || state.ApiToken.IsNull() {
 		// The API Token can be regenerated, but 'terraform refresh' or 'terraform apply' is required to update State
 		// don't need a warning if it is null from importing into State
 		if !state.ApiToken.IsNull() {
@@ -312,7 +330,8 @@ func (ts *testSuiteResource) Read(ctx context.Context, req resource.ReadRequest,
 
 	state.ApplicationName = refreshedStringValue(state.ApplicationName, response.ApplicationName)
 	state.Color = refreshedStringValue(state.Color, response.Color)
-	state.OidcPolicy = refreshedStringValue(state.OidcPolicy, response.OidcPolicy)
+	state.OidcPolicy = refreshedOIDCPolicy(state.OidcPolicy, refreshedStringValue(state.OidcPolicy, response.OidcPolicy))
+	resp.Diagnostics.Append(refreshOIDCPolicyRules(ctx, state.OidcPolicy, &state.OidcPolicyRules)...)
 
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
@@ -354,11 +373,22 @@ func (ts *testSuiteResource) Schema(ctx context.Context, req resource.SchemaRequ
 			"api_token": schema.StringAttribute{
 				Computed:            true,
 				Sensitive:           true,
-				MarkdownDescription: "The API token to use to send test run data to the API.",
+				MarkdownDescription: "The API token to use to send test run data to the API. Null when `store_api_token` is `false`.",
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"api_token_rotation_triggers": schema.MapAttribute{
+				Optional:            true,
+				ElementType:         types.StringType,
+				MarkdownDescription: "Arbitrary values that regenerate the suite's API token in place whenever they change, for example a date to rotate on a schedule. The suite keeps its history; only the old token stops working.",
+			},
+			"store_api_token": schema.BoolAttribute{
+				Optional:            true,
+				Computed:            true,
+				Default:             booldefault.StaticBool(true),
+				MarkdownDescription: "Whether to keep the API token in Terraform state as `api_token`. Set to `false` to keep it out of state, and read it with the `buildkite_test_suite_api_token` ephemeral resource instead. Defaults to `true`.",
+			},
 			"default_branch": schema.StringAttribute{
 				MarkdownDescription: "The default branch for the repository this test suite is for.",
 				Required:            true,
@@ -388,17 +418,76 @@ func (ts *testSuiteResource) Schema(ctx context.Context, req resource.SchemaRequ
 			"oidc_policy": schema.StringAttribute{
 				MarkdownDescription: "The [OIDC policy](https://buildkite.com/docs/pipelines/configure/tests/test-collection/oidc) for the test suite, as a YAML or JSON string. " +
 					"This policy defines which OIDC tokens can be exchanged for suite access, as an alternative to the suite API token. " +
-					"If omitted, the policy is left unmanaged by Terraform; set it to an empty string to remove an existing policy.",
+					"If omitted, the policy is left unmanaged by Terraform; set it to an empty string to remove an existing policy. " +
+					"Conflicts with `oidc_policy_rules`, which is rendered here when configured.",
 				Optional: true,
 				Computed: true,
 				PlanModifiers: []planmodifier.String{
 					stringplanmodifier.UseStateForUnknown(),
 				},
 			},
+			"oidc_policy_rules": oidcPolicyRulesSchema("test suite", "write_uploads"),
 		},
 	}
 }
 
+func (ts *testSuiteResource) ConfigValidators(ctx context.Context) []resource.ConfigValidator {
+	return []resource.ConfigValidator{
+		resourcevalidator.Conflicting(
+			path.MatchRoot("oidc_policy"),
+			path.MatchRoot("oidc_policy_rules"),
+		),
+	}
+}
+
+func (ts *testSuiteResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var config testSuiteModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	planOIDCPolicy(ctx, config.OidcPolicy, config.OidcPolicyRules, true, req, resp)
+
+	var plan testSuiteModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !plan.StoreApiToken.IsUnknown() && !plan.StoreApiToken.ValueBool() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("api_token"), types.StringNull())...)
+		return
+	}
+
+	if req.State.Raw.IsNull() {
+		return
+	}
+
+	var stateTriggers types.Map
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("api_token_rotation_triggers"), &stateTriggers)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if tokenRotationDue(plan.ApiTokenRotationTriggers, stateTriggers) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("api_token"), types.StringUnknown())...)
+	}
+}
+
+// storedApiToken is the api_token to keep in state: the token, unless the configuration keeps it
+// out of state.
+func storedApiToken(store types.Bool, token string) types.String {
+	if !store.IsNull() && !store.ValueBool() {
+		return types.StringNull()
+	}
+	return types.StringValue(token)
+}
+
 func (ts *testSuiteResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	var plan, state testSuiteModel
 	var response testSuiteResponse
@@ -450,8 +539,38 @@ func (ts *testSuiteResource) Update(ctx context.Context, req resource.UpdateRequ
 	state.ApplicationName = stateStringValue(plan.ApplicationName, response.ApplicationName)
 	state.Color = stateStringValue(plan.Color, response.Color)
 	state.OidcPolicy = stateStringValue(plan.OidcPolicy, response.OidcPolicy)
+	state.OidcPolicyRules = plan.OidcPolicyRules
 	state.Slug = types.StringValue(response.Slug)
 
+	// Rotate the API token in place when its triggers change, and fetch it again when it is put
+	// back into state.
+	rotate := tokenRotationDue(plan.ApiTokenRotationTriggers, state.ApiTokenRotationTriggers)
+	if rotate || plan.ApiToken.IsUnknown() {
+		tokenURL := fmt.Sprintf("/v2/analytics/organizations/%s/suites/%s?show_api_token=true", ts.client.organization, state.Slug.ValueString())
+		method := http.MethodGet
+		if rotate {
+			tokenURL = fmt.Sprintf("/v2/analytics/organizations/%s/suites/%s/regenerate_token", ts.client.organization, state.Slug.ValueString())
+			method = http.MethodPost
+		}
+
+		var tokenResponse testSuiteResponse
+		err := retry.RetryContext(ctx, timeout, func() *retry.RetryError {
+			return retryContextError(ts.client.makeRequest(ctx, method, tokenURL, nil, &tokenResponse))
+		})
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Failed to update test suite API token",
+				fmt.Sprintf("Failed to update test suite API token: %s", err.Error()),
+			)
+			return
+		}
+		state.ApiToken = storedApiToken(plan.StoreApiToken, tokenResponse.ApiToken)
+	} else {
+		state.ApiToken = plan.ApiToken
+	}
+	state.ApiTokenRotationTriggers = plan.ApiTokenRotationTriggers
+	state.StoreApiToken = plan.StoreApiToken
+
 	// If the planned team_owner_id differs from the state, add the new one and remove the old one
 	if plan.TeamOwnerId.ValueString() != state.TeamOwnerId.ValueString() {
 		var r *createTestSuiteTeamResponse
@@ -500,7 +619,9 @@ func (ts *testSuiteResource) Update(ctx context.Context, req resource.UpdateRequ
 }
 
 func (ts *testSuiteResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	// Test suites are read by GraphQL ID, which a UUID converts to
+	id, _, _ := importNodeID("Suite", req.ID)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 }
 
 func setTestSuiteModel(testSuiteModel *testSuiteModel, suite *getTestSuiteSuite) {
@@ -182,7 +182,9 @@ func (tst *testSuiteTeamResource) Read(ctx context.Context, req resource.ReadReq
 }
 
 func (tst *testSuiteTeamResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
-	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+	// Test suite teams are read by GraphQL ID, which a UUID converts to
+	id, _, _ := importNodeID("TeamSuite", req.ID)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), id)...)
 }
 
 func (tst *testSuiteTeamResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
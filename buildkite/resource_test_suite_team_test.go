@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/plancheck"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
@@ -52,8 +51,8 @@ func TestAccBuildkiteTestSuiteTeamResource(t *testing.T) {
 	}
 
 	t.Run("creates a test suite team", func(t *testing.T) {
-		ownerTeamName := acctest.RandString(12)
-		newTeamName := acctest.RandString(12)
+		ownerTeamName := randString(t, 12)
+		newTeamName := randString(t, 12)
 		var tr teamResourceModel
 		var ts getTestSuiteSuite
 		var tst testSuiteTeamModel
@@ -89,8 +88,8 @@ func TestAccBuildkiteTestSuiteTeamResource(t *testing.T) {
 	})
 
 	t.Run("updates a test suite teams access level", func(t *testing.T) {
-		ownerTeamName := acctest.RandString(12)
-		newTeamName := acctest.RandString(12)
+		ownerTeamName := randString(t, 12)
+		newTeamName := randString(t, 12)
 		var tr teamResourceModel
 		var ts getTestSuiteSuite
 		var tst testSuiteTeamModel
@@ -147,8 +146,8 @@ func TestAccBuildkiteTestSuiteTeamResource(t *testing.T) {
 	})
 
 	t.Run("imports a test suite team", func(t *testing.T) {
-		ownerTeamName := acctest.RandString(12)
-		newTeamName := acctest.RandString(12)
+		ownerTeamName := randString(t, 12)
+		newTeamName := randString(t, 12)
 		var tr teamResourceModel
 		var ts getTestSuiteSuite
 		var tst testSuiteTeamModel
@@ -185,13 +184,22 @@ func TestAccBuildkiteTestSuiteTeamResource(t *testing.T) {
 					ImportState:       true,
 					ImportStateVerify: true,
 				},
+				{
+					// re-import the resource by its UUID and confirm they match
+					ResourceName: "buildkite_test_suite_team.teamsuite",
+					ImportStateIdFunc: func(s *terraform.State) (string, error) {
+						return s.RootModule().Resources["buildkite_test_suite_team.teamsuite"].Primary.Attributes["uuid"], nil
+					},
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
 			},
 		})
 	})
 
 	t.Run("removes a test suite team", func(t *testing.T) {
-		ownerTeamName := acctest.RandString(12)
-		newTeamName := acctest.RandString(12)
+		ownerTeamName := randString(t, 12)
+		newTeamName := randString(t, 12)
 		var tr teamResourceModel
 		var ts getTestSuiteSuite
 		var tst testSuiteTeamModel
@@ -220,8 +228,8 @@ func TestAccBuildkiteTestSuiteTeamResource(t *testing.T) {
 	})
 
 	t.Run("test suite team is recreated if removed", func(t *testing.T) {
-		ownerTeamName := acctest.RandString(12)
-		newTeamName := acctest.RandString(12)
+		ownerTeamName := randString(t, 12)
+		newTeamName := randString(t, 12)
 
 		check := func(s *terraform.State) error {
 			teamSuite := s.RootModule().Resources["buildkite_test_suite_team.teamsuite"]
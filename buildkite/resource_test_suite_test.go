@@ -7,7 +7,6 @@ import (
 	"reflect"
 	"testing"
 
-	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/terraform"
 )
@@ -162,7 +161,7 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 
 	t.Run("creates a test suite", func(t *testing.T) {
 		var suite getTestSuiteSuite
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			checkTestSuiteExists("buildkite_test_suite.suite", &suite),
@@ -190,7 +189,7 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 
 	t.Run("creates a test suite with an emoji set", func(t *testing.T) {
 		var suite getTestSuiteSuite
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			checkTestSuiteExists("buildkite_test_suite.suite", &suite),
@@ -219,7 +218,7 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 
 	t.Run("creates and updates a test suite with all attributes set", func(t *testing.T) {
 		var suite getTestSuiteSuite
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		oidcPolicy := "- iss: https://agent.buildkite.com\n  claims:\n    organization_slug: my-org\n    pipeline_slug: my-pipeline\n  scopes:\n    - write_uploads\n"
 		updatedOidcPolicy := "- iss: https://agent.buildkite.com\n  claims:\n    organization_slug: my-org\n    pipeline_slug: another-pipeline\n  scopes:\n    - read_suites\n    - write_uploads\n"
@@ -281,7 +280,7 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 
 	t.Run("updates a test suite", func(t *testing.T) {
 		var suite getTestSuiteSuite
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			resource.TestCheckResourceAttrSet("buildkite_test_suite.suite", "id"),
@@ -314,7 +313,7 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 
 	t.Run("creates and handles test suite team owner resolution", func(t *testing.T) {
 		var suite getTestSuiteSuite
-		randName := acctest.RandString(10)
+		randName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			resource.TestCheckResourceAttrSet("buildkite_test_suite.suite", "id"),
@@ -347,7 +346,7 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 
 	t.Run("import a test suite", func(t *testing.T) {
 		var suite getTestSuiteSuite
-		resName := acctest.RandString(10)
+		resName := randString(t, 10)
 
 		check := resource.ComposeAggregateTestCheckFunc(
 			checkTestSuiteExists("buildkite_test_suite.suite", &suite),
@@ -375,6 +374,15 @@ func TestAccBuildkiteTestSuiteResource(t *testing.T) {
 					ImportState:       true,
 					ImportStateVerify: true,
 				},
+				{
+					// re-import the resource by its UUID
+					ResourceName: "buildkite_test_suite.suite",
+					ImportStateIdFunc: func(s *terraform.State) (string, error) {
+						return s.RootModule().Resources["buildkite_test_suite.suite"].Primary.Attributes["uuid"], nil
+					},
+					ImportState:       true,
+					ImportStateVerify: true,
+				},
 			},
 		})
 	})
@@ -509,9 +509,9 @@ func TestMakeRequestReportsAttemptsWhenTransportKeepsFailing(t *testing.T) {
 	}
 }
 
-// The registry resources and data source call client.http.Do without going through makeRequest, so
-// they keep no capture and cannot rebuild the attempt count themselves. It has to survive on the
-// error, or a retried connection failure reads to them as a single-shot one.
+// A caller of client.http.Do that does not go through makeRequest keeps no capture and cannot rebuild
+// the attempt count itself. It has to survive on the error, or a retried connection failure reads to
+// it as a single-shot one.
 func TestDirectRESTCallerKeepsTheAttemptCount(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,111 @@
+package buildkite
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+// transportConfig holds the provider's TLS and proxy settings. Every field is optional; the zero
+// value describes the transport the provider used before these settings existed.
+type transportConfig struct {
+	caCertFile         string
+	caCertPEM          string
+	clientCertFile     string
+	clientKeyFile      string
+	clientCertPEM      string
+	clientKeyPEM       string
+	proxyURL           string
+	insecureSkipVerify bool
+}
+
+// isZero reports whether none of the settings are in use, in which case NewClient keeps the
+// transports retryablehttp builds for itself.
+func (c transportConfig) isZero() bool {
+	return c == transportConfig{}
+}
+
+// newTransport builds the transport shared by the REST and GraphQL clients. It starts from the same
+// pooled transport retryablehttp would otherwise create, so connection limits and timeouts do not
+// change when only one setting is given.
+//
+// The CA bundle is added to the system pool rather than replacing it: a TLS-intercepting proxy
+// re-signs api.buildkite.com with its own CA, but the same process may still reach other hosts
+// directly.
+func newTransport(config transportConfig) (*http.Transport, error) {
+	transport := cleanhttp.DefaultPooledTransport()
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		// Only ever set for local stand-ins of the API; the schema says as much.
+		InsecureSkipVerify: config.insecureSkipVerify,
+	}
+
+	caPEM := []byte(config.caCertPEM)
+	if config.caCertFile != "" {
+		contents, err := os.ReadFile(config.caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate file: %w", err)
+		}
+		caPEM = contents
+	}
+	if len(caPEM) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("the CA certificate bundle contains no PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	certPEM, keyPEM := []byte(config.clientCertPEM), []byte(config.clientKeyPEM)
+	if config.clientCertFile != "" {
+		contents, err := os.ReadFile(config.clientCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate file: %w", err)
+		}
+		certPEM = contents
+	}
+	if config.clientKeyFile != "" {
+		contents, err := os.ReadFile(config.clientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client key file: %w", err)
+		}
+		keyPEM = contents
+	}
+	switch {
+	case len(certPEM) > 0 && len(keyPEM) > 0:
+		certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	case len(certPEM) > 0:
+		return nil, errors.New("a client certificate was given without a client key")
+	case len(keyPEM) > 0:
+		return nil, errors.New("a client key was given without a client certificate")
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	// Without an explicit proxy the pooled transport already honours HTTPS_PROXY and NO_PROXY.
+	if config.proxyURL != "" {
+		proxy, err := url.Parse(config.proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy URL: %w", err)
+		}
+		if proxy.Scheme == "" || proxy.Host == "" {
+			return nil, fmt.Errorf("proxy URL %q must include a scheme and host, e.g. http://proxy.internal:3128", config.proxyURL)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return transport, nil
+}
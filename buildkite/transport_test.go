@@ -0,0 +1,171 @@
+package buildkite
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func serverCAPEM(server *httptest.Server) string {
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+}
+
+// The REST and GraphQL clients must both trust a configured CA, since the proxy re-signs every
+// request rather than only the ones a particular resource makes.
+func TestNewTransportTrustsConfiguredCA(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":{"organization":{"id":"org-abc"}}}`))
+	}))
+	// The subtests run in parallel, after this function has returned.
+	t.Cleanup(server.Close)
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(serverCAPEM(server)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, settings := range map[string]transportConfig{
+		"file": {caCertFile: caFile},
+		"pem":  {caCertPEM: serverCAPEM(server)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			transport, err := newTransport(settings)
+			if err != nil {
+				t.Fatalf("newTransport() error = %v", err)
+			}
+
+			client := NewClient(&clientConfig{
+				apiToken:   "test",
+				graphqlURL: server.URL,
+				restURL:    server.URL,
+				org:        "test-org",
+				userAgent:  "test",
+				transport:  transport,
+			})
+
+			var response map[string]interface{}
+			if err := client.makeRequest(context.Background(), http.MethodGet, "/v2/access-token", nil, &response); err != nil {
+				t.Errorf("REST request failed: %v", err)
+			}
+			if _, err := client.GetOrganizationID(); err != nil {
+				t.Errorf("GraphQL request failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestNewClientWithoutTransportRejectsUnknownCA(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := NewClient(&clientConfig{
+		apiToken:   "test",
+		graphqlURL: server.URL,
+		restURL:    server.URL,
+		org:        "test-org",
+		userAgent:  "test",
+	})
+
+	err := client.makeRequest(context.Background(), http.MethodGet, "/v2/access-token", nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "certificate") {
+		t.Fatalf("makeRequest() error = %v, want a certificate verification error", err)
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		settings transportConfig
+		wantErr  string
+	}{
+		{
+			name:     "missing CA file",
+			settings: transportConfig{caCertFile: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr:  "failed to read CA certificate file",
+		},
+		{
+			name:     "CA bundle without certificates",
+			settings: transportConfig{caCertPEM: "not a certificate"},
+			wantErr:  "contains no PEM encoded certificates",
+		},
+		{
+			name:     "client certificate without key",
+			settings: transportConfig{clientCertPEM: "cert"},
+			wantErr:  "without a client key",
+		},
+		{
+			name:     "client key without certificate",
+			settings: transportConfig{clientKeyPEM: "key"},
+			wantErr:  "without a client certificate",
+		},
+		{
+			name:     "unparseable client key pair",
+			settings: transportConfig{clientCertPEM: "cert", clientKeyPEM: "key"},
+			wantErr:  "failed to load client certificate",
+		},
+		{
+			name:     "proxy without scheme",
+			settings: transportConfig{proxyURL: "proxy.internal:3128"},
+			wantErr:  "must include a scheme and host",
+		},
+		{
+			name:     "insecure skip verify",
+			settings: transportConfig{insecureSkipVerify: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			transport, err := newTransport(tt.settings)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("newTransport() error = %v", err)
+				}
+				if transport.TLSClientConfig.InsecureSkipVerify != tt.settings.insecureSkipVerify {
+					t.Errorf("InsecureSkipVerify = %v, want %v", transport.TLSClientConfig.InsecureSkipVerify, tt.settings.insecureSkipVerify)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("newTransport() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// An explicit proxy applies to every request, regardless of what HTTPS_PROXY says.
+func TestNewTransportUsesExplicitProxy(t *testing.T) {
+	t.Parallel()
+
+	transport, err := newTransport(transportConfig{proxyURL: "http://proxy.internal:3128"})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.buildkite.com/v2/access-token", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil {
+		t.Fatalf("Proxy() error = %v", err)
+	}
+	want, _ := url.Parse("http://proxy.internal:3128")
+	if proxy == nil || proxy.String() != want.String() {
+		t.Errorf("Proxy() = %v, want %v", proxy, want)
+	}
+}
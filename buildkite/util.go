@@ -2,6 +2,7 @@ package buildkite
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log"
@@ -167,6 +168,38 @@ func isUUID(uuid string) bool {
 	return r.MatchString(uuid)
 }
 
+// nodeID returns the GraphQL ID of the node of the given type with the given UUID. Buildkite encodes
+// a node's ID as the base64 of its type name and UUID, so imports can take either.
+func nodeID(typename, uuid string) string {
+	return base64.StdEncoding.EncodeToString([]byte(typename + "---" + uuid))
+}
+
+// nodeUUID returns the UUID encoded in the GraphQL ID of a node of the given type.
+func nodeUUID(typename, id string) (string, bool) {
+	decoded, err := base64.StdEncoding.DecodeString(id)
+	if err != nil {
+		return "", false
+	}
+
+	uuid, ok := strings.CutPrefix(string(decoded), typename+"---")
+	if !ok || !isUUID(uuid) {
+		return "", false
+	}
+
+	return uuid, true
+}
+
+// importNodeID reads an import identifier that is either the GraphQL ID or the UUID of a node of the
+// given type, returning both.
+func importNodeID(typename, raw string) (id, uuid string, ok bool) {
+	if isUUID(raw) {
+		return nodeID(typename, raw), raw, true
+	}
+
+	uuid, ok = nodeUUID(typename, raw)
+	return raw, uuid, ok
+}
+
 func getenv(key string) string {
 	val, ok := os.LookupEnv(key)
 	if !ok {
@@ -198,3 +231,14 @@ func retryContextError(err error) *retry.RetryError {
 	}
 	return retry.NonRetryableError(err)
 }
+
+// tokenRotationDue reports whether a resource's token rotation triggers changed since the last
+// apply. Adding triggers to a resource that had none rotates too, so the token is known to have
+// been issued under them; removing them leaves the token alone.
+func tokenRotationDue(plan, state types.Map) bool {
+	if plan.IsNull() {
+		return false
+	}
+
+	return plan.IsUnknown() || !plan.Equal(state)
+}
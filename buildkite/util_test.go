@@ -5,6 +5,8 @@ import (
 	"os"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
@@ -222,3 +224,56 @@ func TestIsActiveJobsError(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenRotationDue(t *testing.T) {
+	t.Parallel()
+
+	triggers := func(value string) types.Map {
+		return types.MapValueMust(types.StringType, map[string]attr.Value{"rotated": types.StringValue(value)})
+	}
+	null := types.MapNull(types.StringType)
+
+	for name, tc := range map[string]struct {
+		plan, state types.Map
+		want        bool
+	}{
+		"unchanged":        {plan: triggers("2026-01"), state: triggers("2026-01"), want: false},
+		"changed":          {plan: triggers("2026-02"), state: triggers("2026-01"), want: true},
+		"added":            {plan: triggers("2026-01"), state: null, want: true},
+		"removed":          {plan: null, state: triggers("2026-01"), want: false},
+		"never configured": {plan: null, state: null, want: false},
+		"unknown":          {plan: types.MapUnknown(types.StringType), state: triggers("2026-01"), want: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := tokenRotationDue(tc.plan, tc.state); got != tc.want {
+				t.Errorf("tokenRotationDue() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportNodeID(t *testing.T) {
+	t.Parallel()
+
+	const uuid = "b2b8da51-9f93-4cc2-9229-0db787d43903"
+	const id = "Q2x1c3RlclF1ZXVlLS0tYjJiOGRhNTEtOWY5My00Y2MyLTkyMjktMGRiNzg3ZDQzOTAz"
+
+	for name, tc := range map[string]struct {
+		typename, raw string
+		wantID        string
+		wantUUID      string
+		wantOK        bool
+	}{
+		"uuid":                       {typename: "ClusterQueue", raw: uuid, wantID: id, wantUUID: uuid, wantOK: true},
+		"graphql id":                 {typename: "ClusterQueue", raw: id, wantID: id, wantUUID: uuid, wantOK: true},
+		"graphql id of another type": {typename: "Cluster", raw: id, wantID: id, wantOK: false},
+		"not an id":                  {typename: "ClusterQueue", raw: "my-queue", wantID: "my-queue", wantOK: false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			gotID, gotUUID, ok := importNodeID(tc.typename, tc.raw)
+			if gotID != tc.wantID || gotUUID != tc.wantUUID || ok != tc.wantOK {
+				t.Errorf("importNodeID(%q, %q) = %q, %q, %v, want %q, %q, %v", tc.typename, tc.raw, gotID, gotUUID, ok, tc.wantID, tc.wantUUID, tc.wantOK)
+			}
+		})
+	}
+}
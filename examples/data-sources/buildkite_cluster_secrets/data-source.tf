@@ -0,0 +1,14 @@
+data "buildkite_cluster_secrets" "production" {
+  cluster_id = "01234567-89ab-cdef-0123-456789abcdef"
+}
+
+# Fail the plan when a secret exists in the cluster that Terraform does not manage.
+check "no_unmanaged_secrets" {
+  assert {
+    condition = alltrue([
+      for secret in data.buildkite_cluster_secrets.production.secrets :
+      contains(keys(buildkite_cluster_secrets.vault.secrets), secret.key)
+    ])
+    error_message = "The production cluster has secrets that are not managed by Terraform."
+  }
+}
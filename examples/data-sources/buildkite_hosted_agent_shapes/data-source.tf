@@ -0,0 +1,23 @@
+data "buildkite_hosted_agent_shapes" "linux" {
+  os = "linux"
+}
+
+# Pick the smallest ARM shape with at least 8 GB of memory
+locals {
+  linux_arm_shape = [
+    for shape in data.buildkite_hosted_agent_shapes.linux.shapes : shape.name
+    if shape.architecture == "arm64" && shape.memory >= 8
+  ][0]
+}
+
+resource "buildkite_cluster_queue" "linux_arm" {
+  cluster_id = buildkite_cluster.primary.id
+  key        = "linux-arm"
+
+  hosted_agents = {
+    instance_shape = local.linux_arm_shape
+    linux = {
+      agent_image_ref = "ubuntu:24.04"
+    }
+  }
+}
@@ -0,0 +1,20 @@
+# A portal curating the queues of a cluster, invoked with the portal's token rather than an API token
+variable "cluster_queues_portal_token" {
+  type      = string
+  sensitive = true
+}
+
+data "buildkite_portal_invocation" "queues" {
+  slug  = "cluster-queues"
+  token = var.cluster_queues_portal_token
+  variables = jsonencode({
+    cluster = "Q2x1c3Rlci0tLTAxOGE..."
+  })
+}
+
+locals {
+  queue_keys = [
+    for queue in jsondecode(data.buildkite_portal_invocation.queues.result).organization.cluster.queues.edges :
+    queue.node.key
+  ]
+}
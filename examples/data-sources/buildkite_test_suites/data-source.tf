@@ -0,0 +1,22 @@
+data "buildkite_test_suites" "all" {}
+
+# only suites with "payments" in their name
+data "buildkite_test_suites" "payments" {
+  search = "payments"
+}
+
+data "buildkite_team" "platform" {
+  slug = "platform"
+}
+
+# give the platform team read access to every end-to-end suite
+resource "buildkite_test_suite_team" "platform_e2e" {
+  for_each = {
+    for suite in data.buildkite_test_suites.all.test_suites : suite.slug => suite
+    if endswith(suite.slug, "-e2e")
+  }
+
+  test_suite_id = each.value.id
+  team_id       = data.buildkite_team.platform.id
+  access_level  = "READ_ONLY"
+}
@@ -0,0 +1,21 @@
+# Fail the plan, before anything is changed, if the token cannot manage these resource types
+data "buildkite_token_scopes" "token" {
+  resource_types = [
+    "buildkite_pipeline",
+    "buildkite_team",
+    "buildkite_test_suite",
+  ]
+}
+
+# Or report missing access from a check block without failing the plan
+data "buildkite_token_scopes" "audit" {
+  resource_types  = ["buildkite_cluster_secret"]
+  fail_on_missing = false
+}
+
+check "token_scopes" {
+  assert {
+    condition     = length(data.buildkite_token_scopes.audit.missing) == 0
+    error_message = "The API token is missing access: ${jsonencode(data.buildkite_token_scopes.audit.missing)}"
+  }
+}
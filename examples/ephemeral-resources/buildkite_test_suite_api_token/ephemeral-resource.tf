@@ -0,0 +1,18 @@
+resource "buildkite_test_suite" "main" {
+  name            = "main"
+  default_branch  = "main"
+  team_owner_id   = "VGVhbvDf4eRef20tMzIxMGEfYTctNzEF5g00M8f5s6E2YjYtODNlOGNlZgD6HcBi"
+  store_api_token = false
+}
+
+# read the suite API token without storing it in plan or state
+ephemeral "buildkite_test_suite_api_token" "main" {
+  slug = buildkite_test_suite.main.slug
+}
+
+# and pass it to a write-only attribute of another provider
+resource "aws_secretsmanager_secret_version" "suite_token" {
+  secret_id                = "buildkite/test-suite-token"
+  secret_string_wo         = ephemeral.buildkite_test_suite_api_token.main.api_token
+  secret_string_wo_version = 1
+}
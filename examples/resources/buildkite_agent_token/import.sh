@@ -0,0 +1,19 @@
+# import an agent token resource using its GraphQL ID or UUID
+#
+# you can use this query to find the ID:
+# query getAgentTokens {
+#   organization(slug: "ORGANIZATION_SLUG") {
+#     agentTokens(first: 50) {
+#       edges {
+#         node {
+#           id
+#           uuid
+#           description
+#         }
+#       }
+#     }
+#   }
+# }
+#
+# the token value is only available when a token is created, so it is empty after an import
+terraform import buildkite_agent_token.default 7a4e6b1c-2f3d-4e5a-9b8c-1d2e3f4a5b6c
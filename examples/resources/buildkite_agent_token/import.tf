@@ -0,0 +1,4 @@
+import {
+  to = buildkite_agent_token.default
+  id = "QWdlbnRUb2tlbi0tLTdhNGU2YjFjLTJmM2QtNGU1YS05YjhjLTFkMmUzZjRhNWI2Yw=="
+}
@@ -0,0 +1,23 @@
+# import a cluster agent token resource using {cluster_id}/{token_id}, where each is either the
+# GraphQL ID or the UUID
+#
+# you can use this query to find the IDs:
+# query getClusterAgentTokens {
+#   organization(slug: "ORGANIZATION_SLUG") {
+#     cluster(id: "CLUSTER_UUID") {
+#       id
+#       agentTokens(first: 50) {
+#         edges {
+#           node {
+#             id
+#             uuid
+#             description
+#           }
+#         }
+#       }
+#     }
+#   }
+# }
+#
+# the token value is only available when a token is created, so it is empty after an import
+terraform import buildkite_cluster_agent_token.default 35498aaf-ad05-4fa5-9a07-91bf6cacd2bd/b6001416-0e1e-41c6-9dbe-3d96766f451a
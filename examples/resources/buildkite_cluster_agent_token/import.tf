@@ -0,0 +1,4 @@
+import {
+  to = buildkite_cluster_agent_token.default
+  id = "Q2x1c3Rlci0tLTM1NDk4YWFmLWFkMDUtNGZhNS05YTA3LTkxYmY2Y2FjZDJiZA==/b6001416-0e1e-41c6-9dbe-3d96766f451a"
+}
@@ -9,6 +9,25 @@ resource "buildkite_cluster_secret" "example" {
   EOT
 }
 
+# Typed access policy rules are checked at plan time and rendered to the YAML policy.
+resource "buildkite_cluster_secret" "deploy_token" {
+  cluster_id  = "01234567-89ab-cdef-0123-456789abcdef"
+  key         = "DEPLOY_TOKEN"
+  value       = "deploy-token"
+  description = "Token for deploying from main and release branches"
+
+  access_policy = [
+    {
+      pipeline_slugs = ["deploy"]
+      build_branches = ["main", "release/*"]
+    },
+    {
+      pipeline_slugs = ["nightly-deploy"]
+      build_sources  = ["schedule"]
+    },
+  ]
+}
+
 # Use Terraform write-only attributes to pass a secret value without storing it
 # in Terraform plan or state artifacts. The version should be a non-secret marker
 # that changes when the secret value changes.
@@ -29,3 +48,13 @@ resource "buildkite_cluster_secret" "write_only_example" {
   value_wo_version = var.api_token_version
   description      = "API token"
 }
+
+# Rotate a secret on a schedule. With random_bytes the provider generates each new value;
+# configure value_wo instead to rotate in a value read from an ephemeral resource.
+resource "buildkite_cluster_secret" "webhook_signing_key" {
+  cluster_id      = "01234567-89ab-cdef-0123-456789abcdef"
+  key             = "WEBHOOK_SIGNING_KEY"
+  random_bytes    = 32
+  rotation_period = "720h"
+  description     = "Rotated every 30 days"
+}
@@ -0,0 +1,6 @@
+# Import every secret in a cluster using the cluster_id
+#
+# You can find the cluster_id under cluster settings in the UI.
+# Imported secrets need a value_wo and value_wo_version in configuration;
+# the first apply after import sends each value.
+terraform import buildkite_cluster_secrets.vault 01234567-89ab-cdef-0123-456789abcdef
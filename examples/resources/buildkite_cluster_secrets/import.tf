@@ -0,0 +1,4 @@
+import {
+  to = buildkite_cluster_secrets.vault
+  id = "01234567-89ab-cdef-0123-456789abcdef"
+}
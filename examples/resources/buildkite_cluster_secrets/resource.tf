@@ -0,0 +1,30 @@
+# Secrets synced from an external store, for example a Vault KV mount read with an ephemeral resource.
+# Each value is write-only; bump its version to send a new value.
+variable "vault_secrets" {
+  type      = map(string)
+  sensitive = true
+  ephemeral = true
+}
+
+variable "vault_secret_versions" {
+  type = map(string)
+}
+
+resource "buildkite_cluster_secrets" "vault" {
+  cluster_id  = "01234567-89ab-cdef-0123-456789abcdef"
+  description = "Synced from Vault"
+
+  access_policy = [
+    {
+      pipeline_slugs = ["deploy"]
+      build_branches = ["main"]
+    },
+  ]
+
+  secrets = {
+    for key, version in var.vault_secret_versions : key => {
+      value_wo         = var.vault_secrets[key]
+      value_wo_version = version
+    }
+  }
+}
@@ -11,6 +11,16 @@ resource "buildkite_notification_service" "webhook" {
     token_mode = "signature"
     events     = ["build.finished"]
   }
+
+  # Send a test delivery after every change and fail the apply if it is not delivered.
+  test_delivery = true
+}
+
+check "deployment_events_delivery" {
+  assert {
+    condition     = buildkite_notification_service.webhook.last_delivery_status != "failure"
+    error_message = "Deliveries to the deployment events service are failing: ${coalesce(buildkite_notification_service.webhook.last_error, "unknown error")}"
+  }
 }
 
 data "aws_caller_identity" "current" {}
@@ -43,3 +53,23 @@ resource "aws_cloudwatch_event_rule" "buildkite_builds" {
     "detail-type" = ["Build Started", "Build Finished"]
   })
 }
+
+variable "slack_webhook_url" {
+  type      = string
+  sensitive = true
+}
+
+# Legacy Slack services post to an incoming webhook and choose which build states to notify about.
+resource "buildkite_notification_service" "slack" {
+  provider_type = "slack"
+  description   = "Post failed and blocked builds to #builds"
+  enabled       = true
+
+  scope = "all"
+
+  slack = {
+    webhook_url  = var.slack_webhook_url
+    channel      = "#builds"
+    build_states = ["failed", "blocked"]
+  }
+}
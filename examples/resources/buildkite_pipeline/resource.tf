@@ -45,6 +45,13 @@ resource "buildkite_pipeline" "public_pipeline" {
   visibility = "PUBLIC"
 }
 
+# preview pipeline that is torn down even while builds are running
+resource "buildkite_pipeline" "preview" {
+  name       = "Preview: my-feature"
+  repository = "git@github.com:my-org/my-repo"
+  on_delete  = "cancel_builds"
+}
+
 # signed pipeline
 data "buildkite_cluster" "default" {
   name = "Default cluster"
@@ -15,6 +15,21 @@ resource "buildkite_portal" "restricted" {
   allowed_ip_addresses = "192.168.1.0/24 10.0.0.0/8"
 }
 
+# portal whose token is rotated in place every three months
+resource "time_rotating" "quarterly" {
+  rotation_months = 3
+}
+
+resource "buildkite_portal" "rotated" {
+  slug  = "rotated-portal"
+  name  = "Rotated Portal"
+  query = "{ viewer { user { name } } }"
+
+  token_rotation_triggers = {
+    rotated_at = time_rotating.quarterly.id
+  }
+}
+
 # portal with complex GraphQL query
 resource "buildkite_portal" "pipeline_stats" {
   slug        = "pipeline-statistics"
@@ -46,3 +61,28 @@ resource "buildkite_portal" "pipeline_stats" {
 
   user_invokable = false
 }
+
+# portal taking variables; the query is checked against the Buildkite schema at plan time
+resource "buildkite_portal" "pipeline_builds" {
+  slug  = "pipeline-builds"
+  name  = "Pipeline Builds"
+  query = <<-EOT
+    query PipelineBuilds($pipeline: ID!, $first: Int = 10) {
+      pipeline(slug: $pipeline) {
+        builds(first: $first) {
+          edges {
+            node {
+              number
+              state
+            }
+          }
+        }
+      }
+    }
+  EOT
+}
+
+output "pipeline_builds_variables" {
+  # [{ name = "pipeline", type = "ID!", required = true }, { name = "first", type = "Int", required = false }]
+  value = buildkite_portal.pipeline_builds.variables
+}
@@ -16,3 +16,37 @@ resource "buildkite_registry" "example" {
     build_branch: main
 YAML
 }
+
+# the same kind of policy as typed rules, checked at plan time
+resource "buildkite_registry" "typed_policy" {
+  name      = "typedpolicy"
+  ecosystem = "java"
+  team_ids  = [buildkite_team.backend_team.uuid]
+
+  oidc_policy_rules = [
+    {
+      scopes = ["read_packages"]
+      claims = {
+        organization_slug = "my-org"
+      }
+      conditions = [
+        { claim = "pipeline_slug", operator = "in", values = ["web", "api"] },
+        { claim = "build_branch", operator = "matches", values = ["main", "release/*"] },
+      ]
+    },
+  ]
+}
+
+resource "buildkite_registry" "internal_npm" {
+  name      = "internalnpm"
+  ecosystem = "npm"
+  team_ids  = [buildkite_team.frontend_team.uuid]
+}
+
+# a composite registry, whose upstreams are managed in Buildkite
+resource "buildkite_registry" "npm_proxy" {
+  name          = "npmproxy"
+  ecosystem     = "npm"
+  registry_type = "composite"
+  team_ids      = [buildkite_team.frontend_team.uuid]
+}
@@ -1,4 +1,4 @@
-# import a test suite resource using the GraphQL ID
+# import a test suite resource using its GraphQL ID or UUID
 #
 # you can use this query to find the ID:
 # query getSuiteIds {
@@ -7,6 +7,7 @@
 #       edges {
 #         node {
 #           id
+#           uuid
 #           name
 #         }
 #       }
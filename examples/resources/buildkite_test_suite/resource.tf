@@ -23,3 +23,41 @@ resource "buildkite_test_suite" "with_oidc_policy" {
       - write_uploads
   EOT
 }
+
+# the same policy as typed rules, rendered to oidc_policy
+resource "buildkite_test_suite" "with_oidc_policy_rules" {
+  name           = "with OIDC policy rules"
+  default_branch = "main"
+  team_owner_id  = "VGVhbvDf4eRef20tMzIxMGEfYTctNzEF5g00M8f5s6E2YjYtODNlOGNlZgD6HcBi"
+
+  oidc_policy_rules = [
+    {
+      scopes = ["read_suites", "write_uploads"]
+      claims = {
+        organization_slug = "my-org"
+        pipeline_slug     = "my-pipeline"
+      }
+    },
+  ]
+}
+
+# rotate the suite API token whenever rotation_date changes, without
+# recreating the suite
+resource "buildkite_test_suite" "with_token_rotation" {
+  name           = "with token rotation"
+  default_branch = "main"
+  team_owner_id  = "VGVhbvDf4eRef20tMzIxMGEfYTctNzEF5g00M8f5s6E2YjYtODNlOGNlZgD6HcBi"
+
+  api_token_rotation_triggers = {
+    rotation_date = "2026-10-01"
+  }
+}
+
+# keep the API token out of state, reading it through the
+# buildkite_test_suite_api_token ephemeral resource instead
+resource "buildkite_test_suite" "without_stored_token" {
+  name            = "without stored token"
+  default_branch  = "main"
+  team_owner_id   = "VGVhbvDf4eRef20tMzIxMGEfYTctNzEF5g00M8f5s6E2YjYtODNlOGNlZgD6HcBi"
+  store_api_token = false
+}
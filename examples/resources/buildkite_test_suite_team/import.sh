@@ -1,4 +1,4 @@
-# import a test suite team resource using the GraphQL ID
+# import a test suite team resource using its GraphQL ID or UUID
 #
 # you can use this query to find the ID:
 # query getTeamSuiteIds {
@@ -12,6 +12,7 @@
 #             edges {
 #               node {
 #                 id
+#                 uuid
 #                 accessLevel
 #                 team{
 #                   name
@@ -0,0 +1,894 @@
+package fakebuildkite
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// allowedPermissions grants the token's user every permission getOrganizationPermissions asks for.
+func allowedPermissions() Object {
+	permissions := Object{}
+	for _, name := range []string{
+		"agentTokenCreate",
+		"notificationServiceUpdate",
+		"organizationMemberUpdate",
+		"organizationUpdate",
+		"pipelineCreate",
+		"teamAdmin",
+		"teamCreate",
+	} {
+		permissions[name] = Object{"allowed": true}
+	}
+
+	return permissions
+}
+
+func registerOrganization(s *Server) {
+	organization := func(st *State, variables map[string]interface{}) interface{} {
+		if stringVar(variables, "slug") != st.orgSlug && stringVar(variables, "orgSlug") != st.orgSlug {
+			return nil
+		}
+		return st.org
+	}
+
+	// GetOrganizationID, sent by the shurcooL client, which rejects fields it did not ask for.
+	s.anonymous["organization"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if organization(st, variables) == nil {
+			return Object{"organization": nil}, nil
+		}
+		return Object{"organization": pick(st.org, "id")}, nil
+	}
+	s.graphql["getOrganization"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		return Object{"organization": organization(st, variables)}, nil
+	}
+	s.graphql["getOrganizationPermissions"] = s.graphql["getOrganization"]
+
+	s.graphql["setApiIpAddresses"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationID"); err != nil {
+			return nil, err
+		}
+		st.org["allowedApiIpAddresses"] = stringVar(variables, "ipAddresses")
+		return Object{"organizationApiIpAllowlistUpdate": Object{"organization": st.org}}, nil
+	}
+	s.graphql["setOrganization2FA"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationID"); err != nil {
+			return nil, err
+		}
+		st.org["membersRequireTwoFactorAuthentication"] = variables["value"] == true
+		return Object{"organizationEnforceTwoFactorAuthenticationForMembersUpdate": Object{"organization": st.org}}, nil
+	}
+
+	s.graphql["getNode"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		node, ok := st.Node(stringVar(variables, "id"))
+		if !ok {
+			return Object{"node": nil}, nil
+		}
+		return Object{"node": node}, nil
+	}
+}
+
+func registerClusters(s *Server) {
+	s.graphql["createCluster"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		id, uuid := st.NewID("Cluster")
+		cluster := Object{
+			"__typename":   "Cluster",
+			"id":           id,
+			"uuid":         uuid,
+			"name":         stringVar(variables, "name"),
+			"description":  nil,
+			"emoji":        nil,
+			"color":        nil,
+			"defaultQueue": nil,
+		}
+		copyVars(cluster, variables, "description", "emoji", "color")
+		st.PutNode(cluster)
+
+		return Object{"clusterCreate": Object{"clientMutationId": nil, "cluster": cluster}}, nil
+	}
+
+	s.graphql["updateCluster"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		cluster, err := findNode(st, "Cluster", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		if name := stringVar(variables, "name"); name != "" {
+			cluster["name"] = name
+		}
+		copyVars(cluster, variables, "description", "emoji", "color")
+
+		return Object{"clusterUpdate": Object{"clientMutationId": nil, "cluster": cluster}}, nil
+	}
+
+	setDefaultQueue := func(st *State, variables map[string]interface{}) (interface{}, error) {
+		cluster, err := findNode(st, "Cluster", stringVar(variables, "clusterId"))
+		if err != nil {
+			return nil, err
+		}
+		cluster["defaultQueue"] = nil
+		if queueID := stringVar(variables, "queueId"); queueID != "" {
+			queue, err := findNode(st, "ClusterQueue", queueID)
+			if err != nil {
+				return nil, err
+			}
+			cluster["defaultQueue"] = Object{"id": queue["id"], "uuid": queue["uuid"], "key": queue["key"], "description": queue["description"]}
+		}
+
+		return Object{"clusterUpdate": Object{"cluster": cluster}}, nil
+	}
+	s.graphql["setClusterDefaultQueue"] = setDefaultQueue
+	s.graphql["removeClusterDefaultQueue"] = setDefaultQueue
+
+	s.graphql["deleteCluster"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		cluster, err := findNode(st, "Cluster", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		for _, typename := range []string{"ClusterQueue", "ClusterToken"} {
+			for _, child := range st.Nodes(typename, belongsTo(cluster)) {
+				st.DeleteNode(child["id"].(string))
+			}
+		}
+		st.DeleteNode(cluster["id"].(string))
+
+		return Object{"clusterDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	listClusters := func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "orgSlug") != st.orgSlug && stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		clusters := st.Nodes("Cluster", nil)
+		sort.SliceStable(clusters, func(i, j int) bool {
+			return clusters[i]["name"].(string) < clusters[j]["name"].(string)
+		})
+		return Object{"organization": Object{"clusters": connection(clusters)}}, nil
+	}
+	s.graphql["getClusterByName"] = listClusters
+	s.graphql["GetOrganizationClusters"] = listClusters
+}
+
+func registerClusterQueues(s *Server) {
+	s.graphql["createClusterQueue"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		cluster, err := findNode(st, "Cluster", stringVar(variables, "clusterId"))
+		if err != nil {
+			return nil, err
+		}
+		key := stringVar(variables, "key")
+		if len(st.Nodes("ClusterQueue", func(queue Object) bool { return belongsTo(cluster)(queue) && queue["key"] == key })) > 0 {
+			return nil, fmt.Errorf("Key has already been taken")
+		}
+
+		id, uuid := st.NewID("ClusterQueue")
+		queue := Object{
+			"__typename":         "ClusterQueue",
+			"id":                 id,
+			"uuid":               uuid,
+			"key":                key,
+			"description":        nil,
+			"cluster":            Object{"id": cluster["id"], "uuid": cluster["uuid"]},
+			"hosted":             false,
+			"hostedAgents":       nil,
+			"dispatchPaused":     false,
+			"dispatchPausedAt":   nil,
+			"dispatchPausedBy":   nil,
+			"dispatchPausedNote": nil,
+		}
+		copyVars(queue, variables, "description")
+		if settings, ok := variables["hostedAgents"].(map[string]interface{}); ok {
+			queue["hosted"] = true
+			queue["hostedAgents"] = hostedAgents(nil, settings)
+		}
+		st.PutNode(queue)
+
+		return Object{"clusterQueueCreate": Object{"clusterQueue": queue}}, nil
+	}
+
+	s.graphql["updateClusterQueue"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		queue, err := findNode(st, "ClusterQueue", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		copyVars(queue, variables, "description")
+		if settings, ok := variables["hostedAgents"].(map[string]interface{}); ok {
+			if queue["hosted"] != true {
+				return nil, fmt.Errorf("Hosted agent settings can only be changed on a hosted queue")
+			}
+			existing, _ := queue["hostedAgents"].(Object)
+			queue["hostedAgents"] = hostedAgents(existing, settings)
+		}
+
+		return Object{"clusterQueueUpdate": Object{"clusterQueue": queue}}, nil
+	}
+
+	s.graphql["deleteClusterQueue"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		queue, err := findNode(st, "ClusterQueue", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		for _, cluster := range st.Nodes("Cluster", nil) {
+			if defaultQueue, ok := cluster["defaultQueue"].(Object); ok && defaultQueue["id"] == queue["id"] {
+				return nil, fmt.Errorf("The default queue of a cluster cannot be deleted")
+			}
+		}
+		st.DeleteNode(queue["id"].(string))
+
+		return Object{"clusterQueueDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	setPaused := func(paused bool, payload string) GraphQLHandler {
+		return func(st *State, variables map[string]interface{}) (interface{}, error) {
+			queue, err := findNode(st, "ClusterQueue", stringVar(variables, "id"))
+			if err != nil {
+				return nil, err
+			}
+			queue["dispatchPaused"] = paused
+			queue["dispatchPausedAt"] = nil
+			if paused {
+				queue["dispatchPausedAt"] = "2024-01-01T00:00:00Z"
+			}
+
+			return Object{payload: Object{"clientMutationId": nil}}, nil
+		}
+	}
+	s.graphql["pauseDispatchClusterQueue"] = setPaused(true, "clusterQueuePauseDispatch")
+	s.graphql["resumeDispatchClusterQueue"] = setPaused(false, "clusterQueueResumeDispatch")
+
+	s.graphql["getClusterQueues"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "orgSlug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		// The organization's cluster field takes the cluster's UUID
+		cluster, ok := st.NodeByUUID("Cluster", stringVar(variables, "id"))
+		if !ok {
+			return Object{"organization": Object{"cluster": nil}}, nil
+		}
+		queues := st.Nodes("ClusterQueue", belongsTo(cluster))
+		sort.SliceStable(queues, func(i, j int) bool {
+			return queues[i]["key"].(string) < queues[j]["key"].(string)
+		})
+
+		return Object{"organization": Object{"cluster": Object{"queues": connection(queues)}}}, nil
+	}
+	s.graphql["getClusterQueueByNode"] = s.graphql["getNode"]
+
+	// The provider's hosted agent catalogue query, which aliases two __type introspections.
+	s.anonymous["instanceShapes"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		return Object{
+			"instanceShapes": Object{"enumValues": st.EnumValues("HostedAgentInstanceShapeName")},
+			"macosVersions":  Object{"enumValues": st.EnumValues("HostedAgentMacOSVersion")},
+		}, nil
+	}
+}
+
+// defaultEnumValues is the hosted agent catalogue as the schema describes it.
+func defaultEnumValues() map[string][]Object {
+	value := func(name, description string) Object {
+		return Object{"name": name, "description": description, "isDeprecated": false}
+	}
+
+	shapes := []Object{}
+	for _, arch := range []string{"AMD64", "ARM64"} {
+		for _, size := range [][2]int{{2, 4}, {4, 16}, {8, 32}, {16, 64}, {32, 128}} {
+			shapes = append(shapes, value(
+				fmt.Sprintf("LINUX_%s_%dX%d", arch, size[0], size[1]),
+				fmt.Sprintf("Linux %d vCPU x %d GB Memory", size[0], size[1]),
+			))
+		}
+	}
+	shapes = append(shapes,
+		value("MACOS_M2_4X7", "macOS 4 vCPU x 7 GB Memory"),
+		value("MACOS_M2_6X14", "macOS 6 vCPU x 14 GB Memory"),
+		value("MACOS_M2_12X28", "macOS 12 vCPU x 28 GB Memory"),
+		value("MACOS_M4_12X56", "macOS 12 vCPU x 56 GB Memory"),
+		value("MACOS_ARM64_M4_6X28", "macOS 6 vCPU x 28 GB Memory"),
+		value("MACOS_ARM64_M4_12X56", "macOS 12 vCPU x 56 GB Memory"),
+	)
+
+	return map[string][]Object{
+		"HostedAgentInstanceShapeName": shapes,
+		"HostedAgentMacOSVersion": {
+			value("SONOMA", "macOS Sonoma (14.8.3)"),
+			value("SEQUOIA", "macOS Sequoia (15.7.5)"),
+			value("TAHOE", "macOS Tahoe (26.3.1)"),
+			value("TAHOE_SLIM", "macOS Tahoe (26.5)"),
+		},
+	}
+}
+
+// instanceShape matches hosted agent shape names such as LINUX_AMD64_2X4 and MACOS_M2_4X7.
+var instanceShape = regexp.MustCompile(`^([A-Z]+)_.*_(\d+)X(\d+)$`)
+
+// hostedAgents renders hosted agent settings from a create or update input, keeping whatever the
+// input leaves out from existing.
+func hostedAgents(existing Object, input map[string]interface{}) Object {
+	settings := Object{
+		"instanceShape": nil,
+		"platformSettings": Object{
+			"linux": Object{"agentImageRef": ""},
+			"macos": Object{"xcodeVersion": "", "macosVersion": nil},
+		},
+	}
+	if existing != nil {
+		settings = existing
+	}
+
+	if name, ok := input["instanceShape"].(string); ok && name != "" {
+		shape := Object{"name": name, "machineType": "LINUX", "architecture": "AMD64", "vcpu": 0, "memory": 0, "size": "SMALL"}
+		if match := instanceShape.FindStringSubmatch(name); match != nil {
+			vcpu, _ := strconv.Atoi(match[2])
+			memory, _ := strconv.Atoi(match[3])
+			shape["machineType"] = match[1]
+			shape["vcpu"] = vcpu
+			shape["memory"] = memory
+			shape["size"] = instanceSize(vcpu)
+			if match[1] == "MACOS" || strings.Contains(name, "ARM64") {
+				shape["architecture"] = "ARM64"
+			}
+		}
+		settings["instanceShape"] = shape
+	}
+
+	platform, _ := input["platformSettings"].(map[string]interface{})
+	rendered := settings["platformSettings"].(Object)
+	if linux, ok := platform["linux"].(map[string]interface{}); ok {
+		copyVars(rendered["linux"].(Object), linux, "agentImageRef")
+	}
+	if macos, ok := platform["macos"].(map[string]interface{}); ok {
+		copyVars(rendered["macos"].(Object), macos, "xcodeVersion", "macosVersion")
+	}
+
+	return settings
+}
+
+func instanceSize(vcpu int) string {
+	switch {
+	case vcpu <= 2:
+		return "SMALL"
+	case vcpu <= 4:
+		return "MEDIUM"
+	case vcpu <= 8:
+		return "LARGE"
+	case vcpu <= 16:
+		return "EXTRA_LARGE"
+	default:
+		return "EXTRA_EXTRA_LARGE"
+	}
+}
+
+func registerClusterAgentTokens(s *Server) {
+	s.graphql["createClusterAgentToken"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		cluster, err := findNode(st, "Cluster", stringVar(variables, "clusterId"))
+		if err != nil {
+			return nil, err
+		}
+		id, uuid := st.NewID("ClusterToken")
+		token := Object{
+			"__typename":         "ClusterToken",
+			"id":                 id,
+			"uuid":               uuid,
+			"description":        stringVar(variables, "description"),
+			"allowedIpAddresses": stringVar(variables, "allowedIpAddresses"),
+			"cluster":            Object{"id": cluster["id"], "uuid": cluster["uuid"]},
+		}
+		st.PutNode(token)
+
+		return Object{"clusterAgentTokenCreate": Object{"clusterAgentToken": token, "tokenValue": "token-" + uuid}}, nil
+	}
+
+	s.graphql["updateClusterAgentToken"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		token, err := findNode(st, "ClusterToken", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		token["description"] = stringVar(variables, "description")
+		token["allowedIpAddresses"] = stringVar(variables, "allowedIpAddresses")
+
+		return Object{"clusterAgentTokenUpdate": Object{"clusterAgentToken": token}}, nil
+	}
+
+	s.graphql["revokeClusterAgentToken"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		token, err := findNode(st, "ClusterToken", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(token["id"].(string))
+
+		return Object{"clusterAgentTokenRevoke": Object{"deletedClusterAgentTokenId": token["id"]}}, nil
+	}
+
+	s.graphql["getClusterAgentTokens"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "orgSlug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		// The organization's cluster field takes the cluster's UUID
+		cluster, ok := st.NodeByUUID("Cluster", stringVar(variables, "id"))
+		if !ok {
+			return Object{"organization": Object{"cluster": nil}}, nil
+		}
+
+		return Object{"organization": Object{"cluster": Object{"agentTokens": connection(st.Nodes("ClusterToken", belongsTo(cluster)))}}}, nil
+	}
+}
+
+// teamFields are the TeamFields a create or update sets from variables of the same name.
+var teamFields = []string{
+	"description",
+	"privacy",
+	"isDefaultTeam",
+	"defaultMemberRole",
+	"membersCanCreatePipelines",
+	"membersCanCreateSuites",
+	"membersCanCreateRegistries",
+	"membersCanDestroyRegistries",
+	"membersCanDestroyPackages",
+}
+
+func registerTeams(s *Server) {
+	teamBySlug := func(st *State, variables map[string]interface{}) interface{} {
+		slug := strings.TrimPrefix(stringVar(variables, "slug"), st.orgSlug+"/")
+		teams := st.Nodes("Team", func(team Object) bool { return team["slug"] == slug })
+		if len(teams) == 0 {
+			return nil
+		}
+		return teams[0]
+	}
+
+	// GetTeamID, sent by the shurcooL client, which rejects fields it did not ask for.
+	s.anonymous["team"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		team, ok := teamBySlug(st, variables).(Object)
+		if !ok {
+			return Object{"team": nil}, nil
+		}
+		return Object{"team": pick(team, "id", "uuid", "name", "slug", "description", "privacy", "isDefaultTeam", "defaultMemberRole", "membersCanCreatePipelines")}, nil
+	}
+	s.graphql["GetTeamFromSlug"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		return Object{"team": teamBySlug(st, variables)}, nil
+	}
+
+	s.graphql["teamCreate"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationID"); err != nil {
+			return nil, err
+		}
+		name := stringVar(variables, "name")
+		slug := slugify(name)
+		if len(st.Nodes("Team", func(team Object) bool { return team["slug"] == slug })) > 0 {
+			return nil, fmt.Errorf("Name has already been taken")
+		}
+
+		id, uuid := st.NewID("Team")
+		team := Object{
+			"__typename":                  "Team",
+			"id":                          id,
+			"uuid":                        uuid,
+			"name":                        name,
+			"slug":                        slug,
+			"description":                 "",
+			"privacy":                     "VISIBLE",
+			"isDefaultTeam":               false,
+			"defaultMemberRole":           "MEMBER",
+			"membersCanCreatePipelines":   false,
+			"membersCanCreateSuites":      false,
+			"membersCanCreateRegistries":  false,
+			"membersCanDestroyRegistries": false,
+			"membersCanDestroyPackages":   false,
+		}
+		copyVars(team, variables, teamFields...)
+		st.PutNode(team)
+
+		return Object{"teamCreate": Object{"teamEdge": Object{"node": team}}}, nil
+	}
+
+	s.graphql["teamUpdate"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		team, err := findNode(st, "Team", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		team["name"] = stringVar(variables, "name")
+		copyVars(team, variables, teamFields...)
+
+		return Object{"teamUpdate": Object{"team": team}}, nil
+	}
+
+	s.graphql["teamDelete"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		team, err := findNode(st, "Team", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		// Removing a team removes its members and its access to pipelines and suites
+		for _, typename := range []string{"TeamMember", "TeamPipeline", "TeamSuite"} {
+			for _, child := range st.Nodes(typename, ofTeam(team)) {
+				st.DeleteNode(child["id"].(string))
+			}
+		}
+		st.DeleteNode(team["id"].(string))
+		for _, pipeline := range st.Nodes("Pipeline", nil) {
+			refreshPipelineTeams(st, pipeline)
+		}
+		for _, suite := range st.Nodes("Suite", nil) {
+			refreshSuiteTeams(st, suite)
+		}
+
+		return Object{"teamDelete": Object{"deletedTeamID": team["id"]}}, nil
+	}
+
+	s.graphql["GetOrganizationTeams"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		return Object{"organization": Object{"teams": connection(st.Nodes("Team", nil))}}, nil
+	}
+}
+
+func registerTeamMembers(s *Server) {
+	s.graphql["createTeamMember"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		team, err := findNode(st, "Team", stringVar(variables, "teamID"))
+		if err != nil {
+			return nil, err
+		}
+		user, err := findNode(st, "User", stringVar(variables, "userID"))
+		if err != nil {
+			return nil, err
+		}
+		if len(st.Nodes("TeamMember", func(member Object) bool { return ofTeam(team)(member) && member["user"].(Object)["id"] == user["id"] })) > 0 {
+			return nil, fmt.Errorf("User is already a member of this team")
+		}
+		role := variables["role"]
+		if role == nil {
+			role = team["defaultMemberRole"]
+		}
+
+		id, uuid := st.NewID("TeamMember")
+		member := Object{
+			"__typename": "TeamMember",
+			"id":         id,
+			"uuid":       uuid,
+			"role":       role,
+			"team":       Object{"id": team["id"]},
+			"user":       Object{"id": user["id"]},
+		}
+		st.PutNode(member)
+
+		return Object{"teamMemberCreate": Object{"teamMemberEdge": Object{"node": member}}}, nil
+	}
+
+	s.graphql["updateTeamMember"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		member, err := findNode(st, "TeamMember", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		member["role"] = variables["role"]
+
+		return Object{"teamMemberUpdate": Object{"teamMember": member}}, nil
+	}
+
+	s.graphql["deleteTeamMember"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		member, err := findNode(st, "TeamMember", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(member["id"].(string))
+
+		return Object{"teamMemberDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	// Members are ordered by the name of their user.
+	s.graphql["GetTeamMembers"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		slug := strings.TrimPrefix(stringVar(variables, "slug"), st.orgSlug+"/")
+		teams := st.Nodes("Team", func(team Object) bool { return team["slug"] == slug })
+		if len(teams) == 0 {
+			return Object{"team": nil}, nil
+		}
+		members := st.Nodes("TeamMember", ofTeam(teams[0]))
+		name := func(member Object) string {
+			user, _ := st.Node(member["user"].(Object)["id"].(string))
+			name, _ := user["name"].(string)
+			return name
+		}
+		sort.SliceStable(members, func(i, j int) bool { return name(members[i]) < name(members[j]) })
+
+		return Object{"team": Object{"members": connection(members)}}, nil
+	}
+}
+
+func registerOrganizationMembers(s *Server) {
+	member := func(user Object) Object {
+		return Object{"user": pick(user, "id", "uuid", "name", "email")}
+	}
+
+	s.graphql["GetOrganizationMembers"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		members := []Object{}
+		for _, user := range st.Nodes("User", nil) {
+			members = append(members, member(user))
+		}
+		return Object{"organization": Object{"members": connection(members)}}, nil
+	}
+
+	s.graphql["GetOrganizationMemberByEmail"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		members := []Object{}
+		email := stringVar(variables, "email")
+		for _, user := range st.Nodes("User", func(user Object) bool { return strings.EqualFold(user["email"].(string), email) }) {
+			members = append(members, member(user))
+		}
+		return Object{"organization": Object{"members": connection(members)}}, nil
+	}
+}
+
+func registerAgentTokens(s *Server) {
+	s.graphql["createAgentToken"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		id, uuid := st.NewID("AgentToken")
+		token := Object{
+			"__typename":  "AgentToken",
+			"id":          id,
+			"uuid":        uuid,
+			"description": nil,
+		}
+		copyVars(token, variables, "description")
+		st.PutNode(token)
+
+		return Object{"agentTokenCreate": Object{"tokenValue": "token-" + uuid, "agentTokenEdge": Object{"node": token}}}, nil
+	}
+
+	// A revoked token can no longer be found.
+	s.graphql["revokeAgentToken"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		token, err := findNode(st, "AgentToken", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(token["id"].(string))
+
+		return Object{"agentTokenRevoke": Object{"agentToken": token}}, nil
+	}
+
+	// Agent token slugs are the organization and the token's UUID, joined with a slash.
+	s.graphql["getAgentToken"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		token, ok := st.NodeByUUID("AgentToken", strings.TrimPrefix(stringVar(variables, "slug"), st.orgSlug+"/"))
+		if !ok {
+			return Object{"agentToken": nil}, nil
+		}
+		return Object{"agentToken": token}, nil
+	}
+}
+
+func registerBanners(s *Server) {
+	s.graphql["upsertBanner"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		banners := st.Nodes("OrganizationBanner", nil)
+		if len(banners) == 0 {
+			id, uuid := st.NewID("OrganizationBanner")
+			banners = append(banners, Object{"__typename": "OrganizationBanner", "id": id, "uuid": uuid})
+			st.PutNode(banners[0])
+		}
+		banners[0]["message"] = stringVar(variables, "message")
+
+		return Object{"organizationBannerUpsert": Object{"clientMutationId": nil, "banner": banners[0]}}, nil
+	}
+
+	s.graphql["deleteBanner"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		for _, banner := range st.Nodes("OrganizationBanner", nil) {
+			st.DeleteNode(banner["id"].(string))
+		}
+
+		return Object{"organizationBannerDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	s.graphql["getOrganiztionBanner"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "orgSlug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		return Object{"organization": Object{"banners": connection(st.Nodes("OrganizationBanner", nil))}}, nil
+	}
+}
+
+// registerTestSuiteTeams serves the GraphQL side of test suites, which are created through REST.
+func registerTestSuiteTeams(s *Server) {
+	s.graphql["getTestSuite"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		suite, ok := st.Node(stringVar(variables, "id"))
+		if !ok {
+			return Object{"suite": nil}, nil
+		}
+		return Object{"suite": suite}, nil
+	}
+	s.graphql["GetOrganizationTestSuiteTeams"] = s.graphql["getTestSuite"]
+
+	s.graphql["createTestSuiteTeam"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		teamSuite, err := addTeamSuite(st, stringVar(variables, "teamId"), stringVar(variables, "suiteId"), variables["accessLevel"])
+		if err != nil {
+			return nil, err
+		}
+		suite, _ := st.Node(stringVar(variables, "suiteId"))
+		refreshSuiteTeams(st, suite)
+
+		return Object{"teamSuiteCreate": Object{"suite": pick(suite, "teams"), "teamSuite": teamSuite}}, nil
+	}
+
+	s.graphql["updateTestSuiteTeam"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		teamSuite, err := findNode(st, "TeamSuite", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		teamSuite["accessLevel"] = variables["accessLevel"]
+
+		return Object{"teamSuiteUpdate": Object{"teamSuite": teamSuite}}, nil
+	}
+
+	s.graphql["deleteTestSuiteTeam"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		teamSuite, err := findNode(st, "TeamSuite", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(teamSuite["id"].(string))
+		suite, _ := st.Node(teamSuite["suite"].(Object)["id"].(string))
+		refreshSuiteTeams(st, suite)
+
+		return Object{"teamSuiteDelete": Object{"deletedTeamSuiteID": teamSuite["id"], "team": Object{"id": teamSuite["team"].(Object)["id"]}}}, nil
+	}
+
+	// Suites are ordered by name, and search matches part of it.
+	s.graphql["GetOrganizationTestSuites"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		search := strings.ToLower(stringVar(variables, "search"))
+		suites := st.Nodes("Suite", func(suite Object) bool {
+			return strings.Contains(strings.ToLower(suite["name"].(string)), search)
+		})
+		sort.SliceStable(suites, func(i, j int) bool {
+			return suites[i]["name"].(string) < suites[j]["name"].(string)
+		})
+
+		return Object{"organization": Object{"suites": connection(suites)}}, nil
+	}
+}
+
+// addTeamSuite gives a team access to a suite. The caller refreshes the suite's teams.
+func addTeamSuite(st *State, teamID, suiteID string, accessLevel interface{}) (Object, error) {
+	team, err := findNode(st, "Team", teamID)
+	if err != nil {
+		return nil, err
+	}
+	suite, err := findNode(st, "Suite", suiteID)
+	if err != nil {
+		return nil, err
+	}
+	if len(st.Nodes("TeamSuite", func(node Object) bool { return ofTeam(team)(node) && node["suite"].(Object)["id"] == suite["id"] })) > 0 {
+		return nil, fmt.Errorf("The team already has access to this suite")
+	}
+
+	id, uuid := st.NewID("TeamSuite")
+	teamSuite := Object{
+		"__typename":    "TeamSuite",
+		"id":            id,
+		"uuid":          uuid,
+		"teamSuiteUuid": uuid,
+		"accessLevel":   accessLevel,
+		"team":          Object{"id": team["id"], "slug": team["slug"]},
+		"suite":         Object{"id": suite["id"]},
+	}
+	st.PutNode(teamSuite)
+
+	return teamSuite, nil
+}
+
+// refreshSuiteTeams renders a suite's teams connection from its TeamSuite nodes.
+func refreshSuiteTeams(st *State, suite Object) {
+	if suite == nil {
+		return
+	}
+	suite["teams"] = teamsConnection(st, st.Nodes("TeamSuite", func(node Object) bool { return node["suite"].(Object)["id"] == suite["id"] }))
+}
+
+// ofTeam matches nodes whose "team" is the given team.
+func ofTeam(team Object) func(Object) bool {
+	return func(node Object) bool {
+		parent, ok := node["team"].(Object)
+		return ok && parent["id"] == team["id"]
+	}
+}
+
+// teamsConnection renders the nodes joining teams to a pipeline or suite as a connection ordered by
+// team name, with the count pipelines report.
+func teamsConnection(st *State, nodes []Object) Object {
+	name := func(node Object) string {
+		team, _ := st.Node(node["team"].(Object)["id"].(string))
+		name, _ := team["name"].(string)
+		return name
+	}
+	sort.SliceStable(nodes, func(i, j int) bool { return name(nodes[i]) < name(nodes[j]) })
+
+	teams := connection(nodes)
+	teams["count"] = len(nodes)
+	return teams
+}
+
+// stringVar returns a string variable, or "" when it is missing or null.
+func stringVar(variables map[string]interface{}, name string) string {
+	value, _ := variables[name].(string)
+	return value
+}
+
+// copyVars copies the named variables into object when they were sent, explicit nulls included,
+// which is how the API treats a null in an update input.
+func copyVars(object Object, variables map[string]interface{}, names ...string) {
+	for _, name := range names {
+		if value, ok := variables[name]; ok {
+			object[name] = value
+		}
+	}
+}
+
+func checkOrganization(st *State, variables map[string]interface{}, name string) error {
+	if id := stringVar(variables, name); id != st.org["id"] {
+		return fmt.Errorf("No organization found with ID %q", id)
+	}
+	return nil
+}
+
+func findNode(st *State, typename, id string) (Object, error) {
+	node, ok := st.Node(id)
+	if !ok || node["__typename"] != typename {
+		return nil, fmt.Errorf("No %s found with ID %q", typename, id)
+	}
+	return node, nil
+}
+
+// pick copies the named fields of object.
+func pick(object Object, names ...string) Object {
+	picked := Object{}
+	for _, name := range names {
+		picked[name] = object[name]
+	}
+	return picked
+}
+
+// belongsTo matches nodes whose "cluster" is the given cluster.
+func belongsTo(cluster Object) func(Object) bool {
+	return func(node Object) bool {
+		parent, ok := node["cluster"].(Object)
+		return ok && parent["id"] == cluster["id"]
+	}
+}
+
+// connection renders nodes as a single, complete page of a GraphQL connection.
+func connection(nodes []Object) Object {
+	edges := make([]Object, 0, len(nodes))
+	for _, node := range nodes {
+		edges = append(edges, Object{"node": node})
+	}
+
+	return Object{
+		"pageInfo": Object{"endCursor": nil, "hasNextPage": false},
+		"edges":    edges,
+	}
+}
+
+var nonSlugCharacters = regexp.MustCompile(`[^a-z0-9]+`)
+
+func slugify(name string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,699 @@
+package fakebuildkite
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// pipelineFields are the PipelineFields a create or update sets from input fields of the same name.
+var pipelineFields = []string{
+	"allowRebuilds",
+	"branchConfiguration",
+	"cancelIntermediateBuilds",
+	"cancelIntermediateBuildsBranchFilter",
+	"cloneMirrorUrl",
+	"color",
+	"defaultBranch",
+	"defaultTimeoutInMinutes",
+	"description",
+	"emoji",
+	"maximumTimeoutInMinutes",
+	"skipIntermediateBuilds",
+	"skipIntermediateBuildsBranchFilter",
+	"visibility",
+}
+
+func registerPipelines(s *Server) {
+	s.graphql["createPipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		input, _ := variables["input"].(map[string]interface{})
+		if err := checkOrganization(st, input, "organizationId"); err != nil {
+			return nil, err
+		}
+		name := stringVar(input, "name")
+		slug := slugify(name)
+		if _, ok := pipelineBySlug(st, slug); ok {
+			return nil, fmt.Errorf("Name has already been taken")
+		}
+
+		id, uuid := st.NewID("Pipeline")
+		pipeline := Object{
+			"__typename":                           "Pipeline",
+			"id":                                   id,
+			"uuid":                                 uuid,
+			"pipelineUuid":                         uuid,
+			"name":                                 name,
+			"slug":                                 slug,
+			"description":                          "",
+			"allowRebuilds":                        true,
+			"badgeURL":                             "https://badge.buildkite.com/" + uuid + ".svg",
+			"webhookURL":                           "https://webhook.buildkite.com/deliver/" + uuid,
+			"branchConfiguration":                  nil,
+			"cancelIntermediateBuilds":             false,
+			"cancelIntermediateBuildsBranchFilter": "",
+			"skipIntermediateBuilds":               false,
+			"skipIntermediateBuildsBranchFilter":   "",
+			"cloneMirrorUrl":                       nil,
+			"cluster":                              nil,
+			"color":                                nil,
+			"emoji":                                nil,
+			"defaultBranch":                        "",
+			"defaultTimeoutInMinutes":              nil,
+			"maximumTimeoutInMinutes":              nil,
+			"pipelineTemplate":                     nil,
+			"repository":                           repository(""),
+			"steps":                                Object{"yaml": ""},
+			"tags":                                 []Object{},
+			"archived":                             false,
+			"visibility":                           "PRIVATE",
+		}
+		if err := applyPipelineInput(st, pipeline, input); err != nil {
+			return nil, err
+		}
+		teams, _ := input["teams"].([]interface{})
+		for _, value := range teams {
+			team, _ := value.(map[string]interface{})
+			if _, err := findNode(st, "Team", stringVar(team, "id")); err != nil {
+				return nil, err
+			}
+		}
+		st.PutNode(pipeline)
+
+		for _, value := range teams {
+			team, _ := value.(map[string]interface{})
+			if _, err := addTeamPipeline(st, stringVar(team, "id"), id, team["accessLevel"]); err != nil {
+				return nil, err
+			}
+		}
+		refreshPipelineTeams(st, pipeline)
+
+		return Object{"pipelineCreate": Object{"pipeline": pipeline}}, nil
+	}
+
+	s.graphql["updatePipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		input, _ := variables["input"].(map[string]interface{})
+		pipeline, err := findNode(st, "Pipeline", stringVar(input, "id"))
+		if err != nil {
+			return nil, err
+		}
+		if pipeline["archived"] == true {
+			return nil, fmt.Errorf("Archived pipelines cannot be updated")
+		}
+		if name := stringVar(input, "name"); name != "" {
+			pipeline["name"] = name
+		}
+		if err := applyPipelineInput(st, pipeline, input); err != nil {
+			return nil, err
+		}
+
+		return Object{"pipelineUpdate": Object{"pipeline": pipeline}}, nil
+	}
+
+	s.graphql["deletePipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, err := findNode(st, "Pipeline", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		for _, typename := range []string{"TeamPipeline", "PipelineSchedule"} {
+			for _, child := range st.Nodes(typename, ofPipeline(pipeline)) {
+				st.DeleteNode(child["id"].(string))
+			}
+		}
+		st.DeleteNode(pipeline["id"].(string))
+
+		return Object{"pipelineDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	setArchived := func(archived bool, payload string) GraphQLHandler {
+		return func(st *State, variables map[string]interface{}) (interface{}, error) {
+			pipeline, err := findNode(st, "Pipeline", stringVar(variables, "id"))
+			if err != nil {
+				return nil, err
+			}
+			pipeline["archived"] = archived
+
+			return Object{payload: Object{"clientMutationId": nil}}, nil
+		}
+	}
+	s.graphql["archivePipeline"] = setArchived(true, "pipelineArchive")
+	s.graphql["unarchivePipeline"] = setArchived(false, "pipelineUnarchive")
+
+	s.graphql["getPipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, ok := pipelineBySlug(st, strings.TrimPrefix(stringVar(variables, "slug"), st.orgSlug+"/"))
+		if !ok {
+			return Object{"pipeline": nil}, nil
+		}
+		return Object{"pipeline": pipeline}, nil
+	}
+	s.graphql["getPipelineTeams"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, ok := pipelineBySlug(st, strings.TrimPrefix(stringVar(variables, "slug"), st.orgSlug+"/"))
+		if !ok {
+			return Object{"pipeline": nil}, nil
+		}
+		return Object{"pipeline": pick(pipeline, "teams")}, nil
+	}
+	s.graphql["getPipelineProviderSettings"] = s.graphql["getNode"]
+	s.graphql["getPipelineWebhook"] = s.graphql["getNode"]
+
+	s.graphql["GetOrganizationPipelines"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		pipelines := []Object{}
+		for _, pipeline := range st.Nodes("Pipeline", nil) {
+			schedules := st.Nodes("PipelineSchedule", ofPipeline(pipeline))
+			scheduleConnection := connection(schedules)
+			scheduleConnection["count"] = len(schedules)
+			rendered := Object{"schedules": scheduleConnection}
+			for key, value := range pipeline {
+				rendered[key] = value
+			}
+			pipelines = append(pipelines, rendered)
+		}
+		sort.SliceStable(pipelines, func(i, j int) bool {
+			return pipelines[i]["name"].(string) < pipelines[j]["name"].(string)
+		})
+
+		return Object{"organization": Object{"pipelines": connection(pipelines)}}, nil
+	}
+
+	// The fake runs no builds, so there are never any to wait for or cancel.
+	s.graphql["getPipelineUnfinishedBuilds"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, ok := st.Node(stringVar(variables, "id"))
+		if !ok {
+			return Object{"node": nil}, nil
+		}
+		return Object{"node": Object{"__typename": pipeline["__typename"], "builds": connection(nil)}}, nil
+	}
+	s.graphql["cancelBuild"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("No Build found with ID %q", stringVar(variables, "id"))
+	}
+
+	s.graphql["createPipelineWebhook"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, err := findNode(st, "Pipeline", stringVar(variables, "pipelineId"))
+		if err != nil {
+			return nil, err
+		}
+		provider := repositoryProvider(pipeline)
+		if _, ok := provider["webhook"]; !ok {
+			return nil, fmt.Errorf("Webhooks can only be created for GitHub repositories")
+		}
+		if provider["webhook"] != nil {
+			return nil, fmt.Errorf("A webhook already exists for this repository")
+		}
+		st.sequence++
+		webhook := Object{
+			"__typename": "RepositoryProviderGithubWebhook",
+			"externalId": fmt.Sprint(st.sequence),
+			"url":        fmt.Sprintf("%s/hooks/%d", pipeline["repository"].(Object)["url"], st.sequence),
+		}
+		provider["webhook"] = webhook
+
+		return Object{"pipelineCreateWebhook": Object{
+			"pipeline": pick(pipeline, "id", "repository"),
+			"webhook":  webhook,
+		}}, nil
+	}
+	s.graphql["deletePipelineWebhook"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, err := findNode(st, "Pipeline", stringVar(variables, "pipelineId"))
+		if err != nil {
+			return nil, err
+		}
+		provider := repositoryProvider(pipeline)
+		webhook, ok := provider["webhook"].(Object)
+		if !ok {
+			return nil, fmt.Errorf("No webhook exists for this repository")
+		}
+		provider["webhook"] = nil
+
+		return Object{"pipelineDeleteWebhook": Object{
+			"pipeline":                 pick(pipeline, "id"),
+			"deletedWebhookExternalId": webhook["externalId"],
+		}}, nil
+	}
+
+	s.HandleREST(http.MethodPatch, "/v2/organizations/{org}/pipelines/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		pipeline, ok := pipelineBySlug(st, req.Params["slug"])
+		if !ok {
+			return notFound()
+		}
+		if pipeline["archived"] == true {
+			return http.StatusUnprocessableEntity, Object{"message": "Archived pipelines cannot be updated"}
+		}
+		if slug, ok := req.Body["slug"].(string); ok && slug != pipeline["slug"] {
+			if _, exists := pipelineBySlug(st, slug); exists {
+				return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Slug has already been taken"}
+			}
+			pipeline["slug"] = slug
+		}
+		settings := repositoryProvider(pipeline)["settings"].(Object)
+		if requested, ok := req.Body["provider_settings"].(map[string]interface{}); ok {
+			for key, value := range requested {
+				settings[camelCase(key)] = value
+			}
+		}
+
+		rendered := Object{}
+		for key, value := range settings {
+			rendered[snakeCase(key)] = value
+		}
+		return http.StatusOK, Object{"slug": pipeline["slug"], "provider": Object{"settings": rendered}}
+	})
+}
+
+// applyPipelineInput sets the fields of a PipelineCreateInput or PipelineUpdateInput on pipeline.
+func applyPipelineInput(st *State, pipeline Object, input map[string]interface{}) error {
+	copyVars(pipeline, input, pipelineFields...)
+
+	if value, ok := input["clusterId"]; ok {
+		pipeline["cluster"] = nil
+		if id, _ := value.(string); id != "" {
+			cluster, err := findNode(st, "Cluster", id)
+			if err != nil {
+				return err
+			}
+			pipeline["cluster"] = Object{"id": cluster["id"], "name": cluster["name"]}
+		}
+	}
+	if value, ok := input["pipelineTemplateId"]; ok {
+		pipeline["pipelineTemplate"] = nil
+		if id, _ := value.(string); id != "" {
+			if _, err := findNode(st, "PipelineTemplate", id); err != nil {
+				return err
+			}
+			pipeline["pipelineTemplate"] = Object{"id": id}
+		}
+	}
+	if value, ok := input["repository"].(map[string]interface{}); ok {
+		url := stringVar(value, "url")
+		if existing, _ := pipeline["repository"].(Object); existing == nil || existing["url"] != url {
+			pipeline["repository"] = repository(url)
+		}
+	}
+	if value, ok := input["steps"].(map[string]interface{}); ok {
+		pipeline["steps"] = Object{"yaml": stringVar(value, "yaml")}
+	}
+	if value, ok := input["tags"].([]interface{}); ok {
+		tags := []Object{}
+		for _, tag := range value {
+			label, _ := tag.(map[string]interface{})
+			tags = append(tags, Object{"label": stringVar(label, "label")})
+		}
+		pipeline["tags"] = tags
+	}
+
+	return nil
+}
+
+// repository renders a pipeline's repository. GitHub repositories can have a webhook, and both
+// kinds keep the provider settings the REST API sets, by their GraphQL names.
+func repository(url string) Object {
+	provider := Object{"__typename": "RepositoryProviderUnknown", "settings": Object{}}
+	if strings.Contains(url, "github.com") {
+		provider = Object{"__typename": "RepositoryProviderGithub", "settings": Object{}, "webhook": nil}
+	}
+
+	return Object{"url": url, "provider": provider}
+}
+
+// repositoryProvider returns a pipeline's repository provider, adding one to a pipeline a test put
+// without it.
+func repositoryProvider(pipeline Object) Object {
+	repo, ok := pipeline["repository"].(Object)
+	if !ok {
+		repo = Object{"url": ""}
+		pipeline["repository"] = repo
+	}
+	provider, ok := repo["provider"].(Object)
+	if !ok {
+		url, _ := repo["url"].(string)
+		provider = repository(url)["provider"].(Object)
+		repo["provider"] = provider
+	}
+
+	return provider
+}
+
+func pipelineBySlug(st *State, slug string) (Object, bool) {
+	pipelines := st.Nodes("Pipeline", func(pipeline Object) bool { return pipeline["slug"] == slug })
+	if len(pipelines) == 0 {
+		return nil, false
+	}
+
+	return pipelines[0], true
+}
+
+// ofPipeline matches nodes whose "pipeline" is the given pipeline.
+func ofPipeline(pipeline Object) func(Object) bool {
+	return func(node Object) bool {
+		parent, ok := node["pipeline"].(Object)
+		return ok && parent["id"] == pipeline["id"]
+	}
+}
+
+var (
+	snakeBoundary = regexp.MustCompile(`_([a-z])`)
+	camelBoundary = regexp.MustCompile(`([A-Z])`)
+)
+
+func camelCase(name string) string {
+	return snakeBoundary.ReplaceAllStringFunc(name, func(match string) string { return strings.ToUpper(match[1:]) })
+}
+
+func snakeCase(name string) string {
+	return camelBoundary.ReplaceAllStringFunc(name, func(match string) string { return "_" + strings.ToLower(match) })
+}
+
+func registerTeamPipelines(s *Server) {
+	s.graphql["createTeamPipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		teamPipeline, err := addTeamPipeline(st, stringVar(variables, "teamID"), stringVar(variables, "pipelineID"), variables["accessLevel"])
+		if err != nil {
+			return nil, err
+		}
+		pipeline, _ := st.Node(stringVar(variables, "pipelineID"))
+		refreshPipelineTeams(st, pipeline)
+
+		return Object{"teamPipelineCreate": Object{"teamPipelineEdge": Object{"node": teamPipeline}}}, nil
+	}
+
+	s.graphql["updateTeamPipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		teamPipeline, err := findNode(st, "TeamPipeline", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		teamPipeline["accessLevel"] = variables["accessLevel"]
+		teamPipeline["pipelineAccessLevel"] = variables["accessLevel"]
+
+		return Object{"teamPipelineUpdate": Object{"teamPipeline": teamPipeline}}, nil
+	}
+
+	s.graphql["deleteTeamPipeline"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		teamPipeline, err := findNode(st, "TeamPipeline", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(teamPipeline["id"].(string))
+		pipeline, _ := st.Node(teamPipeline["pipeline"].(Object)["id"].(string))
+		refreshPipelineTeams(st, pipeline)
+
+		return Object{"teamPipelineDelete": Object{"deletedTeamPipelineID": teamPipeline["id"], "clientMutationId": nil}}, nil
+	}
+}
+
+// addTeamPipeline gives a team access to a pipeline. The caller refreshes the pipeline's teams.
+func addTeamPipeline(st *State, teamID, pipelineID string, accessLevel interface{}) (Object, error) {
+	team, err := findNode(st, "Team", teamID)
+	if err != nil {
+		return nil, err
+	}
+	pipeline, err := findNode(st, "Pipeline", pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if len(st.Nodes("TeamPipeline", func(node Object) bool { return ofPipeline(pipeline)(node) && ofTeam(team)(node) })) > 0 {
+		return nil, fmt.Errorf("The team already has access to this pipeline")
+	}
+	if accessLevel == nil {
+		accessLevel = "MANAGE_BUILD_AND_READ"
+	}
+
+	id, uuid := st.NewID("TeamPipeline")
+	teamPipeline := Object{
+		"__typename":          "TeamPipeline",
+		"id":                  id,
+		"uuid":                uuid,
+		"accessLevel":         accessLevel,
+		"pipelineAccessLevel": accessLevel,
+		"team":                Object{"id": team["id"]},
+		"pipeline":            Object{"id": pipeline["id"]},
+	}
+	st.PutNode(teamPipeline)
+
+	return teamPipeline, nil
+}
+
+// refreshPipelineTeams renders a pipeline's teams connection from its TeamPipeline nodes, ordered by
+// team name as the provider queries them.
+func refreshPipelineTeams(st *State, pipeline Object) {
+	if pipeline == nil {
+		return
+	}
+	pipeline["teams"] = teamsConnection(st, st.Nodes("TeamPipeline", ofPipeline(pipeline)))
+}
+
+func registerPipelineSchedules(s *Server) {
+	s.graphql["createPipelineSchedule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		pipeline, err := findNode(st, "Pipeline", stringVar(variables, "pipelineId"))
+		if err != nil {
+			return nil, err
+		}
+
+		id, uuid := st.NewID("PipelineSchedule")
+		schedule := Object{
+			"__typename":  "PipelineSchedule",
+			"id":          id,
+			"uuid":        uuid,
+			"label":       nil,
+			"cronline":    nil,
+			"message":     nil,
+			"commit":      nil,
+			"branch":      nil,
+			"env":         nil,
+			"enabled":     true,
+			"nextBuildAt": nil,
+			"pipeline":    Object{"id": pipeline["id"]},
+		}
+		applyScheduleInput(schedule, variables)
+		st.PutNode(schedule)
+
+		return Object{"pipelineScheduleCreate": Object{
+			"pipeline":             Object{"id": pipeline["id"]},
+			"pipelineScheduleEdge": Object{"node": schedule},
+		}}, nil
+	}
+
+	s.graphql["updatePipelineSchedule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		input, _ := variables["input"].(map[string]interface{})
+		schedule, err := findNode(st, "PipelineSchedule", stringVar(input, "id"))
+		if err != nil {
+			return nil, err
+		}
+		applyScheduleInput(schedule, input)
+
+		return Object{"pipelineScheduleUpdate": Object{"pipelineSchedule": schedule}}, nil
+	}
+
+	s.graphql["deletePipelineSchedule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		schedule, err := findNode(st, "PipelineSchedule", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(schedule["id"].(string))
+
+		return Object{"pipelineScheduleDelete": Object{"deletedPipelineScheduleID": schedule["id"]}}, nil
+	}
+
+	s.graphql["getPipelineSchedule"] = s.graphql["getNode"]
+	// Schedule slugs are the organization, pipeline and schedule UUID, joined with slashes.
+	s.graphql["getPipelineScheduleBySlug"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		parts := strings.Split(stringVar(variables, "slug"), "/")
+		schedule, ok := st.NodeByUUID("PipelineSchedule", parts[len(parts)-1])
+		if !ok || len(parts) != 3 || parts[0] != st.orgSlug {
+			return Object{"pipelineSchedule": nil}, nil
+		}
+		return Object{"pipelineSchedule": schedule}, nil
+	}
+}
+
+// applyScheduleInput sets the fields a schedule create or update was sent. The API takes env as
+// lines of KEY=VALUE and returns it as a list.
+func applyScheduleInput(schedule Object, input map[string]interface{}) {
+	copyVars(schedule, input, "label", "cronline", "message", "commit", "branch")
+	if enabled, ok := input["enabled"].(bool); ok {
+		schedule["enabled"] = enabled
+	}
+	if value, ok := input["env"]; ok {
+		schedule["env"] = nil
+		if env, _ := value.(string); env != "" {
+			schedule["env"] = strings.Split(env, "\n")
+		}
+	}
+}
+
+func registerPipelineTemplates(s *Server) {
+	s.graphql["createPipelineTemplate"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		id, uuid := st.NewID("PipelineTemplate")
+		template := Object{
+			"__typename":    "PipelineTemplate",
+			"id":            id,
+			"uuid":          uuid,
+			"name":          stringVar(variables, "name"),
+			"configuration": stringVar(variables, "configuration"),
+			"description":   nil,
+			"available":     false,
+		}
+		copyVars(template, variables, "description", "available")
+		st.PutNode(template)
+
+		return Object{"pipelineTemplateCreate": Object{"pipelineTemplate": template}}, nil
+	}
+
+	s.graphql["updatePipelineTemplate"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		template, err := findNode(st, "PipelineTemplate", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		copyVars(template, variables, "name", "configuration", "description", "available")
+
+		return Object{"pipelineTemplateUpdate": Object{"pipelineTemplate": template}}, nil
+	}
+
+	s.graphql["deletePipelineTemplate"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		template, err := findNode(st, "PipelineTemplate", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(template["id"].(string))
+
+		return Object{"pipelineTemplateDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	s.graphql["getPipelineTemplates"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "orgSlug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		templates := st.Nodes("PipelineTemplate", nil)
+		sort.SliceStable(templates, func(i, j int) bool {
+			return templates[i]["name"].(string) < templates[j]["name"].(string)
+		})
+
+		return Object{"organization": Object{"pipelineTemplates": connection(templates)}}, nil
+	}
+}
+
+// ruleActions are the actions of the rule types the API accepts, by type.
+var ruleActions = map[string]string{
+	"pipeline.trigger_build.pipeline":  "TRIGGER_BUILD",
+	"pipeline.artifacts_read.pipeline": "ARTIFACTS_READ",
+}
+
+func registerRules(s *Server) {
+	s.graphql["createOrganizationRule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		ruleType := stringVar(variables, "ruleType")
+		action, ok := ruleActions[ruleType]
+		if !ok {
+			return nil, fmt.Errorf("Rule type %q is not supported", ruleType)
+		}
+
+		id, uuid := st.NewID("Rule")
+		rule := Object{
+			"__typename":  "Rule",
+			"id":          id,
+			"uuid":        uuid,
+			"type":        ruleType,
+			"description": nil,
+			"sourceType":  "PIPELINE",
+			"targetType":  "PIPELINE",
+			"effect":      "ALLOW",
+			"action":      action,
+		}
+		copyVars(rule, variables, "description")
+		if err := applyRuleValue(st, rule, stringVar(variables, "value")); err != nil {
+			return nil, err
+		}
+		st.PutNode(rule)
+
+		return Object{"ruleCreate": Object{"rule": rule}}, nil
+	}
+
+	s.graphql["updateOrganizationRule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		rule, err := findNode(st, "Rule", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		copyVars(rule, variables, "description")
+		if err := applyRuleValue(st, rule, stringVar(variables, "value")); err != nil {
+			return nil, err
+		}
+
+		return Object{"ruleUpdate": Object{"rule": rule}}, nil
+	}
+
+	s.graphql["deleteOrganizationRule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if err := checkOrganization(st, variables, "organizationId"); err != nil {
+			return nil, err
+		}
+		rule, err := findNode(st, "Rule", stringVar(variables, "id"))
+		if err != nil {
+			return nil, err
+		}
+		st.DeleteNode(rule["id"].(string))
+
+		return Object{"ruleDelete": Object{"clientMutationId": nil}}, nil
+	}
+
+	s.graphql["getOrganizationRule"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		rule, ok := st.NodeByUUID("Rule", stringVar(variables, "uuid"))
+		if !ok {
+			return Object{"rule": nil}, nil
+		}
+		return Object{"rule": rule}, nil
+	}
+
+	// Rules are listed most recently created first.
+	s.graphql["GetOrganizationRules"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		if stringVar(variables, "slug") != st.orgSlug {
+			return Object{"organization": nil}, nil
+		}
+		rules := st.Nodes("Rule", nil)
+		for i, j := 0, len(rules)-1; i < j; i, j = i+1, j-1 {
+			rules[i], rules[j] = rules[j], rules[i]
+		}
+
+		return Object{"organization": Object{"rules": connection(rules)}}, nil
+	}
+}
+
+// applyRuleValue sets a rule's document, source and target from its JSON value, whose
+// source_pipeline and target_pipeline are pipeline UUIDs.
+func applyRuleValue(st *State, rule Object, value string) error {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		return fmt.Errorf("Value is not valid JSON: %w", err)
+	}
+
+	for _, field := range []string{"source", "target"} {
+		uuid, _ := decoded[field+"_pipeline"].(string)
+		pipeline, ok := st.NodeByUUID("Pipeline", uuid)
+		if !ok {
+			return fmt.Errorf("No pipeline found with UUID %q for %s_pipeline", uuid, field)
+		}
+		rule[field] = Object{"__typename": "Pipeline", "uuid": pipeline["uuid"]}
+	}
+
+	document, err := json.Marshal(Object{"rule": rule["type"], "value": decoded})
+	if err != nil {
+		return err
+	}
+	rule["document"] = string(document)
+
+	return nil
+}
@@ -0,0 +1,678 @@
+package fakebuildkite
+
+import (
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Timestamp is the time the fake reports for every created_at and updated_at, so state is
+// repeatable across test runs.
+const Timestamp = "2024-01-01T00:00:00.000Z"
+
+// WebhookIPs are the addresses /v2/meta reports.
+var WebhookIPs = []string{"192.0.2.1/32", "192.0.2.2/32"}
+
+// AccessTokenScopes are the scopes /v2/access-token reports for the provider's token.
+var AccessTokenScopes = []string{
+	"delete_registries",
+	"graphql",
+	"read_clusters",
+	"read_notification_services",
+	"read_pipelines",
+	"read_portals",
+	"read_registries",
+	"read_secrets_details",
+	"read_suites",
+	"write_clusters",
+	"write_notification_services",
+	"write_pipelines",
+	"write_portals",
+	"write_registries",
+	"write_secrets",
+	"write_suites",
+}
+
+// paginate returns the page of objects the page and per_page query parameters select, defaulting to
+// the first page of 30 as the REST API does.
+func paginate(objects []Object, query url.Values) []Object {
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage < 1 {
+		perPage = 30
+	}
+
+	start := min((page-1)*perPage, len(objects))
+	end := min(start+perPage, len(objects))
+	return append([]Object{}, objects[start:end]...)
+}
+
+func registerREST(s *Server) {
+	s.HandleREST(http.MethodGet, "/v2/access-token", func(st *State, req *RESTRequest) (int, interface{}) {
+		return http.StatusOK, Object{"uuid": "00000000-0000-4000-8000-000000000000", "description": "fakebuildkite", "scopes": AccessTokenScopes}
+	})
+	s.HandleREST(http.MethodGet, "/v2/meta", func(st *State, req *RESTRequest) (int, interface{}) {
+		return http.StatusOK, Object{"webhook_ips": WebhookIPs}
+	})
+
+	registerPortals(s)
+	registerRegistries(s)
+	registerClusterSecrets(s)
+	registerClusterQueueSettings(s)
+	registerClusterMaintainers(s)
+	registerOrganizationAPISettings(s)
+	registerNotificationServices(s)
+	registerTestSuites(s)
+
+	// Network ranges are fixed by Buildkite, so tests put the ones they expect at the collection's
+	// path, as objects with a cidr_range and kind.
+	s.HandleREST(http.MethodGet, "/v2/organizations/{org}/clusters/{cluster}/network_ranges", func(st *State, req *RESTRequest) (int, interface{}) {
+		if _, ok := st.NodeByUUID("Cluster", req.Params["cluster"]); !ok {
+			return notFound()
+		}
+		return http.StatusOK, append([]Object{}, st.Objects(req.Path)...)
+	})
+}
+
+func registerPortals(s *Server) {
+	const collection = "/v2/organizations/{org}/portals"
+	path := func(st *State, slug string) string {
+		return "/v2/organizations/" + st.orgSlug + "/portals/" + slug
+	}
+	// The token is only ever returned by the create.
+	withoutToken := func(portal Object) Object {
+		response := Object{}
+		for key, value := range portal {
+			if key != "token" {
+				response[key] = value
+			}
+		}
+		return response
+	}
+
+	s.HandleREST(http.MethodPost, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		slug, _ := req.Body["slug"].(string)
+		if slug == "" {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Slug can't be blank"}
+		}
+		if _, exists := st.Object(path(st, slug)); exists {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Slug has already been taken"}
+		}
+
+		_, uuid := st.NewID("Portal")
+		portal := Object{
+			"uuid":                 uuid,
+			"organization_uuid":    st.org["uuid"],
+			"description":          nil,
+			"allowed_ip_addresses": nil,
+			"user_invokable":       false,
+			"created_at":           Timestamp,
+			"created_by":           nil,
+			"token":                "portal-token-" + uuid,
+		}
+		for key, value := range req.Body {
+			portal[key] = value
+		}
+		st.PutObject(path(st, slug), portal)
+
+		return http.StatusCreated, portal
+	})
+	s.HandleREST(http.MethodGet, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		portals := []Object{}
+		for _, portal := range st.Objects(path(st, "")) {
+			portals = append(portals, withoutToken(portal))
+		}
+		return http.StatusOK, portals
+	})
+	s.HandleREST(http.MethodGet, collection+"/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		portal, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, withoutToken(portal)
+	})
+	update := func(st *State, req *RESTRequest) (int, interface{}) {
+		portal, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		for key, value := range req.Body {
+			portal[key] = value
+		}
+		return http.StatusOK, withoutToken(portal)
+	}
+	s.HandleREST(http.MethodPut, collection+"/{slug}", update)
+	s.HandleREST(http.MethodPatch, collection+"/{slug}", update)
+	s.HandleREST(http.MethodDelete, collection+"/{slug}", deleteObject)
+	s.HandleREST(http.MethodPost, collection+"/{slug}/regenerate_token", func(st *State, req *RESTRequest) (int, interface{}) {
+		portal, ok := st.Object(path(st, req.Params["slug"]))
+		if !ok {
+			return notFound()
+		}
+		st.sequence++
+		portal["token"] = "portal-token-" + portal["uuid"].(string) + "-" + strconv.Itoa(st.sequence)
+		return http.StatusOK, portal
+	})
+
+	// Invocation is served from the portal host, which the fake shares with the REST API. It cannot
+	// run the query, so it answers with the variables it was given.
+	s.HandleREST(http.MethodPost, "/organizations/{org}/portals/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		portal, ok := st.Object(path(st, req.Params["slug"]))
+		if !ok {
+			return notFound()
+		}
+		if req.Header.Get("Authorization") != "Bearer "+portal["token"].(string) {
+			return http.StatusUnauthorized, Object{"message": "Invalid portal token"}
+		}
+		variables, _ := req.Body["variables"].(map[string]interface{})
+		return http.StatusOK, Object{"data": Object{"variables": variables}}
+	})
+}
+
+func registerRegistries(s *Server) {
+	const collection = "/v2/packages/organizations/{org}/registries"
+	path := func(st *State, slug string) string {
+		return "/v2/packages/organizations/" + st.orgSlug + "/registries/" + slug
+	}
+	fields := []string{"name", "description", "emoji", "color", "oidc_policy"}
+
+	s.HandleREST(http.MethodPost, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		name, _ := req.Body["name"].(string)
+		if name == "" {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Name can't be blank"}
+		}
+		slug := strings.ToLower(name)
+		if _, exists := st.Object(path(st, slug)); exists {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Name has already been taken"}
+		}
+
+		registryType := "source"
+		if value, ok := req.Body["type"].(string); ok {
+			registryType = value
+		}
+		if registryType != "source" && registryType != "composite" {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Type is not included in the list"}
+		}
+
+		id, uuid := st.NewID("Registry")
+		registry := Object{
+			"graphql_id": id,
+			"id":         uuid,
+			"slug":       slug,
+			"ecosystem":  req.Body["ecosystem"],
+			"public":     false,
+			"type":       registryType,
+			"team_ids":   req.Body["team_ids"],
+		}
+		for _, key := range fields {
+			registry[key] = req.Body[key]
+		}
+		st.PutObject(path(st, slug), registry)
+
+		return http.StatusCreated, registry
+	})
+	s.HandleREST(http.MethodGet, collection+"/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		registry, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, registry
+	})
+	s.HandleREST(http.MethodPut, collection+"/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		registry, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		for _, key := range fields {
+			if value, ok := req.Body[key]; ok {
+				registry[key] = value
+			}
+		}
+		return http.StatusOK, registry
+	})
+	s.graphql["GetRegistryID"] = func(st *State, variables map[string]interface{}) (interface{}, error) {
+		registry, ok := st.Object(path(st, strings.TrimPrefix(stringVar(variables, "slug"), st.orgSlug+"/")))
+		if !ok {
+			return Object{"registry": nil}, nil
+		}
+		return Object{"registry": Object{"id": registry["graphql_id"]}}, nil
+	}
+	s.HandleREST(http.MethodDelete, collection+"/{slug}", deleteObject)
+}
+
+func registerClusterSecrets(s *Server) {
+	const collection = "/v2/organizations/{org}/clusters/{cluster}/secrets"
+
+	s.HandleREST(http.MethodPost, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		if _, ok := st.NodeByUUID("Cluster", req.Params["cluster"]); !ok {
+			return notFound()
+		}
+		key, _ := req.Body["key"].(string)
+		for _, secret := range st.Objects(req.Path) {
+			if secret["key"] == key {
+				return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Key has already been taken"}
+			}
+		}
+
+		_, uuid := st.NewID("Secret")
+		secret := Object{
+			"id":          uuid,
+			"key":         key,
+			"description": req.Body["description"],
+			"policy":      req.Body["policy"],
+			"created_at":  Timestamp,
+			"updated_at":  Timestamp,
+			"cluster_url": "/v2/organizations/" + st.orgSlug + "/clusters/" + req.Params["cluster"],
+		}
+		st.secretValues[req.Path+"/"+uuid], _ = req.Body["value"].(string)
+		st.PutObject(req.Path+"/"+uuid, secret)
+
+		return http.StatusCreated, secret
+	})
+	s.HandleREST(http.MethodGet, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		if _, ok := st.NodeByUUID("Cluster", req.Params["cluster"]); !ok {
+			return notFound()
+		}
+		return http.StatusOK, paginate(st.Objects(req.Path), req.Query)
+	})
+	s.HandleREST(http.MethodGet, collection+"/{id}", func(st *State, req *RESTRequest) (int, interface{}) {
+		secret, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, secret
+	})
+	s.HandleREST(http.MethodPut, collection+"/{id}", func(st *State, req *RESTRequest) (int, interface{}) {
+		secret, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		for _, key := range []string{"description", "policy"} {
+			if value, ok := req.Body[key]; ok {
+				secret[key] = value
+			}
+		}
+		return http.StatusOK, secret
+	})
+	s.HandleREST(http.MethodPut, collection+"/{id}/value", func(st *State, req *RESTRequest) (int, interface{}) {
+		secretPath := req.Path[:len(req.Path)-len("/value")]
+		secret, ok := st.Object(secretPath)
+		if !ok {
+			return notFound()
+		}
+		st.secretValues[secretPath], _ = req.Body["value"].(string)
+		return http.StatusOK, secret
+	})
+	s.HandleREST(http.MethodDelete, collection+"/{id}", func(st *State, req *RESTRequest) (int, interface{}) {
+		delete(st.secretValues, req.Path)
+		return deleteObject(st, req)
+	})
+}
+
+// registerClusterQueueSettings serves the queue settings only the REST API exposes. They live on a
+// REST object alongside the GraphQL node and disappear with it.
+func registerClusterQueueSettings(s *Server) {
+	const item = "/v2/organizations/{org}/clusters/{cluster}/queues/{queue}"
+
+	settings := func(st *State, req *RESTRequest) (Object, bool) {
+		queue, ok := st.NodeByUUID("ClusterQueue", req.Params["queue"])
+		if !ok || queue["cluster"].(Object)["uuid"] != req.Params["cluster"] {
+			st.DeleteObject(req.Path)
+			return nil, false
+		}
+		object, ok := st.Object(req.Path)
+		if !ok {
+			object = Object{"id": queue["uuid"], "key": queue["key"], "retry_agent_affinity": "prefer-warmest"}
+			st.PutObject(req.Path, object)
+		}
+		object["description"] = queue["description"]
+		return object, true
+	}
+
+	s.HandleREST(http.MethodGet, item, func(st *State, req *RESTRequest) (int, interface{}) {
+		object, ok := settings(st, req)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, object
+	})
+	s.HandleREST(http.MethodPatch, item, func(st *State, req *RESTRequest) (int, interface{}) {
+		object, ok := settings(st, req)
+		if !ok {
+			return notFound()
+		}
+		if affinity, ok := req.Body["retry_agent_affinity"]; ok {
+			object["retry_agent_affinity"] = affinity
+		}
+		return http.StatusOK, object
+	})
+}
+
+func registerClusterMaintainers(s *Server) {
+	const collection = "/v2/organizations/{org}/clusters/{cluster}/maintainers"
+
+	s.HandleREST(http.MethodPost, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		if _, ok := st.NodeByUUID("Cluster", req.Params["cluster"]); !ok {
+			return notFound()
+		}
+		var actor Object
+		if uuid, ok := req.Body["user"].(string); ok {
+			user, ok := st.NodeByUUID("User", uuid)
+			if !ok {
+				return http.StatusUnprocessableEntity, Object{"message": "User not found"}
+			}
+			actor = Object{"id": uuid, "graphql_id": user["id"], "name": user["name"], "email": user["email"], "type": "user"}
+		} else if uuid, ok := req.Body["team"].(string); ok {
+			team, ok := st.NodeByUUID("Team", uuid)
+			if !ok {
+				return http.StatusUnprocessableEntity, Object{"message": "Team not found"}
+			}
+			actor = Object{"id": uuid, "graphql_id": team["id"], "name": team["name"], "slug": team["slug"], "type": "team"}
+		} else {
+			return http.StatusUnprocessableEntity, Object{"message": "Either user or team must be given"}
+		}
+		for _, maintainer := range st.Objects(req.Path) {
+			if maintainer["actor"].(Object)["id"] == actor["id"] {
+				return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Actor is already a maintainer"}
+			}
+		}
+
+		_, uuid := st.NewID("ClusterMaintainer")
+		maintainer := Object{"id": uuid, "actor": actor}
+		st.PutObject(req.Path+"/"+uuid, maintainer)
+
+		return http.StatusCreated, maintainer
+	})
+	s.HandleREST(http.MethodGet, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		if _, ok := st.NodeByUUID("Cluster", req.Params["cluster"]); !ok {
+			return notFound()
+		}
+		return http.StatusOK, append([]Object{}, st.Objects(req.Path)...)
+	})
+	s.HandleREST(http.MethodGet, collection+"/{id}", func(st *State, req *RESTRequest) (int, interface{}) {
+		maintainer, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, maintainer
+	})
+	s.HandleREST(http.MethodDelete, collection+"/{id}", deleteObject)
+}
+
+func registerOrganizationAPISettings(s *Server) {
+	const item = "/v2/organizations/{org}/api-settings"
+
+	settings := func(st *State, req *RESTRequest) Object {
+		object, ok := st.Object(req.Path)
+		if !ok {
+			object = Object{
+				"revoke_inactive_tokens_after_days": nil,
+				"restrict_user_api_token_creation":  false,
+				"features":                          Object{"inactive_api_token_revocation": true},
+			}
+			st.PutObject(req.Path, object)
+		}
+		return object
+	}
+
+	s.HandleREST(http.MethodGet, item, func(st *State, req *RESTRequest) (int, interface{}) {
+		return http.StatusOK, settings(st, req)
+	})
+	s.HandleREST(http.MethodPatch, item, func(st *State, req *RESTRequest) (int, interface{}) {
+		object := settings(st, req)
+		for _, key := range []string{"revoke_inactive_tokens_after_days", "restrict_user_api_token_creation"} {
+			if value, ok := req.Body[key]; ok {
+				object[key] = value
+			}
+		}
+		return http.StatusOK, object
+	})
+}
+
+// notificationServiceSecrets are the settings the API accepts and never returns.
+var notificationServiceSecrets = []string{"api_key", "headers"}
+
+func registerNotificationServices(s *Server) {
+	const collection = "/v2/organizations/{org}/services"
+	path := func(st *State, id string) string {
+		return "/v2/organizations/" + st.orgSlug + "/services/" + id
+	}
+	// Secret settings are kept beside the service rather than in it.
+	response := func(service Object) Object {
+		rendered := Object{}
+		for key, value := range service {
+			rendered[key] = value
+		}
+		settings := Object{}
+		for key, value := range service["settings"].(Object) {
+			if !slices.Contains(notificationServiceSecrets, key) {
+				settings[key] = value
+			}
+		}
+		rendered["settings"] = settings
+		return rendered
+	}
+	apply := func(service Object, body Object) {
+		for _, key := range []string{"description", "branch_configuration", "scope", "scope_uuids", "build_states"} {
+			if value, ok := body[key]; ok {
+				service[key] = value
+			}
+		}
+		if settings, ok := body["settings"].(map[string]interface{}); ok {
+			for key, value := range settings {
+				service["settings"].(Object)[key] = value
+			}
+		}
+	}
+
+	s.HandleREST(http.MethodPost, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		provider, _ := req.Body["provider"].(string)
+		if provider == "" {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Provider can't be blank"}
+		}
+
+		id, uuid := st.NewID("NotificationService")
+		service := Object{
+			"id":                   uuid,
+			"graphql_id":           id,
+			"provider":             Object{"id": provider},
+			"description":          nil,
+			"enabled":              true,
+			"scope":                "all",
+			"scope_uuids":          []interface{}{},
+			"branch_configuration": "",
+			"settings":             Object{},
+			"build_states":         Object{"build_passed": true, "build_failed": true},
+			"last_delivery_status": nil,
+			"last_error":           nil,
+			"created_at":           Timestamp,
+		}
+		apply(service, req.Body)
+		st.PutObject(path(st, uuid), service)
+
+		return http.StatusCreated, response(service)
+	})
+	s.HandleREST(http.MethodGet, collection+"/{id}", func(st *State, req *RESTRequest) (int, interface{}) {
+		service, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, response(service)
+	})
+	s.HandleREST(http.MethodPatch, collection+"/{id}", func(st *State, req *RESTRequest) (int, interface{}) {
+		service, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		apply(service, req.Body)
+		return http.StatusOK, response(service)
+	})
+	s.HandleREST(http.MethodDelete, collection+"/{id}", deleteObject)
+
+	setEnabled := func(enabled bool) RESTHandler {
+		return func(st *State, req *RESTRequest) (int, interface{}) {
+			service, ok := st.Object(path(st, req.Params["id"]))
+			if !ok {
+				return notFound()
+			}
+			service["enabled"] = enabled
+			return http.StatusOK, response(service)
+		}
+	}
+	s.HandleREST(http.MethodPut, collection+"/{id}/enable", setEnabled(true))
+	s.HandleREST(http.MethodPut, collection+"/{id}/disable", setEnabled(false))
+
+	// The fake delivers nowhere, so every test delivery succeeds.
+	s.HandleREST(http.MethodPost, collection+"/{id}/test", func(st *State, req *RESTRequest) (int, interface{}) {
+		service, ok := st.Object(path(st, req.Params["id"]))
+		if !ok {
+			return notFound()
+		}
+		service["last_delivery_status"] = "success"
+		service["last_error"] = nil
+		return http.StatusOK, response(service)
+	})
+}
+
+// testSuiteFields are the suite settings a create or update sets from fields of the same name.
+var testSuiteFields = []string{"name", "default_branch", "emoji", "color", "application_name", "oidc_policy"}
+
+// registerTestSuites serves test suites, which are created through REST and have a GraphQL Suite
+// node that is kept in step with them.
+func registerTestSuites(s *Server) {
+	const collection = "/v2/analytics/organizations/{org}/suites"
+	path := func(st *State, slug string) string {
+		return "/v2/analytics/organizations/" + st.orgSlug + "/suites/" + slug
+	}
+	// The API token is only returned when asked for.
+	response := func(suite Object, showToken bool) Object {
+		rendered := Object{}
+		for key, value := range suite {
+			if key != "api_token" || showToken {
+				rendered[key] = value
+			}
+		}
+		return rendered
+	}
+	sync := func(st *State, suite Object) {
+		node, ok := st.Node(suite["graphql_id"].(string))
+		if !ok {
+			return
+		}
+		node["name"] = suite["name"]
+		node["slug"] = suite["slug"]
+		node["defaultBranch"] = suite["default_branch"]
+		node["emoji"] = suite["emoji"]
+		node["applicationName"] = suite["application_name"]
+	}
+
+	s.HandleREST(http.MethodPost, collection, func(st *State, req *RESTRequest) (int, interface{}) {
+		name, _ := req.Body["name"].(string)
+		if name == "" {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Name can't be blank"}
+		}
+		slug := slugify(name)
+		if _, exists := st.Object(path(st, slug)); exists {
+			return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Name has already been taken"}
+		}
+		teamIDs, _ := req.Body["team_ids"].([]interface{})
+		teams := []Object{}
+		for _, value := range teamIDs {
+			uuid, _ := value.(string)
+			team, ok := st.NodeByUUID("Team", uuid)
+			if !ok {
+				return http.StatusUnprocessableEntity, Object{"message": "Validation failed: Team " + uuid + " not found"}
+			}
+			teams = append(teams, team)
+		}
+
+		id, uuid := st.NewID("Suite")
+		suite := Object{
+			"id":               uuid,
+			"graphql_id":       id,
+			"slug":             slug,
+			"default_branch":   "main",
+			"emoji":            nil,
+			"color":            nil,
+			"application_name": nil,
+			"oidc_policy":      nil,
+			"api_token":        "suite-token-" + uuid,
+			"web_url":          "https://buildkite.com/organizations/" + st.orgSlug + "/analytics/suites/" + slug,
+		}
+		for _, key := range testSuiteFields {
+			if value, ok := req.Body[key]; ok {
+				suite[key] = value
+			}
+		}
+		st.PutObject(path(st, slug), suite)
+		st.PutNode(Object{"__typename": "Suite", "id": id, "uuid": uuid})
+		sync(st, suite)
+
+		node, _ := st.Node(id)
+		for _, team := range teams {
+			if _, err := addTeamSuite(st, team["id"].(string), id, "MANAGE_AND_READ"); err != nil {
+				return http.StatusUnprocessableEntity, Object{"message": err.Error()}
+			}
+		}
+		refreshSuiteTeams(st, node)
+
+		return http.StatusCreated, response(suite, req.Body["show_api_token"] == true)
+	})
+	s.HandleREST(http.MethodGet, collection+"/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		suite, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		return http.StatusOK, response(suite, req.Query.Get("show_api_token") == "true")
+	})
+	s.HandleREST(http.MethodPatch, collection+"/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		suite, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		for _, key := range testSuiteFields {
+			if value, ok := req.Body[key]; ok {
+				suite[key] = value
+			}
+		}
+		sync(st, suite)
+		return http.StatusOK, response(suite, false)
+	})
+	s.HandleREST(http.MethodDelete, collection+"/{slug}", func(st *State, req *RESTRequest) (int, interface{}) {
+		suite, ok := st.Object(req.Path)
+		if !ok {
+			return notFound()
+		}
+		for _, teamSuite := range st.Nodes("TeamSuite", func(node Object) bool { return node["suite"].(Object)["id"] == suite["graphql_id"] }) {
+			st.DeleteNode(teamSuite["id"].(string))
+		}
+		st.DeleteNode(suite["graphql_id"].(string))
+		return deleteObject(st, req)
+	})
+	s.HandleREST(http.MethodPost, collection+"/{slug}/regenerate_token", func(st *State, req *RESTRequest) (int, interface{}) {
+		suite, ok := st.Object(path(st, req.Params["slug"]))
+		if !ok {
+			return notFound()
+		}
+		st.sequence++
+		suite["api_token"] = "suite-token-" + suite["id"].(string) + "-" + strconv.Itoa(st.sequence)
+		return http.StatusOK, response(suite, true)
+	})
+
+}
+
+func deleteObject(st *State, req *RESTRequest) (int, interface{}) {
+	if !st.DeleteObject(req.Path) {
+		return notFound()
+	}
+	return http.StatusNoContent, nil
+}
+
+func notFound() (int, interface{}) {
+	return http.StatusNotFound, Object{"message": "Not Found"}
+}
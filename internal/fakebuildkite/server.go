@@ -0,0 +1,463 @@
+// Package fakebuildkite is an in-memory stand-in for the Buildkite GraphQL and REST APIs, so the
+// provider's resources can be created, read, imported and destroyed in unit tests without a live
+// organization.
+//
+// A Server holds a single organization. GraphQL requests are dispatched on their operation name, so
+// each handler answers one operation from buildkite/graphql; the queries the shurcooL client sends
+// carry no name and are dispatched on their root field instead. REST requests are matched against
+// path patterns such as /v2/organizations/{org}/portals/{slug}. Anything the fake does not implement
+// is answered with an error naming it, so a test fails on the missing operation rather than on an
+// empty response.
+//
+// Tests can add or replace handlers with HandleGraphQL and HandleREST, and change state behind
+// Terraform's back with Update to exercise drift detection.
+package fakebuildkite
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Object is a GraphQL node or REST object, held in the shape the API returns it in. GraphQL nodes
+// carry their type in "__typename", which genqlient needs to decode interface fields.
+type Object = map[string]interface{}
+
+// GraphQLHandler answers one GraphQL operation. The value it returns is sent as the response's
+// data; an error is sent as a GraphQL error instead. Handlers run with the server's state locked.
+type GraphQLHandler func(state *State, variables map[string]interface{}) (interface{}, error)
+
+// RESTHandler answers a REST request matched by a route. It returns the status and a body to encode
+// as JSON, which may be nil. Handlers run with the server's state locked.
+type RESTHandler func(state *State, req *RESTRequest) (int, interface{})
+
+// RESTRequest is a REST request with its path parameters and decoded JSON body.
+type RESTRequest struct {
+	Method string
+	Path   string
+	Params map[string]string
+	Query  url.Values
+	// Header is kept for handlers that authenticate with something other than the API token.
+	Header http.Header
+	Body   Object
+}
+
+// Request is a request the server received, recorded so tests can assert on what was sent.
+type Request struct {
+	Method string
+	Path   string
+	// Operation is the GraphQL operation name, or the root field of an unnamed query.
+	Operation string
+	Variables map[string]interface{}
+	Body      Object
+}
+
+type route struct {
+	method   string
+	segments []string
+	handler  RESTHandler
+}
+
+// Server is a fake Buildkite API backed by in-memory state.
+type Server struct {
+	server *httptest.Server
+
+	mu        sync.Mutex
+	state     *State
+	graphql   map[string]GraphQLHandler
+	anonymous map[string]GraphQLHandler
+	routes    []route
+	requests  []Request
+}
+
+// GraphQLPath is where the server answers GraphQL requests.
+const GraphQLPath = "/v1"
+
+// New starts a fake API for the organization with the given slug, and stops it when the test ends.
+func New(tb testing.TB, orgSlug string) *Server {
+	tb.Helper()
+
+	s := &Server{
+		state:     newState(orgSlug),
+		graphql:   map[string]GraphQLHandler{},
+		anonymous: map[string]GraphQLHandler{},
+	}
+	registerOrganization(s)
+	registerClusters(s)
+	registerClusterQueues(s)
+	registerClusterAgentTokens(s)
+	registerTeams(s)
+	registerTeamMembers(s)
+	registerOrganizationMembers(s)
+	registerAgentTokens(s)
+	registerBanners(s)
+	registerPipelines(s)
+	registerTeamPipelines(s)
+	registerPipelineSchedules(s)
+	registerPipelineTemplates(s)
+	registerRules(s)
+	registerTestSuiteTeams(s)
+	registerREST(s)
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	tb.Cleanup(s.server.Close)
+
+	return s
+}
+
+// URL is the REST base URL, to be given to the provider as rest_url.
+func (s *Server) URL() string { return s.server.URL }
+
+// GraphQLURL is the GraphQL endpoint, to be given to the provider as graphql_url.
+func (s *Server) GraphQLURL() string { return s.server.URL + GraphQLPath }
+
+// Organization returns the slug, GraphQL ID and UUID of the server's organization.
+func (s *Server) Organization() (slug, id, uuid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.state.orgSlug, s.state.org["id"].(string), s.state.org["uuid"].(string)
+}
+
+// HandleGraphQL answers the named operation with handler, replacing any existing handler.
+func (s *Server) HandleGraphQL(operation string, handler GraphQLHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.graphql[operation] = handler
+}
+
+// HandleREST answers requests matching method and pattern with handler. Segments of the pattern in
+// braces match any value and are passed to the handler as parameters. Routes added later take
+// precedence, so a test can override a built-in one.
+func (s *Server) HandleREST(method, pattern string, handler RESTHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.routes = append(s.routes, route{
+		method:   method,
+		segments: strings.Split(strings.Trim(pattern, "/"), "/"),
+		handler:  handler,
+	})
+}
+
+// Update runs fn with the state locked, for setting up fixtures or simulating changes made outside
+// Terraform.
+func (s *Server) Update(fn func(state *State)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(s.state)
+}
+
+// Requests returns the requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == GraphQLPath {
+		s.handleGraphQL(w, body)
+		return
+	}
+	s.handleREST(w, r, body)
+}
+
+// rootField finds the first field of an unnamed query, such as "organization" in
+// query($slug:String!){organization(slug: $slug){id}}.
+var rootField = regexp.MustCompile(`^\s*(?:query|mutation)?[^{]*\{\s*(\w+)`)
+
+func (s *Server) handleGraphQL(w http.ResponseWriter, body []byte) {
+	var request struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, Object{"errors": []Object{{"message": err.Error()}}})
+		return
+	}
+
+	operation := request.OperationName
+	handler, ok := s.graphql[operation]
+	if operation == "" {
+		if match := rootField.FindStringSubmatch(request.Query); match != nil {
+			operation = match[1]
+			handler, ok = s.anonymous[operation]
+		}
+	}
+	s.requests = append(s.requests, Request{Method: http.MethodPost, Path: GraphQLPath, Operation: operation, Variables: request.Variables})
+
+	if !ok {
+		writeGraphQLError(w, fmt.Errorf("fakebuildkite: GraphQL operation %q is not implemented", operation))
+		return
+	}
+	if request.Variables == nil {
+		request.Variables = map[string]interface{}{}
+	}
+
+	data, err := handler(s.state, request.Variables)
+	if err != nil {
+		writeGraphQLError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, Object{"data": data})
+}
+
+func (s *Server) handleREST(w http.ResponseWriter, r *http.Request, body []byte) {
+	var decoded Object
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			writeJSON(w, http.StatusBadRequest, Object{"message": err.Error()})
+			return
+		}
+	}
+	s.requests = append(s.requests, Request{Method: r.Method, Path: r.URL.Path, Body: decoded})
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		params, ok := s.routes[i].match(r.Method, segments)
+		if !ok {
+			continue
+		}
+		if org, ok := params["org"]; ok && org != s.state.orgSlug {
+			writeJSON(w, http.StatusNotFound, Object{"message": "No organization found"})
+			return
+		}
+
+		status, response := s.routes[i].handler(s.state, &RESTRequest{Method: r.Method, Path: r.URL.Path, Params: params, Query: r.URL.Query(), Header: r.Header, Body: decoded})
+		writeJSON(w, status, response)
+		return
+	}
+
+	writeJSON(w, http.StatusNotFound, Object{"message": fmt.Sprintf("fakebuildkite: %s %s is not implemented", r.Method, r.URL.Path)})
+}
+
+func (r route) match(method string, segments []string) (map[string]string, bool) {
+	if r.method != method || len(r.segments) != len(segments) {
+		return nil, false
+	}
+
+	params := map[string]string{}
+	for i, segment := range r.segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			params[segment[1:len(segment)-1]] = segments[i]
+			continue
+		}
+		if segment != segments[i] {
+			return nil, false
+		}
+	}
+
+	return params, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeGraphQLError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusOK, Object{"data": nil, "errors": []Object{{"message": err.Error()}}})
+}
+
+// State is the fake's in-memory organization. Its methods are only safe to call from handlers and
+// Server.Update, which hold the server's lock.
+type State struct {
+	orgSlug string
+	org     Object
+	// nodes holds GraphQL nodes by ID; nodeOrder keeps them in creation order for listing.
+	nodes     map[string]Object
+	nodeOrder []string
+	// objects holds REST objects by path, in creation order.
+	objects     map[string]Object
+	objectOrder []string
+	// secretValues holds cluster secret values by secret path, since the API never returns them.
+	secretValues map[string]string
+	// enumValues holds the values of schema enums the provider reads by introspection, by enum name.
+	enumValues map[string][]Object
+	sequence   int
+}
+
+func newState(orgSlug string) *State {
+	st := &State{orgSlug: orgSlug, nodes: map[string]Object{}, objects: map[string]Object{}, secretValues: map[string]string{}, enumValues: defaultEnumValues()}
+
+	id, uuid := st.NewID("Organization")
+	st.org = Object{
+		"__typename":                            "Organization",
+		"id":                                    id,
+		"uuid":                                  uuid,
+		"slug":                                  orgSlug,
+		"allowedApiIpAddresses":                 "",
+		"membersRequireTwoFactorAuthentication": false,
+		"permissions":                           allowedPermissions(),
+	}
+	st.PutNode(st.org)
+
+	return st
+}
+
+// NewID returns a GraphQL ID and UUID for a new node of the given type. UUIDs are sequential so
+// that test failures are repeatable, and IDs are encoded the way Buildkite's are.
+func (st *State) NewID(typename string) (id, uuid string) {
+	st.sequence++
+	uuid = fmt.Sprintf("00000000-0000-4000-8000-%012d", st.sequence)
+
+	return base64.StdEncoding.EncodeToString([]byte(typename + "---" + uuid)), uuid
+}
+
+// Organization returns the organization node, which handlers may modify in place.
+func (st *State) Organization() Object { return st.org }
+
+// AddUser adds a member to the organization. Users cannot be created through the API, so tests add
+// the ones they need.
+func (st *State) AddUser(name, email string) Object {
+	id, uuid := st.NewID("User")
+	user := Object{"__typename": "User", "id": id, "uuid": uuid, "name": name, "email": email}
+	st.PutNode(user)
+
+	return user
+}
+
+// Node returns the node with the given ID.
+func (st *State) Node(id string) (Object, bool) {
+	node, ok := st.nodes[id]
+	return node, ok
+}
+
+// PutNode stores a node under its "id", replacing any node with the same ID.
+func (st *State) PutNode(node Object) {
+	id := node["id"].(string)
+	if _, exists := st.nodes[id]; !exists {
+		st.nodeOrder = append(st.nodeOrder, id)
+	}
+	st.nodes[id] = node
+}
+
+// DeleteNode removes a node, reporting whether it existed.
+func (st *State) DeleteNode(id string) bool {
+	if _, ok := st.nodes[id]; !ok {
+		return false
+	}
+	delete(st.nodes, id)
+	st.nodeOrder = remove(st.nodeOrder, id)
+
+	return true
+}
+
+// Nodes returns the nodes of the given type that match, in creation order. A nil match returns all
+// of them.
+func (st *State) Nodes(typename string, match func(Object) bool) []Object {
+	var nodes []Object
+	for _, id := range st.nodeOrder {
+		node := st.nodes[id]
+		if node["__typename"] == typename && (match == nil || match(node)) {
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// NodeByUUID returns the node of the given type with the given UUID.
+func (st *State) NodeByUUID(typename, uuid string) (Object, bool) {
+	nodes := st.Nodes(typename, func(node Object) bool { return node["uuid"] == uuid })
+	if len(nodes) == 0 {
+		return nil, false
+	}
+
+	return nodes[0], true
+}
+
+// Object returns the REST object stored at path.
+func (st *State) Object(path string) (Object, bool) {
+	object, ok := st.objects[path]
+	return object, ok
+}
+
+// PutObject stores a REST object at path, replacing any object already there.
+func (st *State) PutObject(path string, object Object) {
+	if _, exists := st.objects[path]; !exists {
+		st.objectOrder = append(st.objectOrder, path)
+	}
+	st.objects[path] = object
+}
+
+// DeleteObject removes the REST object at path, reporting whether it existed.
+func (st *State) DeleteObject(path string) bool {
+	if _, ok := st.objects[path]; !ok {
+		return false
+	}
+	delete(st.objects, path)
+	st.objectOrder = remove(st.objectOrder, path)
+
+	return true
+}
+
+// Objects returns the REST objects directly inside collection, in creation order.
+func (st *State) Objects(collection string) []Object {
+	prefix := strings.TrimSuffix(collection, "/") + "/"
+
+	var objects []Object
+	for _, path := range st.objectOrder {
+		if strings.HasPrefix(path, prefix) && !strings.Contains(path[len(prefix):], "/") {
+			objects = append(objects, st.objects[path])
+		}
+	}
+
+	return objects
+}
+
+// SecretValue returns the value last written to the cluster secret at path.
+func (st *State) SecretValue(path string) (string, bool) {
+	value, ok := st.secretValues[path]
+	return value, ok
+}
+
+// EnumValues returns the values of the named schema enum, each with a name, description and
+// isDeprecated.
+func (st *State) EnumValues(enum string) []Object {
+	return st.enumValues[enum]
+}
+
+// SetEnumValues replaces the values of the named schema enum, so a test can withdraw or deprecate
+// a hosted agent instance shape.
+func (st *State) SetEnumValues(enum string, values []Object) {
+	st.enumValues[enum] = values
+}
+
+func remove(values []string, value string) []string {
+	for i, v := range values {
+		if v == value {
+			return append(values[:i:i], values[i+1:]...)
+		}
+	}
+
+	return values
+}
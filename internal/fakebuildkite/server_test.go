@@ -0,0 +1,197 @@
+package fakebuildkite
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func graphQL(t *testing.T, s *Server, request Object) Object {
+	t.Helper()
+
+	var response Object
+	do(t, http.MethodPost, s.GraphQLURL(), request, http.StatusOK, &response)
+	return response
+}
+
+func do(t *testing.T, method, url string, body interface{}, wantStatus int, response interface{}) {
+	t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("%s %s returned %d, want %d", method, url, resp.StatusCode, wantStatus)
+	}
+	if response != nil {
+		if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUnnamedOrganizationQuery(t *testing.T) {
+	s := New(t, "test-org")
+	_, id, _ := s.Organization()
+
+	response := graphQL(t, s, Object{
+		"query":     "query($slug:ID!){organization(slug: $slug){id}}",
+		"variables": Object{"slug": "test-org"},
+	})
+
+	got := response["data"].(Object)["organization"].(Object)["id"]
+	if got != id {
+		t.Errorf("organization id = %v, want %s", got, id)
+	}
+	if requests := s.Requests(); len(requests) != 1 || requests[0].Operation != "organization" {
+		t.Errorf("requests = %+v, want one organization query", requests)
+	}
+}
+
+func TestClusterLifecycle(t *testing.T) {
+	s := New(t, "test-org")
+	_, orgID, _ := s.Organization()
+
+	created := graphQL(t, s, Object{
+		"operationName": "createCluster",
+		"variables":     Object{"organizationId": orgID, "name": "cluster", "description": "first"},
+	})
+	cluster := created["data"].(Object)["clusterCreate"].(Object)["cluster"].(Object)
+	clusterID := cluster["id"].(string)
+	if cluster["__typename"] != "Cluster" || cluster["description"] != "first" || cluster["emoji"] != nil {
+		t.Fatalf("created cluster = %+v", cluster)
+	}
+
+	graphQL(t, s, Object{
+		"operationName": "createClusterQueue",
+		"variables":     Object{"organizationId": orgID, "clusterId": clusterID, "key": "default"},
+	})
+	duplicate := graphQL(t, s, Object{
+		"operationName": "createClusterQueue",
+		"variables":     Object{"organizationId": orgID, "clusterId": clusterID, "key": "default"},
+	})
+	if duplicate["errors"] == nil {
+		t.Error("creating a queue with a duplicate key should fail")
+	}
+
+	graphQL(t, s, Object{
+		"operationName": "updateCluster",
+		"variables":     Object{"organizationId": orgID, "id": clusterID, "name": "", "description": nil},
+	})
+	node := graphQL(t, s, Object{"operationName": "getNode", "variables": Object{"id": clusterID}})["data"].(Object)["node"].(Object)
+	if node["name"] != "cluster" || node["description"] != nil {
+		t.Errorf("updated cluster = %+v, want the name kept and the description cleared", node)
+	}
+
+	graphQL(t, s, Object{
+		"operationName": "deleteCluster",
+		"variables":     Object{"organizationId": orgID, "id": clusterID},
+	})
+	if node := graphQL(t, s, Object{"operationName": "getNode", "variables": Object{"id": clusterID}})["data"].(Object)["node"]; node != nil {
+		t.Errorf("deleted cluster is still returned: %+v", node)
+	}
+	s.Update(func(st *State) {
+		if queues := st.Nodes("ClusterQueue", nil); len(queues) != 0 {
+			t.Errorf("deleting the cluster left %d queues behind", len(queues))
+		}
+	})
+}
+
+func TestUnimplementedOperationsFail(t *testing.T) {
+	s := New(t, "test-org")
+
+	response := graphQL(t, s, Object{"operationName": "createBuild"})
+	errors, _ := response["errors"].([]interface{})
+	if len(errors) != 1 || !strings.Contains(errors[0].(Object)["message"].(string), `"createBuild" is not implemented`) {
+		t.Errorf("errors = %+v, want one naming the operation", response["errors"])
+	}
+
+	var body Object
+	do(t, http.MethodGet, s.URL()+"/v2/organizations/test-org/pipelines/pipeline", nil, http.StatusNotFound, &body)
+	if !strings.Contains(body["message"].(string), "is not implemented") {
+		t.Errorf("message = %v, want one saying the endpoint is not implemented", body["message"])
+	}
+}
+
+func TestPortalLifecycle(t *testing.T) {
+	s := New(t, "test-org")
+	collection := s.URL() + "/v2/organizations/test-org/portals"
+
+	var created Object
+	do(t, http.MethodPost, collection, Object{"slug": "viewer", "name": "Viewer", "query": "{ viewer { id } }"}, http.StatusCreated, &created)
+	if created["token"] == nil || created["uuid"] == nil {
+		t.Fatalf("created portal = %+v, want a uuid and token", created)
+	}
+	do(t, http.MethodPost, collection, Object{"slug": "viewer"}, http.StatusUnprocessableEntity, nil)
+
+	var updated Object
+	do(t, http.MethodPut, collection+"/viewer", Object{"name": "Renamed"}, http.StatusOK, &updated)
+	if updated["name"] != "Renamed" || updated["query"] != "{ viewer { id } }" {
+		t.Errorf("updated portal = %+v", updated)
+	}
+	if _, ok := updated["token"]; ok {
+		t.Error("the token should only be returned on create")
+	}
+
+	do(t, http.MethodGet, s.URL()+"/v2/organizations/other-org/portals/viewer", nil, http.StatusNotFound, nil)
+	do(t, http.MethodDelete, collection+"/viewer", nil, http.StatusNoContent, nil)
+	do(t, http.MethodGet, collection+"/viewer", nil, http.StatusNotFound, nil)
+}
+
+func TestClusterSecretValuesAreNeverReturned(t *testing.T) {
+	s := New(t, "test-org")
+	var clusterUUID string
+	s.Update(func(st *State) {
+		id, uuid := st.NewID("Cluster")
+		st.PutNode(Object{"__typename": "Cluster", "id": id, "uuid": uuid, "name": "cluster"})
+		clusterUUID = uuid
+	})
+	collection := s.URL() + "/v2/organizations/test-org/clusters/" + clusterUUID + "/secrets"
+
+	var created Object
+	do(t, http.MethodPost, collection, Object{"key": "TOKEN", "value": "s3cret"}, http.StatusCreated, &created)
+	if _, ok := created["value"]; ok {
+		t.Error("the secret value should not be returned")
+	}
+	path := "/v2/organizations/test-org/clusters/" + clusterUUID + "/secrets/" + created["id"].(string)
+	do(t, http.MethodPut, s.URL()+path+"/value", Object{"value": "rotated"}, http.StatusOK, nil)
+
+	s.Update(func(st *State) {
+		if value, _ := st.SecretValue(path); value != "rotated" {
+			t.Errorf("secret value = %q, want %q", value, "rotated")
+		}
+	})
+
+	do(t, http.MethodPost, s.URL()+"/v2/organizations/test-org/clusters/missing/secrets", Object{"key": "TOKEN"}, http.StatusNotFound, nil)
+}
+
+func TestHandlersCanBeReplaced(t *testing.T) {
+	s := New(t, "test-org")
+	s.HandleREST(http.MethodGet, "/v2/meta", func(st *State, req *RESTRequest) (int, interface{}) {
+		return http.StatusServiceUnavailable, Object{"message": "down"}
+	})
+	s.HandleGraphQL("getOrganization", func(st *State, variables map[string]interface{}) (interface{}, error) {
+		return Object{"organization": nil}, nil
+	})
+
+	do(t, http.MethodGet, s.URL()+"/v2/meta", nil, http.StatusServiceUnavailable, nil)
+	response := graphQL(t, s, Object{"operationName": "getOrganization", "variables": Object{"slug": "test-org"}})
+	if response["data"].(Object)["organization"] != nil {
+		t.Errorf("organization = %+v, want the replacement handler's null", response["data"])
+	}
+}
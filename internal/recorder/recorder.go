@@ -0,0 +1,345 @@
+// Package recorder records Buildkite API traffic to a cassette and replays it, so acceptance tests
+// can run without a live organization or network access.
+//
+// Interactions are matched on the method, the path and query, and a hash of the canonicalised
+// request body. Request bodies are never stored, so secrets a test sends (cluster secret values,
+// for one) stay out of the cassette; only the hash is kept. Requests are otherwise free to arrive
+// in any order, which parallel tests need. Identical requests are answered in the order they were
+// recorded, and once the recorded answers run out the last one is repeated, since Terraform may
+// refresh a resource more times on replay than it did while recording.
+package recorder
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// Mode selects what a Recorder does with each request.
+type Mode string
+
+const (
+	// ModeRecord sends requests to the API and saves what it answered.
+	ModeRecord Mode = "record"
+	// ModeReplay answers requests from the cassette and never touches the network.
+	ModeReplay Mode = "replay"
+)
+
+// redacted replaces sensitive values in recorded responses.
+const redacted = "REDACTED"
+
+// sensitiveKeys names the JSON keys whose values are redacted from recorded responses. Agent,
+// suite and portal tokens are all returned under one of these, agent tokens as tokenValue.
+var sensitiveKeys = regexp.MustCompile(`(?i)^(token|token_?value|api_token|access_token|secret|password)$`)
+
+// Cassette is the on-disk form of a recording.
+type Cassette struct {
+	// Organization is the slug the recording was made against. Request paths embed it, so a replay
+	// has to use the same one.
+	Organization string `json:"organization"`
+	// Seed makes the names tests generate repeatable, so the requests a replay sends match the
+	// recorded ones.
+	Seed         string         `json:"seed"`
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and the response it received.
+type Interaction struct {
+	Method   string `json:"method"`
+	URL      string `json:"url"`
+	BodyHash string `json:"body_hash,omitempty"`
+	// Operation is the GraphQL operation name, kept only to make cassettes readable.
+	Operation   string          `json:"operation,omitempty"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	// ResponseText holds a body that is not JSON, such as a proxy's error page, verbatim.
+	ResponseText string `json:"response_text,omitempty"`
+}
+
+func (i *Interaction) key() string {
+	return i.Method + " " + i.URL + " " + i.BodyHash
+}
+
+// Recorder is an http.RoundTripper that records or replays a cassette.
+type Recorder struct {
+	mode Mode
+	path string
+	next http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+	// replay queues the recorded interactions by key; served counts how many of each were used.
+	replay map[string][]*Interaction
+	served map[string]int
+}
+
+// New returns a Recorder for the cassette at path. In ModeReplay the cassette must exist; in
+// ModeRecord any existing cassette is replaced when Save is called.
+func New(mode Mode, path string, next http.RoundTripper) (*Recorder, error) {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	r := &Recorder{
+		mode:   mode,
+		path:   path,
+		next:   next,
+		replay: map[string][]*Interaction{},
+		served: map[string]int{},
+	}
+
+	switch mode {
+	case ModeRecord:
+		seed := make([]byte, 8)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, fmt.Errorf("failed to generate a seed: %w", err)
+		}
+		r.cassette.Seed = hex.EncodeToString(seed)
+
+	case ModeReplay:
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cassette: %w", err)
+		}
+		if err := json.Unmarshal(contents, &r.cassette); err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		for _, interaction := range r.cassette.Interactions {
+			r.replay[interaction.key()] = append(r.replay[interaction.key()], interaction)
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown recorder mode %q, expected %q or %q", mode, ModeRecord, ModeReplay)
+	}
+
+	return r, nil
+}
+
+// Mode reports whether the recorder is recording or replaying.
+func (r *Recorder) Mode() Mode { return r.mode }
+
+// Seed returns the seed stored in the cassette.
+func (r *Recorder) Seed() string { return r.cassette.Seed }
+
+// Organization returns the organization slug stored in the cassette.
+func (r *Recorder) Organization() string { return r.cassette.Organization }
+
+// SetOrganization records the organization slug the recording is being made against.
+func (r *Recorder) SetOrganization(slug string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Organization = slug
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("recorder: failed to read request body: %w", err)
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	interaction := &Interaction{
+		Method:    req.Method,
+		URL:       req.URL.RequestURI(),
+		BodyHash:  hashBody(body),
+		Operation: operationName(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replayResponse(req, interaction)
+	}
+
+	resp, err := r.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// A rate limit says nothing about the API under test, and replaying one would only make the
+	// retry client wait.
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return resp, nil
+	}
+
+	responseBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("recorder: failed to read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction.Status = resp.StatusCode
+	interaction.ContentType = resp.Header.Get("Content-Type")
+	interaction.Response, interaction.ResponseText = sanitise(responseBody)
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+func (r *Recorder) replayResponse(req *http.Request, interaction *Interaction) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := interaction.key()
+	recorded := r.replay[key]
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("recorder: no recorded interaction for %s %s (operation %q); re-record the cassette with BUILDKITE_RECORDER=record",
+			interaction.Method, interaction.URL, interaction.Operation)
+	}
+
+	n := r.served[key]
+	if n >= len(recorded) {
+		n = len(recorded) - 1
+	}
+	r.served[key]++
+	match := recorded[n]
+
+	header := make(http.Header)
+	if match.ContentType != "" {
+		header.Set("Content-Type", match.ContentType)
+	}
+	// The cassette is indented for review; send the response as compactly as it was received.
+	var compact bytes.Buffer
+	if len(match.Response) > 0 {
+		if err := json.Compact(&compact, match.Response); err != nil {
+			return nil, fmt.Errorf("recorder: recorded response for %s %s is not valid JSON: %w", interaction.Method, interaction.URL, err)
+		}
+	}
+	body := compact.Bytes()
+	if match.ResponseText != "" {
+		body = []byte(match.ResponseText)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", match.Status, http.StatusText(match.Status)),
+		StatusCode:    match.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the cassette when recording, with interactions sorted so that re-recording an
+// unchanged suite produces a small diff despite tests running in parallel. It does nothing when
+// replaying.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A stable sort keeps identical requests in the order they were made, which replay depends on.
+	sort.SliceStable(r.cassette.Interactions, func(i, j int) bool {
+		return r.cassette.Interactions[i].key() < r.cassette.Interactions[j].key()
+	})
+
+	contents, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+
+	return os.WriteFile(r.path, append(contents, '\n'), 0o644)
+}
+
+// hashBody hashes a canonical form of the body, so JSON that differs only in key order or spacing
+// still matches.
+func hashBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	canonical := body
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		if encoded, err := json.Marshal(decoded); err == nil {
+			canonical = encoded
+		}
+	}
+
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+func operationName(body []byte) string {
+	var request struct {
+		OperationName string `json:"operationName"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return ""
+	}
+
+	return request.OperationName
+}
+
+// sanitise redacts sensitive values from a JSON response. A body that is not JSON is returned as
+// text instead, unredacted, since there is no structure to find a secret in.
+func sanitise(body []byte) (json.RawMessage, string) {
+	if len(body) == 0 {
+		return nil, ""
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil, string(body)
+	}
+	encoded, err := json.Marshal(redact(decoded))
+	if err != nil {
+		return nil, string(body)
+	}
+
+	return encoded, ""
+}
+
+func redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if _, isString := child.(string); isString && sensitiveKeys.MatchString(key) {
+				v[key] = redacted
+				continue
+			}
+			v[key] = redact(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redact(child)
+		}
+	}
+
+	return value
+}
+
+// Load returns a Recorder for the mode named by the environment value, or nil when it is empty.
+func Load(mode, path string, next http.RoundTripper) (*Recorder, error) {
+	if mode == "" {
+		return nil, nil
+	}
+
+	return New(Mode(mode), path, next)
+}
@@ -0,0 +1,162 @@
+package recorder
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func get(t *testing.T, client *http.Client, url, body string) (int, string) {
+	t.Helper()
+
+	method := http.MethodGet
+	var reader io.Reader
+	if body != "" {
+		method = http.MethodPost
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, url, err)
+	}
+	defer resp.Body.Close()
+
+	contents, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, string(contents)
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/graphql":
+			_, _ = w.Write([]byte(`{"data":{"agentTokenCreate":{"tokenValue":"secret-token","agentTokenEdge":{"node":{"uuid":"token-uuid"}}}}}`))
+		case "/v2/missing":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`not found`))
+		default:
+			// Repeated reads see the resource change, as a refresh after an update would.
+			_, _ = w.Write([]byte(`{"name":"read-` + string(rune('0'+n)) + `"}`))
+		}
+	}))
+	defer server.Close()
+
+	cassette := filepath.Join(t.TempDir(), "cassettes", "test.json")
+	recording, err := New(ModeRecord, cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recording.SetOrganization("test-org")
+	client := &http.Client{Transport: recording}
+
+	_, first := get(t, client, server.URL+"/v2/portal", "")
+	_, second := get(t, client, server.URL+"/v2/portal", "")
+	_, created := get(t, client, server.URL+"/graphql", `{"operationName":"createAgentToken","variables":{"description":"a"}}`)
+	status, missing := get(t, client, server.URL+"/v2/missing", "")
+	if err := recording.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	contents, err := os.ReadFile(cassette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(contents), "secret-token") {
+		t.Errorf("cassette contains an unredacted token:\n%s", contents)
+	}
+	if strings.Contains(string(contents), `"description"`) {
+		t.Errorf("cassette contains a request body:\n%s", contents)
+	}
+
+	server.Close()
+	replaying, err := New(ModeReplay, cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replaying.Organization() != "test-org" || replaying.Seed() != recording.Seed() {
+		t.Errorf("replay organization %q seed %q, want %q %q", replaying.Organization(), replaying.Seed(), "test-org", recording.Seed())
+	}
+	client = &http.Client{Transport: replaying}
+
+	if _, got := get(t, client, "http://replay.invalid/v2/portal", ""); got != first {
+		t.Errorf("first replayed read = %q, want %q", got, first)
+	}
+	if _, got := get(t, client, "http://replay.invalid/v2/portal", ""); got != second {
+		t.Errorf("second replayed read = %q, want %q", got, second)
+	}
+	// Once the recorded answers run out the last is repeated.
+	if _, got := get(t, client, "http://replay.invalid/v2/portal", ""); got != second {
+		t.Errorf("third replayed read = %q, want %q", got, second)
+	}
+
+	// Key order and spacing in a JSON body do not affect matching.
+	_, got := get(t, client, "http://replay.invalid/graphql", `{"variables": {"description": "a"}, "operationName": "createAgentToken"}`)
+	var decoded struct {
+		Data struct {
+			AgentTokenCreate struct {
+				TokenValue string `json:"tokenValue"`
+			} `json:"agentTokenCreate"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("replayed GraphQL response is not JSON: %v", err)
+	}
+	if decoded.Data.AgentTokenCreate.TokenValue != redacted || created == got {
+		t.Errorf("replayed token = %q, want it redacted", decoded.Data.AgentTokenCreate.TokenValue)
+	}
+
+	if gotStatus, got := get(t, client, "http://replay.invalid/v2/missing", ""); gotStatus != status || got != missing {
+		t.Errorf("replayed error = %d %q, want %d %q", gotStatus, got, status, missing)
+	}
+}
+
+func TestReplayRejectsUnrecordedRequests(t *testing.T) {
+	t.Parallel()
+
+	cassette := filepath.Join(t.TempDir(), "empty.json")
+	if err := os.WriteFile(cassette, []byte(`{"organization":"test-org","seed":"abc","interactions":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	replaying, err := New(ModeReplay, cassette, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://replay.invalid/v2/meta", nil)
+	if _, err := replaying.RoundTrip(req); err == nil || !strings.Contains(err.Error(), "no recorded interaction for GET /v2/meta") {
+		t.Errorf("RoundTrip() error = %v, want it to name the unrecorded request", err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	if r, err := Load("", "unused", nil); r != nil || err != nil {
+		t.Errorf("Load(\"\") = %v, %v, want nil, nil", r, err)
+	}
+	if _, err := Load("replay", filepath.Join(t.TempDir(), "missing.json"), nil); err == nil {
+		t.Error("Load() should fail to replay a missing cassette")
+	}
+	if _, err := Load("rewind", "unused", nil); err == nil || !strings.Contains(err.Error(), "unknown recorder mode") {
+		t.Errorf("Load() error = %v, want an unknown mode error", err)
+	}
+}
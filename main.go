@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
+	"fmt"
 	"log"
+	"os"
 
 	"github.com/buildkite/terraform-provider-buildkite/buildkite"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
@@ -15,6 +18,13 @@ var (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "generate-imports" {
+		if err := generateImports(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	var debug bool
 
 	flag.BoolVar(&debug, "debug", false, "set to true to run the provider with support for debuggers like delve")
@@ -35,3 +45,38 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// generateImports writes Terraform configuration importing an existing organization. The API token
+// is only read from BUILDKITE_API_TOKEN, so it never shows up in a process listing.
+func generateImports(args []string) error {
+	config := buildkite.ImportGeneratorConfig{Version: version}
+
+	flags := flag.NewFlagSet("generate-imports", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s generate-imports [flags]\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Writes import blocks and resource configuration for an existing Buildkite organization.")
+		fmt.Fprintln(flags.Output(), "The API token is read from BUILDKITE_API_TOKEN.")
+		fmt.Fprintln(flags.Output())
+		flags.PrintDefaults()
+	}
+	flags.StringVar(&config.Organization, "organization", "", "the slug of the organization to import (default $BUILDKITE_ORGANIZATION_SLUG)")
+	flags.StringVar(&config.GraphQLURL, "graphql-url", "", "the GraphQL endpoint to use (default $BUILDKITE_GRAPHQL_URL or https://graphql.buildkite.com/v1)")
+	out := flags.String("out", "", "the file to write the configuration to (default stdout)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return buildkite.GenerateImports(context.Background(), config, os.Stdout)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	if err := buildkite.GenerateImports(context.Background(), config, f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}